@@ -0,0 +1,560 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/batch"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// convertOptions 为一次转换使用的参数
+type convertOptions struct {
+	// From 为输入格式，auto 表示根据内容识别
+	From string
+	// To 为输出格式
+	To string
+	// Output 为指定的输出路径，为空时与输入文件同名
+	Output string
+	// OutDir 为输出目录，按输入的相对路径在其中重建目录结构
+	OutDir string
+	// Fetch 控制远程文件的下载
+	Fetch fetchOptions
+	// Write 控制输出的内容
+	Write render.WriteOptions
+	// Report 为转换报告的格式，为空时不输出报告
+	Report string
+	// ReportFile 为转换报告的输出路径，为空时输出到标准输出
+	ReportFile string
+	// Bundle 为 -bundle 指定的 zip 文件，不为空时将所有输出与资源打包写入其中，见 convertBundle
+	Bundle string
+	// Manifest 为 -manifest 指定的清单文件，为空时不写入清单，见 writeManifest
+	Manifest string
+	// Index 表示批量转换后在输出的根目录生成链接到每个文档的 README.md，见 writeIndex
+	Index bool
+	// NoProgress 表示不在终端中显示批量转换的进度
+	NoProgress bool
+	// DryRun 表示只解析输入并列出将要写入的文件，不写入任何内容
+	DryRun bool
+	// Force 表示允许覆盖已存在的输出文件
+	Force bool
+	// Backup 表示覆盖已存在的输出文件前先保留一份带时间戳的备份
+	Backup bool
+	// Preview 表示在终端中渲染转换结果，未指定 -o 或 -out-dir 时不写入文件
+	Preview bool
+	// Clipboard 表示将转换结果复制到系统剪贴板，未指定 -o 或 -out-dir 时不写入文件
+	Clipboard bool
+	// Open 表示转换结束后在文件管理器中打开输出文件夹
+	Open bool
+	// ForceRebuild 表示即使输入没有变化也重新转换
+	ForceRebuild bool
+	// NameTemplate 为输出文件名的模板，Name 为解析后的模板，为 nil 时与输入文件同名
+	NameTemplate string
+	Name         *template.Template
+	// Template 为 -template 指定的模板文件，TemplateText 为其内容，解析后的模板在 Write.Template 中
+	Template     string
+	TemplateText string
+	// Transforms 为 -transform 指定的转换脚本，TransformText 为脚本路径与内容，见 transformSheets
+	Transforms    []string
+	TransformText string
+	// StatsFooter 表示在输出末尾写入节点数、字数等统计与来源文件，见 statsFooter
+	StatsFooter bool
+	// Deterministic 表示生成适合纳入版本控制的稳定输出：规范化空白（见 render.WriteOptions.Normalize），
+	// -stats-footer 不写入当前时间
+	Deterministic bool
+	// SheetRegex 不为 nil 时只转换标题与之匹配的画布，每个画布写入单独的文件，见 convertSheets；
+	// SheetRegexText 为 -sheet-regex 指定的正则表达式
+	SheetRegexText string
+	SheetRegex     *regexp.Regexp
+	// Root 与 RootID 按标题路径或 ID 选出一个节点，只转换以它为根节点的子树，见 selectRoot
+	Root   string
+	RootID string
+	// Jobs 为批量转换时同时转换的文件数，不大于 0 时与 CPU 核数相同
+	Jobs int
+	// Retries 与 RetryBackoff 为遇到临时性错误时的重试次数与第一次重试前的等待时间，见 newEngine
+	Retries      int
+	RetryBackoff time.Duration
+	// Merge 表示将所有输入合并写入 Output 一个文件，见 mergeInputs
+	Merge bool
+	// ChunkLevel 与 MaxFileSize 大于 0 时将 Markdown 输出拆分为多个文件，见 writeChunks
+	ChunkLevel  int
+	MaxFileSize int64
+	// Limits 为解析时的资源限制，见 applyLimits
+	Limits xmind.Limits
+	// Cache 为增量转换使用的缓存，为 nil 时不跳过任何输入
+	Cache *buildCache
+}
+
+// stringList 实现 flag.Value，允许同一个参数重复指定多次
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// stringMap 实现 flag.Value，每次指定一个 key=value，可重复指定多次
+type stringMap map[string]string
+
+func (m stringMap) String() string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		keys[i] = k + "=" + m[k]
+	}
+	return strings.Join(keys, ", ")
+}
+
+func (m stringMap) Set(v string) error {
+	k, val, ok := strings.Cut(v, "=")
+	if !ok || k == "" {
+		return i18n.Errorf("格式应为 key=value: %s", v)
+	}
+	m[k] = val
+	return nil
+}
+
+// regexpList 实现 flag.Value，每次指定一个正则表达式，可重复指定多次
+type regexpList []*regexp.Regexp
+
+func (l *regexpList) String() string {
+	exprs := make([]string, len(*l))
+	for i, re := range *l {
+		exprs[i] = re.String()
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func (l *regexpList) Set(v string) error {
+	re, err := regexp.Compile(v)
+	if err != nil {
+		return i18n.Errorf("无效的正则表达式 %q: %v", v, err)
+	}
+	*l = append(*l, re)
+	return nil
+}
+
+// input 表示一个待转换的输入
+type input struct {
+	// Path 为本地路径或远程地址
+	Path string
+	// Rel 为相对于所在输入目录的路径，用于在输出目录中重建目录结构
+	Rel string
+}
+
+// expandInputs 展开输入列表，通配符会展开为所有匹配的文件，目录会递归展开为其中需要转换的文件
+// batch 表示是否按批量方式转换（多个输入或包含目录）
+func expandInputs(files []string, filter fileFilter) (inputs []input, batch bool, err error) {
+	batch = len(files) > 1
+	for _, f := range files {
+		if isRemote(f) {
+			inputs = append(inputs, input{Path: f})
+			continue
+		}
+		if _, err := os.Stat(f); err != nil && hasGlobMeta(f) {
+			matches, err := expandGlob(f)
+			if err != nil {
+				return nil, false, err
+			}
+			batch = true
+			inputs = append(inputs, matches...)
+			continue
+		}
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, false, &fileError{path: f, err: i18n.Errorf("打开文件失败: %w", err)}
+		}
+		// 目录形式的 MindNode bundle 作为单个文件处理
+		if !info.IsDir() || xmind.FormatOf(f) == "mindnode" {
+			inputs = append(inputs, input{Path: f, Rel: filepath.Base(f)})
+			continue
+		}
+
+		batch = true
+		found, err := walkDir(f, filter)
+		if err != nil {
+			return nil, false, err
+		}
+		inputs = append(inputs, found...)
+	}
+	if len(inputs) == 0 {
+		return nil, false, withCode(exitNotFound, i18n.Errorf("没有找到可转换的文件"))
+	}
+	return inputs, batch, nil
+}
+
+// newEngine 按 -jobs、-retries 与 -retry-backoff 创建批量转换使用的 batch.Engine
+// name 返回第 i 个任务的名称，用于输出重试的提示
+func newEngine(opts convertOptions, name func(i int) string) *batch.Engine {
+	e := batch.New(opts.Jobs)
+	e.Retries, e.Backoff = opts.Retries, opts.RetryBackoff
+	e.OnRetry = func(i, attempt int, err error) {
+		warnf(levelVerbose, "%s: %v，正在第 %d 次尝试", name(i), err, attempt, slog.String("input", name(i)), slog.Int("attempt", attempt))
+	}
+	return e
+}
+
+// convertFile 转换单个输入，未指定输出路径时生成的文件与输入文件同名，仅扩展名按输出格式变化
+// S3 上的文件输出到同一位置，其他远程文件输出到当前目录，文件名取自来源提供的文件名
+// 指定输出目录时按输入的相对路径输出到该目录下，指定 -name-template 时文件名按模板生成
+// 出错时返回的结果中仍包含已经得到的统计信息
+func convertFile(ctx context.Context, src input, opts convertOptions) (rep fileReport, err error) {
+	start := time.Now()
+	rep.Input = src.Path
+	defer func() { rep.DurationMs = time.Since(start).Milliseconds() }()
+
+	in := src.Path
+	outExt, err := outputExt(opts)
+	if err != nil {
+		return rep, err
+	}
+	logf(levelVerbose, "正在转换 %s", in, slog.String("input", in))
+
+	var sheets []xmind.Sheet
+	var inHash string
+	var cached fileReport
+	var skip bool
+	name := in
+	outFile := opts.Output
+	if isRemote(in) {
+		sheets, name, err = readRemote(ctx, in, opts.From, opts.Fetch)
+		if err != nil {
+			return rep, err
+		}
+		if src.Rel == "" {
+			src.Rel = path.Base(name)
+		}
+	} else {
+		// 输出路径不依赖解析结果时先检查输入是否没有变化，从而跳过解析与写入
+		if outFile != "" || opts.Name == nil {
+			target := outFile
+			if target == "" {
+				target, _ = defaultOutput(in, src.Rel, outExt, nil, opts, false)
+			}
+			if inHash, cached, skip = skipUnchanged(in, target, opts); skip {
+				return cached, nil
+			}
+		}
+		sheets, err = xmind.ParseFileAsContext(ctx, in, opts.From)
+		if err != nil {
+			return rep, withCode(exitParse, err)
+		}
+	}
+	if sheets, err = transformSheets(ctx, sheets, opts); err != nil {
+		return rep, err
+	}
+	if opts.SheetRegex != nil {
+		return convertSheets(ctx, src, name, sheets, opts)
+	}
+	if outFile == "" {
+		outFile, err = defaultOutput(name, src.Rel, outExt, sheets, opts, !opts.DryRun)
+		if err != nil {
+			return rep, err
+		}
+		// 按模板生成的文件名可能包含画布标题，解析后再检查输入是否没有变化
+		if !isRemote(in) && opts.Name != nil {
+			if inHash, cached, skip = skipUnchanged(in, outFile, opts); skip {
+				return cached, nil
+			}
+		}
+	}
+	return writeConverted(ctx, in, name, outFile, inHash, sheets, opts, start)
+}
+
+// writeConverted 将 in 转换得到的 sheets 写入 outFile，并按需要预览、复制到剪贴板或只列出将要写入的文件，
+// name 为来源提供的文件名，inHash 不为空时在缓存中记录生成的文件
+func writeConverted(ctx context.Context, in, name, outFile, inHash string, sheets []xmind.Sheet, opts convertOptions, start time.Time) (fileReport, error) {
+	if outFile == in || (!isRemote(in) && filepath.Clean(outFile) == filepath.Clean(in)) {
+		return fileReport{Input: in}, withCode(exitUsage, i18n.Errorf("输出文件与输入文件相同: %s", outFile))
+	}
+
+	rep := newFileReport(in, sheets, opts)
+	logSheets(rep, sheets)
+	if opts.Preview {
+		restore := suspendProgress()
+		printPreview(os.Stdout, sheets, opts)
+		restore()
+	}
+	if opts.StatsFooter {
+		opts.Write.Footer = statsFooter(path.Base(filepath.ToSlash(name)), sheets, opts)
+	}
+	if opts.Clipboard {
+		if err := copyOutput(sheets, opts); err != nil {
+			return rep, err
+		}
+	}
+	if (opts.Preview || opts.Clipboard) && opts.Output == "" && opts.OutDir == "" {
+		return rep, nil
+	}
+	if opts.DryRun {
+		rep.Output = outFile
+		rep.Action = outputAction(outFile)
+		if rep.Action == "overwrite" && !opts.Force && !opts.Backup && !opts.Cache.generated(outFile) {
+			return rep, existsError(outFile)
+		}
+		return rep, nil
+	}
+	if err := writeOutput(ctx, outFile, sheets, opts); err != nil {
+		return rep, err
+	}
+	logf(levelDebug, "已写入 %s", outFile, slog.String("input", in), slog.String("output", outFile), slog.Duration("elapsed", time.Since(start)))
+	rep.Output = outFile
+	opts.Cache.store(inHash, rep, opts)
+	return rep, nil
+}
+
+// convertSheets 将标题与 -sheet-regex 匹配的每个画布分别写入按文件名模板生成的输出文件，
+// 没有变化的本地输入按每个输出文件分别跳过；返回的结果中 Outputs 为所有输出文件，Output 为其中的第一个
+func convertSheets(ctx context.Context, src input, name string, sheets []xmind.Sheet, opts convertOptions) (fileReport, error) {
+	in := src.Path
+	rep := fileReport{Input: in}
+	outExt, err := outputExt(opts)
+	if err != nil {
+		return rep, err
+	}
+	for _, s := range sheets {
+		if !opts.SheetRegex.MatchString(sheetTitle(s)) {
+			continue
+		}
+		one := []xmind.Sheet{s}
+		outFile, err := defaultOutput(name, src.Rel, outExt, one, opts, !opts.DryRun)
+		if err != nil {
+			return rep, err
+		}
+		var inHash string
+		var fr fileReport
+		skip := false
+		if !isRemote(in) {
+			inHash, fr, skip = skipUnchanged(in, outFile, opts)
+		}
+		if !skip {
+			if fr, err = writeConverted(ctx, in, name, outFile, inHash, one, opts, time.Now()); err != nil {
+				return rep, err
+			}
+		}
+		if rep.Output == "" {
+			rep.Output, rep.Title, rep.Action, rep.Skipped = fr.Output, fr.Title, fr.Action, fr.Skipped
+		}
+		// 只有所有输出文件都没有变化时才算作跳过
+		rep.Skipped = rep.Skipped && fr.Skipped
+		rep.Outputs = append(rep.Outputs, fr.Output)
+		rep.Sheets += fr.Sheets
+		rep.Topics += fr.Topics
+		rep.Warnings = append(rep.Warnings, fr.Warnings...)
+	}
+	if len(rep.Outputs) == 0 {
+		warnf(levelNormal, "%s: 没有标题与 -sheet-regex 匹配的画布", in, slog.String("input", in))
+	}
+	return rep, nil
+}
+
+// logSheets 输出解析得到的画布以及转换时会丢失的内容
+func logSheets(rep fileReport, sheets []xmind.Sheet) {
+	for i, s := range sheets {
+		title := s.Title
+		if title == "" {
+			title = s.RootTopic.Title
+		}
+		logf(levelDebug, "  画布 %d %q: %d 个节点", i+1, title, s.TopicCount(),
+			slog.String("input", rep.Input), slog.Int("sheet", i+1), slog.Int("topics", s.TopicCount()))
+	}
+	for _, w := range rep.Warnings {
+		warnf(levelVerbose, "%s: %s", rep.Input, w, slog.String("input", rep.Input))
+	}
+}
+
+// skipUnchanged 判断本地输入 in 是否可以跳过，同时返回输入内容的摘要供写入后更新缓存
+// 跳过时返回上次转换的统计信息；指定 -force-rebuild 时从不跳过
+func skipUnchanged(in, target string, opts convertOptions) (string, fileReport, bool) {
+	inHash, cached, hit := opts.Cache.lookup(in, target, opts)
+	if !hit || opts.ForceRebuild {
+		return inHash, fileReport{}, false
+	}
+	logf(levelVerbose, "%s 没有变化，跳过", in, slog.String("input", in))
+	if opts.DryRun {
+		cached.Action = "skip"
+	}
+	return inHash, cached, true
+}
+
+// defaultOutput 计算未指定 -o 时的输出路径：指定了输出目录时按相对路径 rel 输出到其中，否则与 name 位于同一目录
+// create 为 true 时自动创建输出目录中所需的子目录
+func defaultOutput(name, rel, ext string, sheets []xmind.Sheet, opts convertOptions, create bool) (string, error) {
+	if opts.OutDir == "" {
+		return outputName(name, ext, sheets, opts)
+	}
+	rel, err := outputName(rel, ext, sheets, opts)
+	if err != nil {
+		return "", err
+	}
+	return outDirPath(opts.OutDir, rel, create)
+}
+
+// outputName 将 name（可以包含目录）中的文件名替换为输出文件名
+// 默认为原文件名替换扩展名，指定 -name-template 时按模板生成
+func outputName(name, ext string, sheets []xmind.Sheet, opts convertOptions) (string, error) {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	if opts.Name == nil {
+		return base + ext, nil
+	}
+	dir, file := filepath.Split(base)
+	file, err := renderName(opts.Name, file, ext, sheets)
+	if err != nil {
+		return "", err
+	}
+	return dir + file, nil
+}
+
+// outputAction 返回写入 outFile 时的操作：create（新建）、overwrite（覆盖）、upload（上传到 S3）或 stdout
+func outputAction(outFile string) string {
+	switch {
+	case outFile == "-":
+		return "stdout"
+	case isS3(outFile):
+		return "upload"
+	}
+	if _, err := os.Stat(outFile); err == nil {
+		return "overwrite"
+	}
+	return "create"
+}
+
+// planOutput 在 -dry-run 时检查多个输入是否输出到同一个文件，planned 记录已经列出的输出文件
+// 同一次运行中较早的输入会先写入该文件，因此后面的输入视为覆盖
+func planOutput(fr *fileReport, planned map[string]bool, opts convertOptions) error {
+	if fr.Output == "" || fr.Output == "-" {
+		return nil
+	}
+	defer func() { planned[fr.Output] = true }()
+	if !planned[fr.Output] {
+		return nil
+	}
+	if !opts.Force && !opts.Backup {
+		return existsError(fr.Output)
+	}
+	fr.Action = "overwrite"
+	return nil
+}
+
+func existsError(outFile string) error {
+	return withCode(exitExists, i18n.Errorf("输出文件已存在: %s，使用 --force 覆盖或 --backup 保留备份", outFile))
+}
+
+// protectOutput 检查本地输出文件是否已存在，已存在时只有指定了 -force 或 -backup 才允许覆盖
+// 缓存中记录的由本程序生成且之后没有被修改过的文件可以直接覆盖
+// 指定 -backup 时先将原文件复制为 <文件名>.<时间戳>.bak
+func protectOutput(outFile string, opts convertOptions) (err error) {
+	if outFile == "-" || isS3(outFile) {
+		return nil
+	}
+	// 先判断是否为本程序生成的文件，claim 之后本次运行写入的文件不再视为可以覆盖
+	generated := opts.Cache.generated(outFile)
+	claimed := opts.Cache.claim(outFile)
+	if !claimed && !opts.Force {
+		return existsError(outFile)
+	}
+	if claimed {
+		defer func() {
+			if err != nil {
+				opts.Cache.unclaim(outFile)
+			}
+		}()
+	}
+	info, err := os.Stat(outFile)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() {
+		return withCode(exitWrite, i18n.Errorf("输出路径是一个目录: %s", outFile))
+	}
+	switch {
+	case opts.Backup:
+		backup := outFile + "." + time.Now().Format("20060102-150405") + ".bak"
+		data, err := os.ReadFile(outFile)
+		if err == nil {
+			err = os.WriteFile(backup, data, info.Mode().Perm())
+		}
+		if err != nil {
+			return withCode(exitWrite, i18n.Errorf("备份 %s 失败: %v", outFile, err))
+		}
+		logf(levelVerbose, "已将 %s 备份为 %s", outFile, backup, slog.String("output", outFile), slog.String("backup", backup))
+	case !opts.Force && !generated:
+		return existsError(outFile)
+	}
+	return nil
+}
+
+// writeOutput 按输出格式将 Sheet 列表写入 outFile，已存在的文件按 protectOutput 处理
+func writeOutput(ctx context.Context, outFile string, sheets []xmind.Sheet, opts convertOptions) (err error) {
+	if opts.chunked() && outFile != "-" {
+		return writeChunks(ctx, outFile, sheets, opts)
+	}
+	if err := protectOutput(outFile, opts); err != nil {
+		return err
+	}
+	// 没有写成时撤销 claim，使重试时可以再次写入
+	defer func() {
+		if err != nil {
+			opts.Cache.unclaim(outFile)
+		}
+	}()
+	out, err := createOutput(ctx, outFile, opts.Fetch)
+	if err != nil {
+		return withCode(exitWrite, err)
+	}
+	err = render.WriteAsContext(ctx, opts.To, out, sheets, opts.Write)
+	if a, ok := out.(aborter); ok && err != nil {
+		a.Abort()
+	} else if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return withCode(exitWrite, i18n.Errorf("写入输出文件失败: %w", err))
+	}
+	return nil
+}
+
+// copyOutput 按输出格式将 Sheet 列表复制到系统剪贴板
+func copyOutput(sheets []xmind.Sheet, opts convertOptions) error {
+	var buf bytes.Buffer
+	// BOM 只用于文件，粘贴时会成为多余的字符
+	write := opts.Write
+	write.BOM = false
+	if err := render.WriteAsOptions(opts.To, &buf, sheets, write); err != nil {
+		return withCode(exitWrite, err)
+	}
+	return withCode(exitWrite, copyToClipboard(buf.Bytes()))
+}
+
+// outDirPath 计算输出目录中的目标路径，rel 为输出文件的相对路径，create 为 true 时自动创建本地目录中所需的子目录
+func outDirPath(dir, rel string, create bool) (string, error) {
+	if isS3(dir) {
+		return strings.TrimSuffix(dir, "/") + "/" + filepath.ToSlash(rel), nil
+	}
+	p := filepath.Join(dir, rel)
+	if !create {
+		return p, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return "", withCode(exitWrite, i18n.Errorf("创建输出目录失败: %w", err))
+	}
+	return p, nil
+}
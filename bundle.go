@@ -0,0 +1,131 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+)
+
+// convertBundle 按 -bundle 将所有输入转换到临时目录，连同 .xmind 文件中的资源（以及 -manifest 指定的清单）
+// 打包为一个 zip 文件，转换报告中的输出路径改为压缩包中的路径
+func convertBundle(ctx context.Context, files []string, filter fileFilter, opts convertOptions, rep *runReport) error {
+	if opts.Output != "" || opts.OutDir != "" || opts.Merge {
+		return withCode(exitUsage, i18n.Errorf("-bundle 不能与 -o、-out-dir 或 -merge 同时使用"))
+	}
+	if opts.DryRun || opts.Preview || opts.Clipboard {
+		return withCode(exitUsage, i18n.Errorf("-bundle 不能与 -dry-run、-preview 或 -clipboard 同时使用"))
+	}
+	dir, err := os.MkdirTemp("", "xmind2md-bundle-")
+	if err != nil {
+		return withCode(exitWrite, i18n.Errorf("创建临时目录失败: %v", err))
+	}
+	defer os.RemoveAll(dir)
+	opts.OutDir = dir
+	err = interrupted(ctx, convertAll(ctx, files, filter, opts, rep))
+	if rep.Succeeded == 0 || ctx.Err() != nil {
+		return err
+	}
+
+	// 每个 .xmind 输入的资源保存在输出文件旁边与输入同名的目录中，与 extract 默认的位置一致
+	for _, f := range rep.Files {
+		if f.Error != "" || len(f.outputs()) == 0 || isRemote(f.Input) {
+			continue
+		}
+		wb, werr := loadWorkbook(ctx, f.Input, opts.From)
+		if werr != nil || !wb.zipped() || wb.Format != "xmind" {
+			continue
+		}
+		base := filepath.Base(f.Input)
+		res := filepath.Join(filepath.Dir(f.outputs()[0]), strings.TrimSuffix(base, filepath.Ext(base)))
+		if _, werr := render.ExtractResources(ctx, bytes.NewReader(wb.data), wb.Size, res, nil, render.WriteOptions{}); werr != nil {
+			return &fileError{path: f.Input, err: withCode(exitWrite, werr)}
+		}
+	}
+	if opts.Manifest != "" {
+		if merr := writeManifest(filepath.Join(dir, filepath.Base(opts.Manifest)), rep, err, opts); merr != nil {
+			return merr
+		}
+	}
+	n, zerr := writeBundle(ctx, opts.Bundle, dir, opts)
+	if zerr != nil {
+		return zerr
+	}
+
+	for i := range rep.Files {
+		f := &rep.Files[i]
+		f.Output = bundlePath(dir, f.Output)
+		for j := range f.Outputs {
+			f.Outputs[j] = bundlePath(dir, f.Outputs[j])
+		}
+	}
+	generatedFiles = nil
+	recordOutput(opts.Bundle)
+	if verbosity > levelQuiet && !opts.reportToStdout() {
+		fmt.Printf(i18n.T("已打包到 %s（%d 个文件）\n"), opts.Bundle, n)
+	}
+	return err
+}
+
+// writeBundle 将目录 dir 中的所有文件按相对路径写入 zip 文件 bundle，返回写入的文件数；
+// 已存在的 bundle 按 protectOutput 处理，-deterministic 时文件不带修改时间
+func writeBundle(ctx context.Context, bundle, dir string, opts convertOptions) (int, error) {
+	if err := protectOutput(bundle, opts); err != nil {
+		return 0, err
+	}
+	out, err := createOutput(ctx, bundle, opts.Fetch)
+	if err != nil {
+		return 0, withCode(exitWrite, err)
+	}
+	// 不写入时间时 modified 为零值
+	modified, _ := conversionTime(opts)
+	n := 0
+	zw := zip.NewWriter(out)
+	err = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: bundlePath(dir, p), Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(w, f); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if a, ok := out.(aborter); ok && err != nil {
+		a.Abort()
+	} else if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return 0, withCode(exitWrite, i18n.Errorf("写入 %s 失败: %v", bundle, err))
+	}
+	return n, nil
+}
+
+// bundlePath 返回临时目录 dir 中的文件 p 在压缩包中的路径，不在 dir 中时原样返回
+func bundlePath(dir, p string) string {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return p
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// buildCache 记录本地输入与输出文件的内容摘要，重复转换时跳过输入没有变化的文件
+// 缓存保存在用户缓存目录下的 xmind2md/cache.json 中，以输出文件的绝对路径为键
+type buildCache struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	changed bool
+	// written 记录本次运行中已经写入的输出文件
+	written map[string]bool
+}
+
+// cacheEntry 为一个输出文件上次生成时的记录
+type cacheEntry struct {
+	// Input 与 Output 为输入与输出内容的 SHA-256
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	// Options 为影响输出内容的参数与程序版本的摘要
+	Options string `json:"options"`
+	// 以下为上次转换的统计信息，跳过时用于汇总、报告与目录
+	Title    string   `json:"title,omitempty"`
+	Sheets   int      `json:"sheets"`
+	Topics   int      `json:"topics"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// loadBuildCache 读取增量转换的缓存，无法确定缓存目录时返回 nil，此时不使用缓存
+// 缓存文件不存在或已损坏时从空缓存开始
+func loadBuildCache() *buildCache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	c := &buildCache{path: filepath.Join(dir, "xmind2md", "cache.json"), entries: map[string]cacheEntry{}, written: map[string]bool{}}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		logf(levelDebug, "忽略无法读取的缓存 %s: %v", c.path, err)
+		c.entries = map[string]cacheEntry{}
+	}
+	return c
+}
+
+// save 写回缓存，同时删除输出文件已不存在的记录
+func (c *buildCache) save() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for out := range c.entries {
+		if _, err := os.Stat(out); err != nil {
+			delete(c.entries, out)
+			c.changed = true
+		}
+	}
+	if !c.changed {
+		return
+	}
+	data, err := json.Marshal(c.entries)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.path), 0o755)
+	}
+	if err == nil {
+		var f *atomicFile
+		if f, err = createAtomic(c.path); err == nil {
+			if _, err = f.Write(data); err != nil {
+				f.Abort()
+			} else {
+				err = f.Close()
+			}
+		}
+	}
+	if err != nil {
+		warnf(levelVerbose, "保存缓存失败: %v", err)
+		return
+	}
+	c.changed = false
+}
+
+// newRun 开始新的一轮转换，watch 每次转换发生变化的文件前调用
+func (c *buildCache) newRun() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.written = map[string]bool{}
+	c.mu.Unlock()
+}
+
+// lookup 判断输入 in 与上次生成 out 时相比是否没有变化，同时返回输入内容的摘要供 store 使用
+// 输入、参数与输出文件都与记录一致时才视为没有变化；目录形式的输入不使用缓存，返回的摘要为空
+func (c *buildCache) lookup(in, out string, opts convertOptions) (inHash string, rep fileReport, ok bool) {
+	if c == nil {
+		return "", rep, false
+	}
+	inHash, err := hashFile(in)
+	if err != nil {
+		return "", rep, false
+	}
+	c.mu.Lock()
+	e, found := c.entries[cacheKey(out)]
+	c.mu.Unlock()
+	if !found || e.Input != inHash || e.Options != optionsHash(opts) {
+		return inHash, rep, false
+	}
+	if h, err := hashFile(out); err != nil || h != e.Output {
+		return inHash, rep, false
+	}
+	rep = fileReport{Input: in, Output: out, Title: e.Title, Sheets: e.Sheets, Topics: e.Topics, Warnings: e.Warnings, Skipped: true}
+	return inHash, rep, true
+}
+
+// store 记录刚刚由 in 生成的输出文件 rep.Output
+func (c *buildCache) store(inHash string, rep fileReport, opts convertOptions) {
+	if c == nil || inHash == "" {
+		return
+	}
+	outHash, err := hashFile(rep.Output)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey(rep.Output)] = cacheEntry{
+		Input:    inHash,
+		Output:   outHash,
+		Options:  optionsHash(opts),
+		Title:    rep.Title,
+		Sheets:   rep.Sheets,
+		Topics:   rep.Topics,
+		Warnings: rep.Warnings,
+	}
+	c.changed = true
+	c.written[cacheKey(rep.Output)] = true
+}
+
+// storeOutput 记录刚刚生成的附属输出文件，如拆分后的其余部分，使之后的转换可以直接覆盖
+func (c *buildCache) storeOutput(out string) {
+	if c == nil {
+		return
+	}
+	outHash, err := hashFile(out)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey(out)] = cacheEntry{Output: outHash}
+	c.changed = true
+	c.written[cacheKey(out)] = true
+}
+
+// claim 记录本次运行即将写入 out，out 已经被本次运行中的其他输入写入时返回 false
+// 并行转换时用于避免多个输入同时写入同一个文件
+func (c *buildCache) claim(out string) bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.written[cacheKey(out)] {
+		return false
+	}
+	c.written[cacheKey(out)] = true
+	return true
+}
+
+// unclaim 撤销 claim，用于没有能够写成 out 的情况，使重试时可以再次写入
+func (c *buildCache) unclaim(out string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.written, cacheKey(out))
+}
+
+// generated 判断 out 是否为本程序生成且之后没有被修改过，这样的文件可以直接覆盖
+// 本次运行中刚由其他输入写入的文件不算，以免多个输入输出到同一个文件时互相覆盖
+func (c *buildCache) generated(out string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	e, found := c.entries[cacheKey(out)]
+	written := c.written[cacheKey(out)]
+	c.mu.Unlock()
+	if !found || written {
+		return false
+	}
+	h, err := hashFile(out)
+	return err == nil && h == e.Output
+}
+
+func cacheKey(out string) string {
+	if abs, err := filepath.Abs(out); err == nil {
+		return abs
+	}
+	return out
+}
+
+// optionsHash 计算影响输出内容的参数的摘要，程序版本不同时同样视为参数变化
+func optionsHash(opts convertOptions) string {
+	ver, rev, _ := buildInfo()
+	// 解析后的模板只能打印出地址，改用模板的内容
+	write := opts.Write
+	write.Template = nil
+	// -stats-footer 的内容包含转换时间，只记录是否指定
+	write.Footer = ""
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s %s|%s|%s|%+v|%d|%d|%s|%s|%q|%q|%t", ver, rev, opts.From, opts.To, write, opts.ChunkLevel, opts.MaxFileSize, opts.TemplateText, opts.TransformText, opts.Root, opts.RootID, opts.StatsFooter)))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashFile 计算文件内容的 SHA-256，目录返回错误
+func hashFile(p string) (string, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var checkCommand = &command{
+	Name:  "check",
+	Args:  "[参数] <思维导图> <已导出的文件>",
+	Short: "重新转换思维导图并与已导出的文件比较，不一致时输出差异并以退出码 11 结束",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var filter fileFilter
+		var lines int
+		defineOutputFlags(fs, &opts, &filter)
+		fs.IntVar(&lines, "context", 3, "差异中每处修改前后保留的行数")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return withCode(exitUsage, i18n.Errorf("check 需要指定思维导图与已导出的文件"))
+			}
+			if err := prepareOptions(&opts); err != nil {
+				return err
+			}
+			if opts.To == "xmind" {
+				return withCode(exitUsage, i18n.Errorf("check 只支持文本格式的输出"))
+			}
+			if lines < 0 {
+				return withCode(exitUsage, i18n.Errorf("-context 不能为负数"))
+			}
+			in, exported := args[0], args[1]
+			sheets, name, err := readInputSheets(ctx, in, opts)
+			if err == nil {
+				sheets, err = transformSheets(ctx, sheets, opts)
+			}
+			if err != nil {
+				return &fileError{path: in, err: err}
+			}
+			if opts.StatsFooter {
+				opts.Write.Footer = statsFooter(path.Base(filepath.ToSlash(name)), sheets, opts)
+			}
+			paths, parts, err := renderExpected(ctx, exported, sheets, opts)
+			if err != nil {
+				return withCode(exitWrite, err)
+			}
+
+			drifted := 0
+			for i, p := range paths {
+				changed, err := checkDrift(p, parts[i], in, lines)
+				if err != nil {
+					return err
+				}
+				if changed {
+					drifted++
+				}
+			}
+			if drifted > 0 {
+				return withCode(exitDifferent, i18n.Errorf("%d 个文件与重新转换的结果不一致", drifted))
+			}
+			logf(levelNormal, "%s 与 %s 一致", strings.Join(paths, ", "), in)
+			return nil
+		}
+	},
+}
+
+// renderExpected 返回 sheets 按 opts 转换后应当得到的文件与内容，拆分输出时为每个部分，与 writeOutput 写出的一致
+func renderExpected(ctx context.Context, exported string, sheets []xmind.Sheet, opts convertOptions) ([]string, []string, error) {
+	if opts.chunked() {
+		return chunkParts(ctx, exported, sheets, opts)
+	}
+	var buf bytes.Buffer
+	if err := render.WriteAsContext(ctx, opts.To, &buf, sheets, opts.Write); err != nil {
+		return nil, nil, err
+	}
+	return []string{exported}, []string{buf.String()}, nil
+}
+
+// checkDrift 比较已导出的文件 exported 与重新转换得到的内容 want，不一致时输出从前者变为后者的差异，
+// 文件不存在时视为空文件
+func checkDrift(exported, want, in string, lines int) (bool, error) {
+	data, err := os.ReadFile(exported)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, &fileError{path: exported, err: i18n.Errorf("打开文件失败: %w", err)}
+	}
+	if string(data) == want {
+		return false, nil
+	}
+	if err != nil {
+		warnf(levelNormal, "%s 不存在", exported)
+	}
+	changed, werr := writeUnified(os.Stdout, exported, i18n.Sprintf("%s（重新转换）", in), splitLines(string(data)), splitLines(want), lines)
+	if werr != nil {
+		return false, withCode(exitWrite, werr)
+	}
+	// 只有行尾的换行符不同时逐行比较没有差异
+	if !changed {
+		warnf(levelNormal, "%s 与重新转换的结果只有换行符不同", exported)
+	}
+	return true, nil
+}
+
+// splitLines 将 s 按 \n 拆分为行，末尾的换行符不产生空行
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var checkLinksCommand = &command{
+	Name:  "check-links",
+	Args:  "[参数] <文件>...",
+	Short: "检查思维导图中的外部链接是否可以访问，列出失效的链接及其所在的节点",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var timeout time.Duration
+		var jobs int
+		var asJSON bool
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.DurationVar(&timeout, "timeout", 10*time.Second, "检查每个链接的超时时间")
+		fs.IntVar(&jobs, "jobs", 8, "同时检查的链接数")
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出失效的链接")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return withCode(exitUsage, i18n.Errorf("check-links 需要指定至少一个文件"))
+			}
+			if jobs < 1 || timeout <= 0 {
+				return withCode(exitUsage, i18n.Errorf("-jobs 与 -timeout 应大于 0"))
+			}
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+			var refs []linkRef
+			for _, in := range args {
+				sheets, _, err := readInputSheets(ctx, in, opts)
+				if err != nil {
+					return &fileError{path: in, err: err}
+				}
+				refs = append(refs, collectLinks(in, sheets)...)
+			}
+			results := checkLinks(ctx, refs, &http.Client{Timeout: timeout}, jobs)
+			if ctx.Err() != nil {
+				return errInterrupted()
+			}
+
+			dead := []linkRef{}
+			for _, r := range refs {
+				if res := results[r.URL]; res.dead() {
+					r.Status, r.Error = res.Status, res.Error
+					dead = append(dead, r)
+				}
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(dead); err != nil {
+					return withCode(exitWrite, err)
+				}
+			} else {
+				printDeadLinks(os.Stdout, dead)
+			}
+			logf(levelVerbose, "检查了 %d 个链接（%d 个不同的地址）", len(refs), len(results))
+			if len(dead) > 0 {
+				return withCode(exitLinks, i18n.Errorf("%d 个链接失效", len(dead)))
+			}
+			return nil
+		}
+	},
+}
+
+// linkRef 为节点中的一个外部链接，Status 与 Error 为检查的结果
+type linkRef struct {
+	File string `json:"file"`
+	// Path 为节点的位置，依次为画布（有标题时）与各级节点的标题
+	Path    []string `json:"path"`
+	TopicID string   `json:"topicId,omitempty"`
+	URL     string   `json:"url"`
+	Status  int      `json:"status,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// linkResult 为检查一个地址的结果，Status 为最终的 HTTP 状态码，请求失败时为 0 并记录 Error
+type linkResult struct {
+	Status int
+	Error  string
+}
+
+func (r linkResult) dead() bool {
+	return r.Error != "" || r.Status >= 400
+}
+
+// describe 返回检查结果的说明，如 404 Not Found 或请求失败的原因
+func (r linkResult) describe() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status))
+}
+
+// collectLinks 返回 sheets 中所有 http 与 https 链接，内部链接、附件与 mailto: 等其他链接不检查
+func collectLinks(file string, sheets []xmind.Sheet) []linkRef {
+	var refs []linkRef
+	for i := range sheets {
+		s := &sheets[i]
+		s.Walk(func(path []*xmind.Topic, t *xmind.Topic) error {
+			if u, err := url.Parse(strings.TrimSpace(t.Href)); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+				refs = append(refs, linkRef{File: file, Path: topicTrail(s, path, t), TopicID: t.ID, URL: u.String()})
+			}
+			return nil
+		})
+	}
+	return refs
+}
+
+// checkLinks 用 jobs 个 goroutine 检查 refs 中的地址，同一个地址只检查一次，返回每个地址的结果
+// ctx 被取消后不再开始新的检查
+func checkLinks(ctx context.Context, refs []linkRef, client *http.Client, jobs int) map[string]linkResult {
+	results := map[string]linkResult{}
+	urls := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range urls {
+				res := checkLink(ctx, client, u)
+				mu.Lock()
+				results[u] = res
+				mu.Unlock()
+				if res.dead() {
+					logf(levelVerbose, "%s: %s", u, res.describe())
+				}
+			}
+		}()
+	}
+	seen := map[string]bool{}
+	for _, r := range refs {
+		if seen[r.URL] || ctx.Err() != nil {
+			continue
+		}
+		seen[r.URL] = true
+		urls <- r.URL
+	}
+	close(urls)
+	wg.Wait()
+	return results
+}
+
+// checkLink 用 HEAD 请求检查地址，服务器不支持 HEAD 或返回错误时改用 GET 再试一次（不读取响应内容）
+func checkLink(ctx context.Context, client *http.Client, u string) linkResult {
+	res := requestLink(ctx, client, http.MethodHead, u)
+	if res.dead() && ctx.Err() == nil {
+		res = requestLink(ctx, client, http.MethodGet, u)
+	}
+	return res
+}
+
+func requestLink(ctx context.Context, client *http.Client, method, u string) linkResult {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return linkResult{Error: err.Error()}
+	}
+	req.Header.Set("User-Agent", "xmindtomarkdown")
+	resp, err := client.Do(req)
+	if err != nil {
+		return linkResult{Error: err.Error()}
+	}
+	resp.Body.Close()
+	return linkResult{Status: resp.StatusCode}
+}
+
+// printDeadLinks 每个失效的链接输出一行：文件: 位置: 地址 (原因)
+func printDeadLinks(w io.Writer, dead []linkRef) {
+	for _, r := range dead {
+		fmt.Fprintf(w, "%s: %s: %s (%s)\n", r.File, strings.Join(r.Path, " > "), r.URL, linkResult{Status: r.Status, Error: r.Error}.describe())
+	}
+	if len(dead) == 0 {
+		fmt.Fprintln(w, i18n.T("没有发现失效的链接"))
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// chunked 判断是否需要把 Markdown 输出拆分为多个文件
+func (opts convertOptions) chunked() bool {
+	return opts.To == "md" && (opts.ChunkLevel > 0 || opts.MaxFileSize > 0)
+}
+
+// writeChunks 将 Markdown 输出按 -chunk-level 层级的标题拆分为多个文件，第一部分写入 outFile，
+// 其余部分依次写入 <文件名>-2.md、<文件名>-3.md……，每个文件末尾带有上一部分与下一部分的链接
+// 指定了 -max-file-size 时相邻的小节合并到同一个文件中，直到超过大小限制
+func writeChunks(ctx context.Context, outFile string, sheets []xmind.Sheet, opts convertOptions) error {
+	paths, parts, err := chunkParts(ctx, outFile, sheets, opts)
+	if err != nil {
+		return withCode(exitWrite, err)
+	}
+	for i, part := range parts {
+		if err := writeChunk(ctx, paths[i], part, opts); err != nil {
+			return err
+		}
+		if i > 0 {
+			opts.Cache.storeOutput(paths[i])
+			logf(levelVerbose, "已写入 %s", paths[i], slog.String("output", paths[i]))
+		}
+	}
+	// 上次拆分出的部分更多时，删除多余的、由本程序生成且没有被修改过的文件
+	for i := len(parts); !isS3(outFile) && opts.Cache.generated(chunkPath(outFile, i)); i++ {
+		if err := os.Remove(chunkPath(outFile, i)); err != nil {
+			break
+		}
+		logf(levelVerbose, "已删除多余的 %s", chunkPath(outFile, i))
+	}
+	return nil
+}
+
+// chunkParts 生成拆分后各部分的输出路径与内容，多于一部分时每部分末尾带有上一部分与下一部分的链接
+func chunkParts(ctx context.Context, outFile string, sheets []xmind.Sheet, opts convertOptions) ([]string, []string, error) {
+	var buf bytes.Buffer
+	if err := render.WriteAsContext(ctx, opts.To, &buf, sheets, opts.Write); err != nil {
+		return nil, nil, err
+	}
+	parts := splitChunks(buf.String(), chunkHeading(opts), opts.MaxFileSize)
+	paths := make([]string, len(parts))
+	for i := range parts {
+		paths[i] = chunkPath(outFile, i)
+	}
+	if len(parts) > 1 {
+		linked, err := linkChunks(ctx, sheets, parts, paths, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if linked != nil {
+			parts = linked
+		}
+		for i := range parts {
+			parts[i] = strings.TrimRight(parts[i], "\n") + "\n\n" + chunkNav(paths, i) + "\n"
+		}
+	}
+	return paths, parts, nil
+}
+
+// linkChunks 重新写出 sheets，使 xmind:#ID 形式的内部链接与目录中的条目指向拆分后对应的文件与锚点，
+// 并在与 parts 相同的标题处拆分；parts 中的标题与节点对应不上时（如以 "# " 开头的段落）返回 nil，保持原来的链接
+func linkChunks(ctx context.Context, sheets []xmind.Sheet, parts, paths []string, opts convertOptions) ([]string, error) {
+	headings := render.MarkdownHeadings(sheets, opts.Write)
+	// starts 为每部分中第一个标题的序号
+	starts := make([]int, len(parts))
+	n := 0
+	for i, part := range parts {
+		starts[i] = n
+		for _, line := range strings.SplitAfter(part, "\n") {
+			if level := headingLevel(line); level > 0 {
+				if n >= len(headings) || headings[n].Level != level {
+					return nil, nil
+				}
+				n++
+			}
+		}
+	}
+	if n != len(headings) {
+		return nil, nil
+	}
+	write := opts.Write
+	write.Links = map[string]string{}
+	// 目录在第一部分中，其中的标题仍链接到同一文件中的锚点
+	write.TOCLinks = make([]string, len(headings))
+	for i := range parts {
+		end := len(headings)
+		if i+1 < len(parts) {
+			end = starts[i+1]
+		}
+		// 每个文件中的锚点单独编号
+		for j, anchor := range render.HeadingAnchors(headings[starts[i]:end]) {
+			target := chunkLink(paths[i]) + "#" + anchor
+			if i > 0 {
+				write.TOCLinks[starts[i]+j] = target
+			}
+			for _, id := range headings[starts[i]+j].IDs {
+				write.Links[id] = target
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := render.WriteAsContext(ctx, opts.To, &buf, sheets, write); err != nil {
+		return nil, err
+	}
+	return splitAtHeadings(buf.String(), starts), nil
+}
+
+// splitAtHeadings 在序号为 starts[1:] 的标题处拆分 Markdown，starts 为 linkChunks 中每部分第一个标题的序号
+func splitAtHeadings(md string, starts []int) []string {
+	var parts []string
+	var cur strings.Builder
+	n := 0
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if headingLevel(line) > 0 {
+			if len(parts)+1 < len(starts) && n == starts[len(parts)+1] {
+				parts = append(parts, cur.String())
+				cur.Reset()
+			}
+			n++
+		}
+		cur.WriteString(line)
+	}
+	return append(parts, cur.String())
+}
+
+func writeChunk(ctx context.Context, p, content string, opts convertOptions) (err error) {
+	if err := protectOutput(p, opts); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			opts.Cache.unclaim(p)
+		}
+	}()
+	out, err := createOutput(ctx, p, opts.Fetch)
+	if err != nil {
+		return withCode(exitWrite, err)
+	}
+	_, err = out.Write([]byte(content))
+	if a, ok := out.(aborter); ok && err != nil {
+		a.Abort()
+	} else if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return withCode(exitWrite, i18n.Errorf("写入输出文件失败: %w", err))
+	}
+	return nil
+}
+
+// chunkHeading 返回拆分位置的标题级别，-chunk-level 为节点的层级，根节点为第 1 层，没有指定时按第 2 层拆分
+func chunkHeading(opts convertOptions) int {
+	level := opts.ChunkLevel
+	if level < 2 {
+		level = 2
+	}
+	start := opts.Write.HeadingStart
+	if start < 1 {
+		start = 1
+	}
+	if h := start + level - 1; h < 6 {
+		return h
+	}
+	return 6
+}
+
+// splitChunks 在级别不低于 heading 的标题处拆分 Markdown，级别更高的上级标题与其后的第一个小节放在一起
+// maxSize 大于 0 时合并相邻的小节，使每部分尽量不超过 maxSize 字节，单个小节超过限制时单独成为一部分
+func splitChunks(md string, heading int, maxSize int64) []string {
+	var sections []string
+	var cur strings.Builder
+	// parentOnly 表示当前小节只有上级标题，还没有遇到拆分级别的标题
+	parentOnly := false
+	for _, line := range strings.SplitAfter(md, "\n") {
+		if level := headingLevel(line); level > 0 && level <= heading && cur.Len() > 0 && !parentOnly {
+			sections = append(sections, cur.String())
+			cur.Reset()
+		}
+		if level := headingLevel(line); level > 0 && level <= heading {
+			parentOnly = level < heading
+		}
+		cur.WriteString(line)
+	}
+	if strings.TrimSpace(cur.String()) != "" || len(sections) == 0 {
+		sections = append(sections, cur.String())
+	}
+	if maxSize <= 0 {
+		return sections
+	}
+	var parts []string
+	var part strings.Builder
+	for _, s := range sections {
+		if part.Len() > 0 && int64(part.Len()+len(s)) > maxSize {
+			parts = append(parts, part.String())
+			part.Reset()
+		}
+		part.WriteString(s)
+	}
+	return append(parts, part.String())
+}
+
+// headingLevel 返回 ATX 标题行的级别，不是标题时返回 0
+func headingLevel(line string) int {
+	n := 0
+	for n < len(line) && line[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 || n == len(line) || (line[n] != ' ' && line[n] != '\n') {
+		return 0
+	}
+	return n
+}
+
+// chunkPath 返回第 i 部分（从 0 开始）的输出路径，第一部分使用 outFile 本身
+func chunkPath(outFile string, i int) string {
+	if i == 0 {
+		return outFile
+	}
+	ext := path.Ext(outFile)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(outFile, ext), i+1, ext)
+}
+
+// chunkNav 返回第 i 部分末尾指向上一部分与下一部分的链接，链接使用相对于同一目录的文件名
+func chunkNav(paths []string, i int) string {
+	var links []string
+	if i > 0 {
+		links = append(links, i18n.Sprintf("[← 上一部分](%s)", chunkLink(paths[i-1])))
+	}
+	links = append(links, i18n.Sprintf("第 %d / %d 部分", i+1, len(paths)))
+	if i+1 < len(paths) {
+		links = append(links, i18n.Sprintf("[下一部分 →](%s)", chunkLink(paths[i+1])))
+	}
+	return "---\n\n" + strings.Join(links, " | ")
+}
+
+// chunkLink 返回链接中使用的文件名，空格等字符按 URL 编码
+func chunkLink(p string) string {
+	name := path.Base(strings.ReplaceAll(p, `\`, "/"))
+	return linkEscaper.Replace(name)
+}
+
+// linkEscaper 对 Markdown 链接目标中的空格与括号进行 URL 编码
+var linkEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29")
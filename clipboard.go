@@ -0,0 +1,50 @@
+//go:build !windows
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// clipboardCommands 为各平台写入剪贴板的命令，按顺序使用第一个可用的命令
+func clipboardCommands() [][]string {
+	if runtime.GOOS == "darwin" {
+		return [][]string{{"pbcopy"}}
+	}
+	var cmds [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	return append(cmds,
+		[]string{"xclip", "-selection", "clipboard"},
+		[]string{"xsel", "--clipboard", "--input"},
+		[]string{"termux-clipboard-set"},
+	)
+}
+
+// copyToClipboard 将文本写入系统剪贴板
+func copyToClipboard(data []byte) error {
+	var names []string
+	for _, args := range clipboardCommands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			names = append(names, args[0])
+			continue
+		}
+		cmd := exec.Command(path, args[1:]...)
+		cmd.Stdin = bytes.NewReader(data)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return i18n.Errorf("写入剪贴板失败: %v %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	return i18n.Errorf("没有找到可用的剪贴板工具（需要 %s 之一）", strings.Join(names, "、"))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	procSetClipboardData = user32.NewProc("SetClipboardData")
+	procGlobalAlloc      = kernel32.NewProc("GlobalAlloc")
+	procGlobalFree       = kernel32.NewProc("GlobalFree")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	procRtlMoveMemory    = kernel32.NewProc("RtlMoveMemory")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+// copyToClipboard 将文本以 CF_UNICODETEXT 格式写入系统剪贴板
+func copyToClipboard(data []byte) error {
+	text, err := syscall.UTF16FromString(string(data))
+	if err != nil {
+		return i18n.Errorf("写入剪贴板失败: %v", err)
+	}
+	if r, _, err := procOpenClipboard.Call(0); r == 0 {
+		return i18n.Errorf("打开剪贴板失败: %v", err)
+	}
+	defer procCloseClipboard.Call()
+	if r, _, err := procEmptyClipboard.Call(); r == 0 {
+		return i18n.Errorf("清空剪贴板失败: %v", err)
+	}
+
+	size := uintptr(len(text) * 2)
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, size)
+	if h == 0 {
+		return i18n.Errorf("写入剪贴板失败: %v", err)
+	}
+	p, _, err := procGlobalLock.Call(h)
+	if p == 0 {
+		procGlobalFree.Call(h)
+		return i18n.Errorf("写入剪贴板失败: %v", err)
+	}
+	procRtlMoveMemory.Call(p, uintptr(unsafe.Pointer(&text[0])), size)
+	procGlobalUnlock.Call(h)
+	// 写入成功后内存由系统管理，失败时需要自行释放
+	if r, _, err := procSetClipboardData.Call(cfUnicodeText, h); r == 0 {
+		procGlobalFree.Call(h)
+		return i18n.Errorf("写入剪贴板失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Command cshared 将转换器编译为 C 动态库，Python、Rust、Node.js 等程序可以通过 FFI 在进程内调用：
+//
+//	go build -buildmode=c-shared -o libxmindtomarkdown.so ./cmd/cshared
+//
+// 同时生成的 libxmindtomarkdown.h 中声明了导出的函数，返回的字符串均由本库分配，
+// 调用方使用完后应传给 XMindToMarkdownFree 释放
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"unsafe"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+)
+
+// options 为 ConvertXMindToMarkdown 的 opts 参数，字段含义与同名的命令行参数相同，省略的字段使用默认值
+type options struct {
+	From         string            `json:"from"`
+	Format       string            `json:"format"`
+	HeadingStart int               `json:"headingStart"`
+	Notes        bool              `json:"notes"`
+	LeafStyle    string            `json:"leafStyle"`
+	MaxDepth     int               `json:"maxDepth"`
+	Markers      map[string]string `json:"markers"`
+}
+
+// renderOptions 将 options 转换为 render.Option
+func (o options) renderOptions() []render.Option {
+	var opts []render.Option
+	if o.From != "" {
+		opts = append(opts, render.WithInputFormat(o.From))
+	}
+	if o.Format != "" {
+		opts = append(opts, render.WithFormat(o.Format))
+	}
+	if o.HeadingStart > 0 {
+		opts = append(opts, render.WithHeadingStart(o.HeadingStart))
+	}
+	if o.LeafStyle != "" {
+		opts = append(opts, render.WithLeafStyle(o.LeafStyle))
+	}
+	if len(o.Markers) > 0 {
+		opts = append(opts, render.WithMarkers(o.Markers))
+	}
+	return append(opts, render.WithNotes(o.Notes), render.WithMaxDepth(o.MaxDepth))
+}
+
+// ConvertXMindToMarkdown 转换 data 指向的 size 个字节的文件内容，opts 为 JSON 格式的选项（可以为 NULL），
+// 如 {"format": "md", "headingStart": 2, "notes": true}
+//
+// 成功时返回输出内容并将其字节数写入 outSize（可以为 NULL），输出为 xmind 等二进制格式时可能包含 0 字节；
+// 失败时返回 NULL，并在 errOut 不为 NULL 时写入错误信息。返回值与 *errOut 都需要用 XMindToMarkdownFree 释放
+//
+//export ConvertXMindToMarkdown
+func ConvertXMindToMarkdown(data *C.char, size C.int, opts *C.char, outSize *C.int, errOut **C.char) *C.char {
+	out, err := convert(C.GoBytes(unsafe.Pointer(data), size), opts)
+	if err != nil {
+		if errOut != nil {
+			*errOut = C.CString(err.Error())
+		}
+		return nil
+	}
+	if outSize != nil {
+		*outSize = C.int(len(out))
+	}
+	// 末尾多分配一个 0 字节，使文本输出可以直接作为 C 字符串使用
+	p := C.malloc(C.size_t(len(out) + 1))
+	buf := (*[1 << 30]byte)(p)[: len(out)+1 : len(out)+1]
+	copy(buf, out)
+	buf[len(out)] = 0
+	return (*C.char)(p)
+}
+
+// XMindToMarkdownFree 释放 ConvertXMindToMarkdown 返回的内容或错误信息
+//
+//export XMindToMarkdownFree
+func XMindToMarkdownFree(p *C.char) {
+	C.free(unsafe.Pointer(p))
+}
+
+// convert 解析 JSON 格式的选项并转换 data
+func convert(data []byte, opts *C.char) ([]byte, error) {
+	var o options
+	if opts != nil {
+		if err := json.Unmarshal([]byte(C.GoString(opts)), &o); err != nil {
+			return nil, i18n.Errorf("解析选项失败: %v", err)
+		}
+	}
+	return render.ConvertBytes(data, o.renderOptions()...)
+}
+
+func main() {}
@@ -0,0 +1,103 @@
+//go:build js && wasm
+
+// Command wasm 将转换器编译为 WebAssembly，在浏览器或 Node.js 中以 JavaScript 调用：
+//
+//	GOOS=js GOARCH=wasm go build -o xmindtomarkdown.wasm ./cmd/wasm
+//
+// 加载后在全局对象上提供 xmindtomarkdown.convert(bytes, options)，bytes 为文件内容的 Uint8Array，
+// 返回的 Promise 在文本格式时得到字符串，在 xmind 等二进制格式时得到 Uint8Array，转换失败时以 Error 拒绝。
+// options 可以省略，支持的字段为 from、format、headingStart、notes、leafStyle、maxDepth 与 markers
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("convert", js.FuncOf(convert))
+	js.Global().Set("xmindtomarkdown", api)
+	// 保持运行，使 JavaScript 可以一直调用导出的函数
+	select {}
+}
+
+// convert 实现 xmindtomarkdown.convert(bytes, options)
+func convert(this js.Value, args []js.Value) interface{} {
+	promise := js.Global().Get("Promise")
+	return promise.New(js.FuncOf(func(this js.Value, cb []js.Value) interface{} {
+		resolve, reject := cb[0], cb[1]
+		out, binary, err := convertArgs(args)
+		if err != nil {
+			reject.Invoke(js.Global().Get("Error").New(err.Error()))
+			return nil
+		}
+		if binary {
+			arr := js.Global().Get("Uint8Array").New(len(out))
+			js.CopyBytesToJS(arr, out)
+			resolve.Invoke(arr)
+		} else {
+			resolve.Invoke(string(out))
+		}
+		return nil
+	}))
+}
+
+// convertArgs 检查参数并转换，返回输出内容与输出是否为二进制格式
+func convertArgs(args []js.Value) ([]byte, bool, error) {
+	if len(args) == 0 || args[0].Type() != js.TypeObject || args[0].Get("length").Type() != js.TypeNumber {
+		return nil, false, i18n.Errorf("convert 的第一个参数应为文件内容的 Uint8Array")
+	}
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+
+	opts, format := options(js.Undefined())
+	if len(args) > 1 {
+		opts, format = options(args[1])
+	}
+	out, err := render.ConvertBytes(data, opts...)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, format == "xmind", nil
+}
+
+// options 将 JavaScript 对象转换为 render.Option，同时返回输出格式
+func options(v js.Value) ([]render.Option, string) {
+	format := "md"
+	if v.Type() != js.TypeObject {
+		return nil, format
+	}
+	var opts []render.Option
+	if s := v.Get("from"); s.Type() == js.TypeString {
+		opts = append(opts, render.WithInputFormat(s.String()))
+	}
+	if s := v.Get("format"); s.Type() == js.TypeString {
+		format = s.String()
+		opts = append(opts, render.WithFormat(format))
+	}
+	if n := v.Get("headingStart"); n.Type() == js.TypeNumber {
+		opts = append(opts, render.WithHeadingStart(n.Int()))
+	}
+	if b := v.Get("notes"); b.Type() == js.TypeBoolean {
+		opts = append(opts, render.WithNotes(b.Bool()))
+	}
+	if s := v.Get("leafStyle"); s.Type() == js.TypeString {
+		opts = append(opts, render.WithLeafStyle(s.String()))
+	}
+	if n := v.Get("maxDepth"); n.Type() == js.TypeNumber {
+		opts = append(opts, render.WithMaxDepth(n.Int()))
+	}
+	if m := v.Get("markers"); m.Type() == js.TypeObject {
+		markers := map[string]string{}
+		keys := js.Global().Get("Object").Call("keys", m)
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			markers[k] = m.Get(k).String()
+		}
+		opts = append(opts, render.WithMarkers(markers))
+	}
+	return opts, format
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// command 为一个子命令
+type command struct {
+	// Name 为命令名称
+	Name string
+	// Args 为用法中参数部分的说明
+	Args string
+	// Short 为命令的简介
+	Short string
+	// Setup 在 fs 中定义命令的参数，返回以位置参数执行命令的函数，ctx 取消时命令应尽快结束
+	Setup func(fs *flag.FlagSet) func(ctx context.Context, args []string) error
+}
+
+// commands 为所有子命令，按帮助信息中的显示顺序排列
+var commands []*command
+
+func init() {
+	commands = []*command{convertCommand, watchCommand, pickCommand, infoCommand, sheetsCommand, treeCommand, statsCommand, diffCommand, mergeCommand, splitCommand, extractCommand, lintCommand, checkCommand, checkLinksCommand, duplicatesCommand, searchCommand, queryCommand, serveCommand, versionCommand, completionCommand, helpCommand}
+}
+
+// defaultCommand 为未指定命令时执行的命令，兼容 `xmindtomarkdown a.xmind` 写法
+var defaultCommand = convertCommand
+
+// lookupCommand 按名称查找子命令，找不到时返回 nil
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// run 根据第一个参数选择子命令并执行，所有错误都返回给 main 统一处理
+func run(ctx context.Context, args []string) error {
+	presetLanguage(args)
+	if len(args) > 0 {
+		switch args[0] {
+		case "-h", "-help", "--help":
+			printUsage(os.Stderr)
+			return flag.ErrHelp
+		case "-version", "--version":
+			printVersion(os.Stdout)
+			return nil
+		}
+	}
+	cmd := defaultCommand
+	if len(args) > 0 {
+		if c := lookupCommand(args[0]); c != nil {
+			cmd, args = c, args[1:]
+		} else if looksLikeCommand(args[0]) {
+			return withCode(exitUsage, i18n.Errorf("未知命令: %s，使用 \"xmindtomarkdown help\" 查看所有命令", args[0]))
+		}
+	}
+	return runCommand(ctx, cmd, args)
+}
+
+// presetLanguage 在解析参数之前按 -lang 或 XMIND2MD_LANG 选择语言，使参数错误与帮助信息同样使用该语言
+// 无效的取值在解析参数后报错
+func presetLanguage(args []string) {
+	lang := os.Getenv(envName("lang"))
+	for i, a := range args {
+		if a == "--" {
+			break
+		}
+		switch {
+		case a == "-lang" || a == "--lang":
+			if i+1 < len(args) {
+				lang = args[i+1]
+			}
+		case strings.HasPrefix(a, "-lang="), strings.HasPrefix(a, "--lang="):
+			lang = a[strings.Index(a, "=")+1:]
+		}
+	}
+	if lang != "" {
+		i18n.SetLanguage(lang)
+	}
+}
+
+// looksLikeCommand 判断参数是否像是拼写错误的命令名称，而不是要转换的文件
+func looksLikeCommand(arg string) bool {
+	if arg == "" || strings.ContainsAny(arg, `-./\:*?[`) {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return err != nil
+}
+
+// runCommand 解析命令的参数并执行
+func runCommand(ctx context.Context, cmd *command, args []string) error {
+	fs := newFlagSet(cmd)
+	exec := cmd.Setup(fs)
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		printCommandUsage(os.Stderr, cmd, fs)
+		return err
+	}
+	if err != nil {
+		// 解析失败时后面的参数没有生效，单独找出 -error-format 以便按要求的格式输出错误
+		for i, a := range args {
+			if a == "-error-format=json" || a == "--error-format=json" ||
+				((a == "-error-format" || a == "--error-format") && i+1 < len(args) && args[i+1] == "json") {
+				errorFormat = "json"
+			}
+		}
+		return withCode(exitUsage, err)
+	}
+	// 参数的优先级为：命令行 > 环境变量 > 配置文件
+	set := setFlags(fs)
+	if err := applyEnv(fs, set); err != nil {
+		return err
+	}
+	if err := loadConfig(fs, cmd, set); err != nil {
+		return withCode(exitUsage, err)
+	}
+	if language != "" {
+		if err := i18n.SetLanguage(language); err != nil {
+			return withCode(exitUsage, err)
+		}
+	}
+	if errorFormat != "text" && errorFormat != "json" {
+		format := errorFormat
+		errorFormat = "text"
+		return withCode(exitUsage, i18n.Errorf("不支持的错误信息格式: %s", format))
+	}
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	return exec(ctx, positional)
+}
+
+// newFlagSet 创建命令的参数集合，并定义所有命令共用的参数
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	// 参数错误由 main 统一输出，-h 时才打印用法
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	fs.BoolVar(&pauseOnExit, "pause", false, "在交互式终端中运行时，结束前等待按回车键退出")
+	fs.StringVar(&errorFormat, "error-format", "text", "错误信息的输出格式: text, json")
+	fs.StringVar(&logFormat, "log-format", "text", "诊断信息（-v 等）的输出格式: text, json（每行一个 JSON 对象，带有级别与字段）")
+	fs.StringVar(&configPath, "config", "", "配置文件路径，默认为 ~/.config/xmind2md/config.yaml")
+	fs.StringVar(&language, "lang", "", "提示与错误信息使用的语言（zh 或 en），默认根据系统的区域设置选择")
+	verbosity = levelNormal
+	fs.Var(&verbosityFlag{delta: 0}, "q", "只输出错误信息")
+	fs.Var(&verbosityFlag{delta: 1}, "v", "输出正在处理的文件与转换时丢失的内容，可重复指定")
+	fs.Var(&verbosityFlag{delta: 2}, "vv", "输出更详细的诊断信息，同 -v -v")
+	return fs
+}
+
+// commandFlags 返回命令定义的所有参数，用于查看参数定义，不会改变当前的参数取值
+func commandFlags(c *command) *flag.FlagSet {
+	pause, format, config, level, lang, logs := pauseOnExit, errorFormat, configPath, verbosity, language, logFormat
+	defer func() {
+		pauseOnExit, errorFormat, configPath, verbosity, language, logFormat = pause, format, config, level, lang, logs
+	}()
+	fs := newFlagSet(c)
+	c.Setup(fs)
+	return fs
+}
+
+// parseArgs 解析参数并返回所有位置参数，允许参数与文件交替出现，如 `a.xmind -o a.md`
+// 单独的 -- 之后的参数全部视为位置参数
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// printUsage 输出所有命令的简介
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, i18n.T("用法: xmindtomarkdown <命令> [参数]"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, i18n.T("命令:"))
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", c.Name, i18n.T(c.Short))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, i18n.T("未指定命令时执行 %s，例如 `xmindtomarkdown a.xmind`\n"), defaultCommand.Name)
+	fmt.Fprintln(w, i18n.T("使用 \"xmindtomarkdown help <命令>\" 查看命令的参数"))
+}
+
+// printCommandUsage 输出单个命令的用法与参数说明
+func printCommandUsage(w io.Writer, cmd *command, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) { f.Usage = i18n.T(f.Usage) })
+	fmt.Fprintf(w, i18n.T("用法: xmindtomarkdown %s %s\n\n"), cmd.Name, i18n.T(cmd.Args))
+	fmt.Fprintf(w, i18n.T("%s\n\n参数:\n"), i18n.T(cmd.Short))
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintf(w, i18n.T("\n参数也可以通过环境变量 %s<参数名> 设置，如 --out-dir 对应 %s\n"), envPrefix, envName("out-dir"))
+}
+
+var versionCommand = &command{
+	Name:  "version",
+	Args:  "",
+	Short: "显示版本信息与支持的格式",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		return func(ctx context.Context, args []string) error {
+			printVersion(os.Stdout)
+			return nil
+		}
+	},
+}
+
+var helpCommand = &command{
+	Name:  "help",
+	Args:  "[命令]",
+	Short: "显示命令的帮助信息",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				printUsage(os.Stdout)
+				return nil
+			}
+			cmd := lookupCommand(args[0])
+			if cmd == nil {
+				return withCode(exitUsage, i18n.Errorf("未知命令: %s", args[0]))
+			}
+			printCommandUsage(os.Stdout, cmd, commandFlags(cmd))
+			return nil
+		}
+	},
+}
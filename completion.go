@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// completionShells 为支持生成补全脚本的 shell
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+var completionCommand = &command{
+	Name:  "completion",
+	Args:  "<" + strings.Join(completionShells, "|") + ">",
+	Short: "生成 shell 补全脚本",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("必须指定 shell: %s", strings.Join(completionShells, ", ")))
+			}
+			return writeCompletion(os.Stdout, args[0])
+		}
+	},
+}
+
+// compFlag 为补全脚本中的一个参数
+type compFlag struct {
+	// Name 为参数的写法，单个字母的参数为 -x，其他为 --name
+	Name  string
+	Usage string
+	// TakesValue 表示参数需要取值
+	TakesValue bool
+	// Repeat 表示参数可以重复指定
+	Repeat bool
+	// Values 为参数可选的取值
+	Values []string
+	// Files 表示参数的取值为文件或目录
+	Files bool
+}
+
+// compCommand 为补全脚本中的一个命令
+type compCommand struct {
+	Name  string
+	Short string
+	Flags []compFlag
+	// Args 为位置参数可选的取值，为空时补全文件名
+	Args []string
+}
+
+// flagValues 返回参数可选的取值
+func flagValues(name string) []string {
+	switch name {
+	case "to", "format":
+		return render.OutputFormats()
+	case "from":
+		return append([]string{"auto"}, xmind.InputFormats()...)
+	case "error-format":
+		return []string{"text", "json"}
+	case "lang":
+		return i18n.Languages()
+	case "leaf-style":
+		return render.LeafStyles()
+	case "bullet":
+		return render.Bullets()
+	case "list-indent":
+		return render.ListIndents()
+	case "sort":
+		return render.Sorts()
+	case "escape":
+		return render.Escapes()
+	case "eol":
+		return render.EOLs()
+	}
+	return nil
+}
+
+// fileFlags 为取值是文件或目录的参数
+var fileFlags = map[string]bool{"f": true, "o": true, "output": true, "out-dir": true}
+
+// completionModel 根据命令列表与各命令定义的参数生成补全所需的信息
+func completionModel() []compCommand {
+	var names []string
+	for _, c := range commands {
+		names = append(names, c.Name)
+	}
+	var model []compCommand
+	for _, c := range commands {
+		fs := commandFlags(c)
+		cc := compCommand{Name: c.Name, Short: i18n.T(c.Short)}
+		fs.VisitAll(func(f *flag.Flag) {
+			name := "--" + f.Name
+			if len(f.Name) == 1 {
+				name = "-" + f.Name
+			}
+			bf, isBool := f.Value.(interface{ IsBoolFlag() bool })
+			_, repeat := f.Value.(*stringList)
+			if _, ok := f.Value.(*regexpList); ok {
+				repeat = true
+			}
+			cc.Flags = append(cc.Flags, compFlag{
+				Name:       name,
+				Usage:      i18n.T(f.Usage),
+				TakesValue: !(isBool && bf.IsBoolFlag()),
+				Repeat:     repeat,
+				Values:     flagValues(f.Name),
+				Files:      fileFlags[f.Name],
+			})
+		})
+		switch c.Name {
+		case "help":
+			cc.Args = names
+		case "completion":
+			cc.Args = completionShells
+		}
+		model = append(model, cc)
+	}
+	return model
+}
+
+// writeCompletion 输出指定 shell 的补全脚本
+func writeCompletion(w io.Writer, shell string) error {
+	model := completionModel()
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, model)
+	case "zsh":
+		writeZshCompletion(w, model)
+	case "fish":
+		writeFishCompletion(w, model)
+	case "powershell", "pwsh":
+		writePowerShellCompletion(w, model)
+	default:
+		return withCode(exitUsage, i18n.Errorf("不支持的 shell: %s，可选: %s", shell, strings.Join(completionShells, ", ")))
+	}
+	return nil
+}
+
+// valueFlags 返回所有有固定取值的参数及其取值，参数同时给出 -name 与 --name 两种写法
+func valueFlags(model []compCommand) ([]string, map[string][]string) {
+	values := map[string][]string{}
+	for _, c := range model {
+		for _, f := range c.Flags {
+			if len(f.Values) > 0 {
+				values[f.Name] = f.Values
+				values[strings.TrimPrefix(f.Name, "-")] = f.Values
+			}
+		}
+	}
+	var names []string
+	for n := range values {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, values
+}
+
+func commandNames(model []compCommand) string {
+	var names []string
+	for _, c := range model {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func flagNames(c compCommand) string {
+	var names []string
+	for _, f := range c.Flags {
+		names = append(names, f.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+func writeBashCompletion(w io.Writer, model []compCommand) {
+	names, values := valueFlags(model)
+	fmt.Fprintln(w, i18n.T("# xmindtomarkdown 的 bash 补全脚本"))
+	fmt.Fprintln(w, "_xmindtomarkdown() {")
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintf(w, "    local cmd=%s i\n", defaultCommand.Name)
+	fmt.Fprintln(w, "    for ((i = 1; i < COMP_CWORD; i++)); do")
+	fmt.Fprintf(w, "        case \"${COMP_WORDS[i]}\" in\n            %s) cmd=\"${COMP_WORDS[i]}\"; break ;;\n        esac\n", strings.Join(strings.Fields(commandNames(model)), "|"))
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w, `    case "$prev" in`)
+	for _, n := range names {
+		if strings.HasPrefix(n, "--") {
+			fmt.Fprintf(w, "        %s|%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", n, n[1:], strings.Join(values[n], " "))
+		}
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "    local flags args")
+	fmt.Fprintln(w, `    case "$cmd" in`)
+	for _, c := range model {
+		fmt.Fprintf(w, "        %s) flags=\"%s\" args=\"%s\" ;;\n", c.Name, flagNames(c), strings.Join(c.Args, " "))
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, `    if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -W "$flags" -- "$cur"))`)
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    if [[ -n "$args" ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -W "$args" -- "$cur"))`)
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    COMPREPLY=()")
+	fmt.Fprintln(w, `    if ((COMP_CWORD == 1)); then`)
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", commandNames(model))
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    COMPREPLY+=($(compgen -f -- "$cur"))`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o filenames -F _xmindtomarkdown xmindtomarkdown")
+}
+
+// zshEscape 转义 zsh _arguments 说明中的特殊字符
+func zshEscape(s string) string {
+	return strings.NewReplacer(`'`, `'\''`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+func writeZshCompletion(w io.Writer, model []compCommand) {
+	fmt.Fprintln(w, "#compdef xmindtomarkdown")
+	fmt.Fprintln(w, i18n.T("# xmindtomarkdown 的 zsh 补全脚本"))
+	fmt.Fprintln(w, "_xmindtomarkdown() {")
+	fmt.Fprintln(w, "    local -a commands")
+	fmt.Fprintln(w, "    commands=(")
+	for _, c := range model {
+		fmt.Fprintf(w, "        '%s:%s'\n", c.Name, zshEscape(c.Short))
+	}
+	fmt.Fprintln(w, "    )")
+	fmt.Fprintf(w, "    local cmd=%s i\n", defaultCommand.Name)
+	fmt.Fprintln(w, "    for ((i = 2; i < CURRENT; i++)); do")
+	fmt.Fprintf(w, "        case ${words[i]} in\n            %s) cmd=${words[i]}; break ;;\n        esac\n", strings.Join(strings.Fields(commandNames(model)), "|"))
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w, "    if ((CURRENT == 2)); then")
+	fmt.Fprintf(w, "        _describe -t commands %s commands\n", i18n.T("命令"))
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    case $cmd in")
+	for _, c := range model {
+		fmt.Fprintf(w, "        %s)\n            _arguments -s", c.Name)
+		for _, f := range c.Flags {
+			spec := ""
+			if f.Repeat {
+				spec = "*"
+			}
+			spec += f.Name + "[" + zshEscape(f.Usage) + "]"
+			if f.TakesValue {
+				switch {
+				case len(f.Values) > 0:
+					spec += ":" + i18n.T("值") + ":(" + strings.Join(f.Values, " ") + ")"
+				case f.Files:
+					spec += ":" + i18n.T("文件") + ":_files"
+				default:
+					spec += ":" + i18n.T("值") + ": "
+				}
+			}
+			fmt.Fprintf(w, " \\\n                '%s'", spec)
+		}
+		if len(c.Args) > 0 {
+			fmt.Fprintf(w, " \\\n                '*:%s:(%s)'", i18n.T("参数"), strings.Join(c.Args, " "))
+		} else {
+			fmt.Fprintf(w, " \\\n                '*:%s:_files'", i18n.T("文件"))
+		}
+		fmt.Fprintln(w, "\n            ;;")
+	}
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, `if [[ "$funcstack[1]" == "_xmindtomarkdown" ]]; then`)
+	fmt.Fprintln(w, `    _xmindtomarkdown "$@"`)
+	fmt.Fprintln(w, "else")
+	fmt.Fprintln(w, "    compdef _xmindtomarkdown xmindtomarkdown")
+	fmt.Fprintln(w, "fi")
+}
+
+// fishEscape 转义 fish 单引号字符串中的特殊字符
+func fishEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+func writeFishCompletion(w io.Writer, model []compCommand) {
+	fmt.Fprintln(w, i18n.T("# xmindtomarkdown 的 fish 补全脚本"))
+	fmt.Fprintln(w, "function __fish_xmindtomarkdown_command")
+	fmt.Fprintln(w, "    for t in (commandline -opc)[2..-1]")
+	fmt.Fprintf(w, "        switch $t\n            case %s\n                echo $t\n                return\n        end\n", commandNames(model))
+	fmt.Fprintln(w, "    end")
+	fmt.Fprintf(w, "    echo %s\n", defaultCommand.Name)
+	fmt.Fprintln(w, "end")
+	fmt.Fprintln(w)
+	for _, c := range model {
+		fmt.Fprintf(w, "complete -c xmindtomarkdown -n '__fish_use_subcommand' -a %s -d '%s'\n", c.Name, fishEscape(c.Short))
+	}
+	for _, c := range model {
+		cond := fmt.Sprintf("test (__fish_xmindtomarkdown_command) = %s", c.Name)
+		for _, f := range c.Flags {
+			opt := "-l " + strings.TrimPrefix(f.Name, "--")
+			if !strings.HasPrefix(f.Name, "--") {
+				opt = "-s " + strings.TrimPrefix(f.Name, "-")
+			}
+			if f.TakesValue {
+				opt += " -r"
+			}
+			switch {
+			case len(f.Values) > 0:
+				opt += fmt.Sprintf(" -f -a '%s'", strings.Join(f.Values, " "))
+			case f.TakesValue && !f.Files:
+				opt += " -f"
+			}
+			fmt.Fprintf(w, "complete -c xmindtomarkdown -n '%s' %s -d '%s'\n", cond, opt, fishEscape(f.Usage))
+		}
+		if len(c.Args) > 0 {
+			fmt.Fprintf(w, "complete -c xmindtomarkdown -n '%s' -f -a '%s'\n", cond, strings.Join(c.Args, " "))
+		}
+	}
+}
+
+// psQuote 生成 PowerShell 单引号字符串
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func psList(items []string) string {
+	var quoted []string
+	for _, s := range items {
+		quoted = append(quoted, psQuote(s))
+	}
+	return "@(" + strings.Join(quoted, ", ") + ")"
+}
+
+func writePowerShellCompletion(w io.Writer, model []compCommand) {
+	names, values := valueFlags(model)
+	fmt.Fprintln(w, i18n.T("# xmindtomarkdown 的 PowerShell 补全脚本"))
+	fmt.Fprintln(w, "Register-ArgumentCompleter -Native -CommandName xmindtomarkdown, xmindtomarkdown.exe -ScriptBlock {")
+	fmt.Fprintln(w, "    param($wordToComplete, $commandAst, $cursorPosition)")
+	fmt.Fprintf(w, "    $commands = %s\n", psList(strings.Fields(commandNames(model))))
+	fmt.Fprintln(w, "    $flags = @{")
+	for _, c := range model {
+		fmt.Fprintf(w, "        %s = %s\n", psQuote(c.Name), psList(strings.Fields(flagNames(c))))
+	}
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $positional = @{")
+	for _, c := range model {
+		if len(c.Args) > 0 {
+			fmt.Fprintf(w, "        %s = %s\n", psQuote(c.Name), psList(c.Args))
+		}
+	}
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $values = @{")
+	for _, n := range names {
+		fmt.Fprintf(w, "        %s = %s\n", psQuote(n), psList(values[n]))
+	}
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -le $cursorPosition } | ForEach-Object { $_.ToString() })")
+	fmt.Fprintln(w, "    if ($wordToComplete -ne '' -and $words.Count -gt 0) { $words = @($words | Select-Object -SkipLast 1) }")
+	fmt.Fprintf(w, "    $cmd = %s\n", psQuote(defaultCommand.Name))
+	fmt.Fprintln(w, "    foreach ($word in ($words | Select-Object -Skip 1)) { if ($commands -contains $word) { $cmd = $word; break } }")
+	fmt.Fprintln(w, "    $prev = if ($words.Count -gt 1) { $words[-1] } else { '' }")
+	fmt.Fprintln(w, "    if ($values.ContainsKey($prev)) {")
+	fmt.Fprintln(w, "        $candidates = $values[$prev]")
+	fmt.Fprintln(w, "    } elseif ($wordToComplete.StartsWith('-')) {")
+	fmt.Fprintln(w, "        $candidates = $flags[$cmd]")
+	fmt.Fprintln(w, "    } elseif ($positional.ContainsKey($cmd)) {")
+	fmt.Fprintln(w, "        $candidates = $positional[$cmd]")
+	fmt.Fprintln(w, "    } elseif ($words.Count -le 1) {")
+	fmt.Fprintln(w, "        $candidates = $commands")
+	fmt.Fprintln(w, "    } else {")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {")
+	fmt.Fprintln(w, "        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)")
+	fmt.Fprintln(w, "    }")
+	fmt.Fprintln(w, "}")
+}
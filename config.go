@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// configPath 对应 -config 参数，为空时使用默认位置的配置文件（不存在时忽略）
+var configPath string
+
+// defaultConfigPath 返回默认的配置文件路径 ~/.config/xmind2md/config.yaml
+// 设置了 XDG_CONFIG_HOME 时使用其中的 xmind2md/config.yaml，Windows 上位于 %AppData%\xmind2md
+func defaultConfigPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" && runtime.GOOS == "windows" {
+		dir, _ = os.UserConfigDir()
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "xmind2md", "config.yaml")
+}
+
+// loadConfig 读取配置文件，将其中的设置作为没有通过命令行或环境变量指定的参数的默认值
+// set 为已经指定的参数（见 setFlags）
+// 顶层的设置项对所有命令生效，与命令同名的设置项只对该命令生效，并覆盖顶层的同名设置
+func loadConfig(fs *flag.FlagSet, cmd *command, set map[string]bool) error {
+	path, explicit := configPath, configPath != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return i18n.Errorf("读取配置文件失败: %v", err)
+	}
+	cfg, err := parseYAML(data)
+	if err != nil {
+		return i18n.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+
+	var section []yamlEntry
+	for _, e := range cfg {
+		if c := lookupCommand(e.Key); c != nil {
+			if c != cmd {
+				continue
+			}
+			m, ok := e.Value.([]yamlEntry)
+			if !ok {
+				return i18n.Errorf("配置文件 %s 中的 %s 应为参数的映射", path, e.Key)
+			}
+			section = m
+			continue
+		}
+		if err := applyConfigEntry(fs, set, e, ""); err != nil {
+			return i18n.Errorf("配置文件 %s: %v", path, err)
+		}
+	}
+	for _, e := range section {
+		if err := applyConfigEntry(fs, set, e, cmd.Name+"."); err != nil {
+			return i18n.Errorf("配置文件 %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// setFlags 返回命令行中已经指定的参数，以参数的取值位置区分，使 -to 与 -format 这类别名视为同一个参数
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[fmt.Sprintf("%p", f.Value)] = true
+	})
+	return set
+}
+
+// flagConflicts 为互斥的参数，命令行指定了其中之一时忽略环境变量与配置文件中的另一个
+var flagConflicts = map[string][]string{
+	"out-dir": {"o"},
+}
+
+// conflictSet 判断与 name 互斥的参数是否已经指定
+func conflictSet(fs *flag.FlagSet, set map[string]bool, name string) bool {
+	for _, other := range flagConflicts[name] {
+		if f := fs.Lookup(other); f != nil && set[fmt.Sprintf("%p", f.Value)] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConfigEntry 将一项配置设置到对应的参数上，命令行已经指定的参数保持不变
+// prefix 为命令的设置项前缀（如 "convert."），为空表示顶层设置项，其他命令的参数不会报错
+func applyConfigEntry(fs *flag.FlagSet, set map[string]bool, e yamlEntry, prefix string) error {
+	f := fs.Lookup(e.Key)
+	if f == nil {
+		if prefix == "" && isCommandFlag(e.Key) {
+			return nil
+		}
+		return i18n.Errorf("未知的设置项 %s%s", prefix, e.Key)
+	}
+	if set[fmt.Sprintf("%p", f.Value)] || conflictSet(fs, set, e.Key) {
+		return nil
+	}
+	var values []string
+	switch v := e.Value.(type) {
+	case string:
+		values = []string{v}
+	case []string:
+		values = v
+	case []yamlEntry:
+		for _, kv := range v {
+			s, ok := kv.Value.(string)
+			if !ok {
+				return i18n.Errorf("%s%s.%s 应为单个值", prefix, e.Key, kv.Key)
+			}
+			values = append(values, kv.Key+"="+s)
+		}
+	}
+	for _, v := range values {
+		if err := fs.Set(e.Key, v); err != nil {
+			return i18n.Errorf("%s%s 的值 %q 无效: %v", prefix, e.Key, v, err)
+		}
+	}
+	return nil
+}
+
+// isCommandFlag 判断 name 是否为某个命令的参数
+func isCommandFlag(name string) bool {
+	for _, c := range commands {
+		if commandFlags(c).Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlEntry 为配置文件中映射的一项，按文件中的顺序保存
+// Value 为 string、[]string 或嵌套的 []yamlEntry
+type yamlEntry struct {
+	Key   string
+	Value interface{}
+}
+
+// yamlLine 为去除注释后的一行配置
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// parseYAML 解析配置文件使用的 YAML 子集：以缩进表示层级的映射、"- " 开头的列表、
+// [a, b] 形式的行内列表、带引号或不带引号的字符串以及 # 注释
+func parseYAML(data []byte) ([]yamlEntry, error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	for n := 1; scanner.Scan(); n++ {
+		raw := stripYAMLComment(scanner.Text())
+		text := strings.TrimLeft(raw, " ")
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, i18n.Errorf("第 %d 行: 不能使用 Tab 缩进", n)
+		}
+		lines = append(lines, yamlLine{num: n, indent: len(raw) - len(text), text: strings.TrimRight(text, " \t\r")})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	p := &yamlParser{lines: lines}
+	v, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, i18n.Errorf("第 %d 行: 缩进错误", lines[p.pos].num)
+	}
+	m, ok := v.([]yamlEntry)
+	if !ok {
+		return nil, i18n.Errorf("第 %d 行: 顶层应为映射", lines[0].num)
+	}
+	return m, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block 解析从当前行开始、缩进为 indent 的映射或列表
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isYAMLListItem(p.lines[p.pos].text) {
+		return p.list(indent)
+	}
+	var m []yamlEntry
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if isYAMLListItem(line.text) {
+			return nil, i18n.Errorf("第 %d 行: 映射中不能出现列表项", line.num)
+		}
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, i18n.Errorf("第 %d 行: 应为 key: value 形式", line.num)
+		}
+		p.pos++
+		entry := yamlEntry{Key: key}
+		switch {
+		case rest != "":
+			v, err := yamlScalar(rest)
+			if err != nil {
+				return nil, i18n.Errorf("第 %d 行: %v", line.num, err)
+			}
+			entry.Value = v
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			v, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			entry.Value = v
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLListItem(p.lines[p.pos].text):
+			// 列表项可以与键对齐
+			v, err := p.list(indent)
+			if err != nil {
+				return nil, err
+			}
+			entry.Value = v
+		default:
+			entry.Value = ""
+		}
+		m = append(m, entry)
+	}
+	return m, nil
+}
+
+// list 解析缩进为 indent 的列表，列表项只能是字符串
+func (p *yamlParser) list(indent int) (interface{}, error) {
+	var items []string
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLListItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		v, err := yamlScalar(strings.TrimSpace(strings.TrimPrefix(line.text, "-")))
+		if err != nil {
+			return nil, i18n.Errorf("第 %d 行: %v", line.num, err)
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, i18n.Errorf("第 %d 行: 列表项只能是字符串", line.num)
+		}
+		items = append(items, s)
+		p.pos++
+	}
+	return items, nil
+}
+
+func isYAMLListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey 将 "key: value" 拆分为键与值，键可以带引号
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 || !strings.HasPrefix(text[end+2:], ":") {
+			return "", "", false
+		}
+		return text[1 : end+1], strings.TrimSpace(text[end+3:]), true
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", false
+		}
+		i = len(text) - 1
+	}
+	return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+}
+
+// yamlScalar 解析单个值，支持带引号的字符串与 [a, b] 形式的行内列表
+func yamlScalar(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, i18n.Errorf("无效的字符串 %s", s)
+		}
+		return v, nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return nil, i18n.Errorf("无效的字符串 %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, i18n.Errorf("无效的列表 %s", s)
+		}
+		var items []string
+		for _, item := range strings.Split(s[1:len(s)-1], ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			v, err := yamlScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			str, ok := v.(string)
+			if !ok {
+				return nil, i18n.Errorf("列表项只能是字符串")
+			}
+			items = append(items, str)
+		}
+		return items, nil
+	}
+	return s, nil
+}
+
+// stripYAMLComment 去除行尾注释，引号中的 # 不视为注释
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '[' || line[i-1] == ',' || line[i-1] == '-' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
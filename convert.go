@@ -0,0 +1,578 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/batch"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var convertCommand = &command{
+	Name:  "convert",
+	Args:  "[参数] [文件...]",
+	Short: "将思维导图转换为 Markdown 等格式（默认命令）",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		// 使用 flag 定义 -f 参数，但如果没有提供，则在交互式终端中提示用户输入
+		var files stringList
+		var opts convertOptions
+		var filter fileFilter
+		fs.Var(&files, "f", "指定要转换的思维导图文件、目录、http(s) 地址、s3://bucket/key 或 gdrive://<文件ID>，可重复指定多个，- 表示从标准输入读取")
+		defineOutputFlags(fs, &opts, &filter)
+		fs.StringVar(&opts.Output, "o", "", "指定输出文件路径（本地路径或 s3://bucket/key），- 表示输出到标准输出，默认与输入文件同名")
+		fs.StringVar(&opts.Output, "output", "", "同 -o")
+		fs.StringVar(&opts.SheetRegexText, "sheet-regex", "", "只转换标题（没有标题时为根节点的标题）与正则表达式匹配的画布，每个画布写入单独的文件，文件名按 -name-template 生成，默认为 \"{{.Base}}-{{.Sheet}}{{.Ext}}\"")
+		fs.DurationVar(&opts.Fetch.Timeout, "timeout", 30*time.Second, "下载远程文件的超时时间")
+		fs.Int64Var(&opts.Fetch.MaxSize, "max-size", 100<<20, "允许下载的最大字节数")
+		fs.StringVar(&opts.Fetch.AuthHeader, "auth-header", "", "下载远程文件时附加的认证请求头，如 \"Authorization: Bearer xxx\"")
+		fs.StringVar(&opts.Fetch.DriveToken, "drive-token", "", "访问 Google Drive（gdrive://<文件ID> 或 Drive 分享链接）使用的 OAuth 访问令牌")
+		fs.StringVar(&opts.Report, "report", "", "转换结束后输出转换报告，格式: json")
+		fs.StringVar(&opts.ReportFile, "report-file", "", "将 JSON 格式的转换报告写入指定文件")
+		fs.StringVar(&opts.Bundle, "bundle", "", "将转换得到的所有文件连同 .xmind 文件中的图片、附件等资源打包写入指定的 zip 文件（本地路径或 s3://bucket/key）")
+		fs.StringVar(&opts.Manifest, "manifest", "", "转换结束后将每个输出文件的路径、SHA-256 与对应的输入写入指定的 JSON 清单文件（-dry-run 时不写入）")
+		fs.BoolVar(&opts.Index, "index", false, "批量转换后在输出目录（未指定 -out-dir 时为所有输出文件共同的目录）生成 README.md，列出并链接每个文档及其标题与节点数")
+		fs.BoolVar(&opts.NoProgress, "no-progress", false, "不在终端中显示转换进度")
+		fs.BoolVar(&opts.DryRun, "dry-run", false, "只解析输入并列出将要新建或覆盖的文件，不写入任何内容")
+		fs.BoolVar(&opts.Clipboard, "clipboard", false, "将转换结果复制到系统剪贴板，同时指定 -o 或 -out-dir 时才写入文件")
+		fs.BoolVar(&opts.Open, "open", false, "转换结束后在文件管理器中打开输出文件夹")
+		fs.BoolVar(&opts.Merge, "merge", false, "将所有输入合并写入 -o 指定的一个文件，Markdown 中每个文件成为一个小节并在开头生成目录")
+		fs.BoolVar(&opts.Preview, "preview", false, "在终端中渲染转换得到的 Markdown，同时指定 -o 或 -out-dir 时才写入文件")
+
+		// 位置参数同样作为输入文件，例如 `xmindtomarkdown a.xmind b.xmind` 或将多个文件拖放到程序上
+		return func(ctx context.Context, args []string) error {
+			files = append(files, args...)
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+			return convertInputs(ctx, files, filter, opts)
+		}
+	},
+}
+
+// defineOutputFlags 定义 convert 与 watch 共用的参数，包括输入输出格式、输出位置与覆盖方式
+func defineOutputFlags(fs *flag.FlagSet, opts *convertOptions, filter *fileFilter) {
+	fs.Var(&filter.Include, "include-files", "转换目录时只转换与模式匹配的文件（如 \"**/*.xmind\"），可重复指定")
+	fs.Var(&filter.Exclude, "exclude-files", "转换目录时跳过与模式匹配的文件或目录（如 \"archive/**\"），可重复指定")
+	fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+	fs.StringVar(&opts.To, "to", "md", "输出格式: "+strings.Join(render.OutputFormats(), ", "))
+	fs.StringVar(&opts.To, "format", "md", "同 -to")
+	fs.StringVar(&opts.OutDir, "out-dir", "", "指定输出目录（本地目录或 s3://bucket/prefix），转换目录时会在其中重建相对目录结构")
+	fs.StringVar(&opts.Template, "template", "", "使用 Go text/template 模板文件自定义输出内容，输出文件的扩展名取自模板文件名，如 report.html.tmpl 输出 .html")
+	fs.StringVar(&opts.NameTemplate, "name-template", "", "输出文件名模板，如 \"{{.Base}}-{{.Sheet}}-{{.Date}}{{.Ext}}\"，可用字段: Base, Sheet, Date, Ext, Slug")
+	opts.Write.Markers = map[string]string{}
+	fs.Var(stringMap(opts.Write.Markers), "marker", "将图标输出为标题前的文本，格式为 图标ID=文本（如 priority-1=🔴），可重复指定")
+	fs.BoolVar(&opts.Write.Notes, "notes", false, "在 Markdown 中将节点备注输出为节点下的引用块")
+	fs.Var((*stringList)(&opts.Transforms), "transform", "在输出前用脚本改写节点（重命名、删除、添加标签或移动），脚本从标准输入读取中间格式并输出改写后的中间格式，可重复指定")
+	fs.StringVar(&opts.Root, "root", "", "只转换标题为该值的第一个节点及其子树，并将其作为根节点；包含 / 时为从根节点的子节点开始的标题路径，如 \"第二章/2.1\"")
+	fs.StringVar(&opts.RootID, "root-id", "", "只转换 ID 为该值的节点及其子树，并将其作为根节点")
+	fs.BoolVar(&opts.Write.PruneEmpty, "prune-empty", false, "去掉标题为空的节点，子树全部为空时一并去掉，否则子节点提升一级")
+	fs.Var((*regexpList)(&opts.Write.Include), "include", "只输出标题与正则表达式匹配的节点及其子节点（上级节点同样保留），可重复指定")
+	fs.Var((*regexpList)(&opts.Write.Exclude), "exclude", "不输出标题与正则表达式匹配的节点及其子节点（如 \"^(内部|Draft)$\"），可重复指定")
+	fs.Var((*stringList)(&opts.Write.FilterMarkers), "filter-marker", "只输出带有该图标的节点及其子节点（如 flag-red，flag 匹配所有旗帜），可重复指定")
+	fs.Var((*stringList)(&opts.Write.FilterLabels), "filter-label", "只输出带有该标签的节点及其子节点，可重复指定")
+	fs.StringVar(&opts.Write.Sort, "sort", "none", "同一节点下子节点的排列方式: "+strings.Join(render.Sorts(), ", ")+"（none 保持原来的顺序）")
+	fs.BoolVar(&opts.Write.LeavesOnly, "leaves-only", false, "只输出叶子节点，每个画布输出为根节点下的平铺清单（Markdown 中 -leaf-style heading 按 bullet 输出）")
+	fs.BoolVar(&opts.Write.GroupLeaves, "group-leaves", false, "与 -leaves-only 一起使用，按第一层分支将叶子节点分组")
+	fs.IntVar(&opts.Write.MaxDepth, "max-depth", 0, "最多输出的层数，根节点为第 1 层，0 表示不限制")
+	fs.BoolVar(&opts.Write.MergeDuplicates, "merge-duplicates", false, "合并同一节点下标题相同（忽略大小写与多余的空白）的子节点，后面节点的子节点移到第一个节点下")
+	fs.BoolVar(&opts.Write.Numbering, "numbering", false, "在根节点以外的每个节点标题前加上层级编号（1、1.1、1.1.2），与 XMind 中的编号设置无关")
+	fs.BoolVar(&opts.StatsFooter, "stats-footer", false, "在输出末尾写入节点数、字数、最大层数以及来源文件与转换时间")
+	fs.BoolVar(&opts.Deterministic, "deterministic", false, "生成适合纳入 Git 的稳定输出：去掉行尾空白，合并连续的空行，-stats-footer 不写入转换时间（设置了 SOURCE_DATE_EPOCH 时使用其时间）")
+	fs.BoolVar(&opts.Write.DepthNote, "depth-note", false, "在因 -max-depth 被截断的节点下输出“…（还有 n 层）”")
+	fs.BoolVar(&opts.Write.TOC, "toc", false, "在 Markdown 输出开头生成链接到各级标题的目录")
+	fs.IntVar(&opts.Write.TOCDepth, "toc-depth", 3, "-toc 生成的目录列出的层数，根节点为第 1 层")
+	fs.IntVar(&opts.Write.HeadingStart, "heading-start", 1, "Markdown 中根节点的标题级别（1-6），子节点依次递增，如 2 表示根节点为 h2、子节点从 h3 开始")
+	fs.StringVar(&opts.Write.LeafStyle, "leaf-style", "heading", "Markdown 中叶子节点的输出方式: "+strings.Join(render.LeafStyles(), ", "))
+	fs.StringVar(&opts.Write.Bullet, "bullet", "-", "Markdown 列表项的标记: "+strings.Join(render.Bullets(), ", "))
+	fs.StringVar(&opts.Write.ListIndent, "list-indent", "2", "Markdown 列表的缩进: "+strings.Join(render.ListIndents(), ", ")+"（2 或 4 个空格、Tab）")
+	fs.StringVar(&opts.Write.Escape, "escape", "off", "Markdown 中节点标题特殊字符的转义方式: "+strings.Join(render.Escapes(), ", ")+"（smart 只转义会改变渲染结果的字符）")
+	fs.StringVar(&opts.Write.EOL, "eol", "lf", "文本格式输出使用的换行符: "+strings.Join(render.EOLs(), ", "))
+	fs.BoolVar(&opts.Write.BOM, "bom", false, "在文本格式输出的开头写入 UTF-8 BOM")
+	fs.IntVar(&opts.ChunkLevel, "chunk-level", 0, "将 Markdown 输出按该层级的节点拆分为多个文件（根节点为第 1 层），文件之间带有上一部分与下一部分的链接")
+	fs.Int64Var(&opts.MaxFileSize, "max-file-size", 0, "拆分 Markdown 输出时每个文件尽量不超过的字节数，单独指定时按第 2 层拆分")
+	fs.BoolVar(&opts.Force, "force", false, "覆盖已存在的输出文件")
+	fs.BoolVar(&opts.Backup, "backup", false, "覆盖已存在的输出文件前保留一份带时间戳的备份")
+	fs.BoolVar(&opts.ForceRebuild, "force-rebuild", false, "即使输入没有变化也重新转换")
+	fs.IntVar(&opts.Jobs, "jobs", 1, "批量转换时同时转换的文件数，0 表示与 CPU 核数相同")
+	fs.IntVar(&opts.Retries, "retries", batch.DefaultRetries, "读取输入或写入输出时遇到临时性错误（如网络超时、文件被其他程序锁定）后最多重试的次数，0 表示不重试")
+	fs.DurationVar(&opts.RetryBackoff, "retry-backoff", batch.DefaultBackoff, "第一次重试前等待的时间，之后每次加倍")
+	defineLimitFlags(fs, &opts.Limits)
+}
+
+// prepareOptions 检查输出格式并解析输出模板与文件名模板
+func prepareOptions(opts *convertOptions) error {
+	opts.Write.Normalize = opts.Deterministic
+	if err := applyLimits(opts.Limits); err != nil {
+		return err
+	}
+	if err := prepareTemplate(opts); err != nil {
+		return err
+	}
+	if err := prepareTransforms(opts); err != nil {
+		return err
+	}
+	if err := checkFormat(opts.To); err != nil {
+		return withCode(exitUsage, err)
+	}
+	if opts.Root != "" && opts.RootID != "" {
+		return withCode(exitUsage, i18n.Errorf("-root 与 -root-id 不能同时指定"))
+	}
+	if opts.Write.MaxDepth < 0 {
+		return withCode(exitUsage, i18n.Errorf("-max-depth 不能为负数"))
+	}
+	if opts.Write.HeadingStart < 1 || opts.Write.HeadingStart > 6 {
+		return withCode(exitUsage, i18n.Errorf("-heading-start 应为 1 到 6 之间的整数"))
+	}
+	if !oneOf(opts.Write.LeafStyle, render.LeafStyles()) {
+		return withCode(exitUsage, i18n.Errorf("不支持的叶子节点输出方式: %s，可选: %s", opts.Write.LeafStyle, strings.Join(render.LeafStyles(), ", ")))
+	}
+	if opts.Write.GroupLeaves && !opts.Write.LeavesOnly {
+		return withCode(exitUsage, i18n.Errorf("-group-leaves 需要与 -leaves-only 一起使用"))
+	}
+	// 平铺的叶子节点作为清单输出，而不是一串同级的标题
+	if opts.Write.LeavesOnly && opts.Write.LeafStyle == "heading" {
+		opts.Write.LeafStyle = "bullet"
+	}
+	if !oneOf(opts.Write.Bullet, render.Bullets()) {
+		return withCode(exitUsage, i18n.Errorf("不支持的列表项标记: %s，可选: %s", opts.Write.Bullet, strings.Join(render.Bullets(), ", ")))
+	}
+	if !oneOf(opts.Write.ListIndent, render.ListIndents()) {
+		return withCode(exitUsage, i18n.Errorf("不支持的列表缩进: %s，可选: %s", opts.Write.ListIndent, strings.Join(render.ListIndents(), ", ")))
+	}
+	if !oneOf(opts.Write.Sort, render.Sorts()) {
+		return withCode(exitUsage, i18n.Errorf("不支持的排列方式: %s，可选: %s", opts.Write.Sort, strings.Join(render.Sorts(), ", ")))
+	}
+	if !oneOf(opts.Write.Escape, render.Escapes()) {
+		return withCode(exitUsage, i18n.Errorf("不支持的转义方式: %s，可选: %s", opts.Write.Escape, strings.Join(render.Escapes(), ", ")))
+	}
+	if !oneOf(opts.Write.EOL, render.EOLs()) {
+		return withCode(exitUsage, i18n.Errorf("不支持的换行符: %s，可选: %s", opts.Write.EOL, strings.Join(render.EOLs(), ", ")))
+	}
+	if opts.Jobs < 0 || opts.Retries < 0 || opts.RetryBackoff < 0 {
+		return withCode(exitUsage, i18n.Errorf("-jobs、-retries 与 -retry-backoff 不能为负数"))
+	}
+	if opts.ChunkLevel < 0 || opts.MaxFileSize < 0 {
+		return withCode(exitUsage, i18n.Errorf("-chunk-level 与 -max-file-size 不能为负数"))
+	}
+	if opts.Write.TOCDepth < 1 {
+		return withCode(exitUsage, i18n.Errorf("-toc-depth 至少为 1"))
+	}
+	if opts.ChunkLevel == 1 {
+		return withCode(exitUsage, i18n.Errorf("-chunk-level 至少为 2，根节点所在的第 1 层不能拆分"))
+	}
+	if opts.SheetRegexText != "" {
+		re, err := regexp.Compile(opts.SheetRegexText)
+		if err != nil {
+			return withCode(exitUsage, i18n.Errorf("无效的正则表达式 %q: %v", opts.SheetRegexText, err))
+		}
+		if opts.Output != "" || opts.Merge || opts.Clipboard {
+			return withCode(exitUsage, i18n.Errorf("-sheet-regex 将每个画布写入单独的文件，不能与 -o、-merge 或 -clipboard 同时使用"))
+		}
+		opts.SheetRegex = re
+		// 默认的文件名按画布区分
+		if opts.NameTemplate == "" {
+			opts.NameTemplate = "{{.Base}}-{{.Sheet}}{{.Ext}}"
+		}
+	}
+	if opts.NameTemplate != "" {
+		t, err := parseNameTemplate(opts.NameTemplate)
+		if err != nil {
+			return err
+		}
+		opts.Name = t
+	}
+	return nil
+}
+
+// prepareTemplate 读取并解析 -template 指定的模板，指定模板时输出格式为 template
+func prepareTemplate(opts *convertOptions) error {
+	if opts.Template == "" {
+		if opts.To == "template" {
+			return withCode(exitUsage, i18n.Errorf("-to template 需要同时用 -template 指定模板文件"))
+		}
+		return nil
+	}
+	if opts.To != "md" && opts.To != "template" {
+		return withCode(exitUsage, i18n.Errorf("-template 不能与 -to %s 同时使用", opts.To))
+	}
+	data, err := os.ReadFile(opts.Template)
+	if err != nil {
+		return withCode(exitUsage, i18n.Errorf("读取模板失败: %v", err))
+	}
+	t, err := render.ParseTemplate(filepath.Base(opts.Template), string(data))
+	if err == nil {
+		// 提前用空数据执行一次，使拼错的字段名在转换开始前报错
+		err = render.WriteTemplate(io.Discard, nil, t)
+	}
+	if err != nil {
+		return withCode(exitUsage, err)
+	}
+	opts.To = "template"
+	opts.TemplateText = string(data)
+	opts.Write.Template = t
+	return nil
+}
+
+// outputExt 返回输出文件的扩展名，使用模板时取自模板文件名去掉 .tmpl 等后缀后的扩展名
+func outputExt(opts convertOptions) (string, error) {
+	if opts.To == "template" && opts.Template != "" {
+		name := filepath.Base(opts.Template)
+		switch filepath.Ext(name) {
+		case ".tmpl", ".tpl", ".gotmpl":
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		if ext := filepath.Ext(name); ext != "" {
+			return ext, nil
+		}
+	}
+	return render.OutputExt(opts.To)
+}
+
+// oneOf 判断 v 是否为 values 中的一个
+func oneOf(v string, values []string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// convertInputs 转换所有输入，并按需要输出转换报告
+func convertInputs(ctx context.Context, files []string, filter fileFilter, opts convertOptions) error {
+	if opts.ReportFile != "" && opts.Report == "" {
+		opts.Report = "json"
+	}
+	if opts.Report != "" && opts.Report != "json" {
+		return withCode(exitUsage, i18n.Errorf("不支持的报告格式: %s", opts.Report))
+	}
+	rep := &runReport{StartedAt: time.Now()}
+	var err error
+	if opts.Bundle != "" {
+		err = convertBundle(ctx, files, filter, opts, rep)
+	} else {
+		err = interrupted(ctx, convertAll(ctx, files, filter, opts, rep))
+	}
+	if err != nil {
+		rep.Error = err.Error()
+	}
+	if opts.Open && len(generatedFiles) > 0 {
+		openOutputFolders(generatedFiles)
+	}
+	// -bundle 时清单写在压缩包中
+	if opts.Manifest != "" && !opts.DryRun && opts.Bundle == "" {
+		if merr := writeManifest(opts.Manifest, rep, err, opts); merr != nil && err == nil {
+			err = merr
+		}
+	}
+	if opts.Report != "" {
+		if rerr := writeReport(rep, opts); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// convertAll 转换所有输入，未指定输入时从管道读取或在终端中提示输入路径
+func convertAll(ctx context.Context, files []string, filter fileFilter, opts convertOptions, rep *runReport) error {
+	if err := prepareOptions(&opts); err != nil {
+		return err
+	}
+	if opts.Clipboard && opts.To == "xmind" {
+		return withCode(exitUsage, i18n.Errorf("-clipboard 只支持文本格式的输出"))
+	}
+	// 转换报告输出到标准输出时不再输出给人看的提示，-bundle 时输出的是临时目录中的文件，只在打包后输出提示
+	quiet := verbosity <= levelQuiet || opts.reportToStdout() || opts.Bundle != ""
+
+	// 支持 `xmindtomarkdown -` 形式，或未指定文件但标准输入不是终端（管道或重定向）
+	if len(files) == 0 && !isTerminal(os.Stdin) {
+		files = append(files, "-")
+	}
+
+	if len(files) == 1 && files[0] == "-" {
+		if opts.Index {
+			return withCode(exitUsage, i18n.Errorf("-index 只能用于批量转换，且不能与 -merge 同时使用"))
+		}
+		if opts.SheetRegex != nil || opts.Bundle != "" {
+			return withCode(exitUsage, i18n.Errorf("-sheet-regex 与 -bundle 不能用于从标准输入读取的内容"))
+		}
+		// 转换结果默认输出到标准输出
+		start := time.Now()
+		sheets, err := readStdin(opts.From)
+		if err == nil {
+			sheets, err = transformSheets(ctx, sheets, opts)
+		}
+		if err != nil {
+			rep.add(fileReport{Input: "-"}, err)
+			return err
+		}
+		fr := newFileReport("-", sheets, opts)
+		logSheets(fr, sheets)
+		if opts.Preview {
+			printPreview(os.Stdout, sheets, opts)
+		}
+		if opts.Clipboard {
+			if err := copyOutput(sheets, opts); err != nil {
+				rep.add(fr, err)
+				return err
+			}
+			logf(levelNormal, "已复制到剪贴板")
+		}
+		if (opts.Preview || opts.Clipboard) && opts.Output == "" {
+			rep.add(fr, nil)
+			return nil
+		}
+		if opts.DryRun {
+			fr.Output = opts.Output
+			if fr.Output == "" {
+				fr.Output = "-"
+			}
+			fr.Action = outputAction(fr.Output)
+			rep.add(fr, nil)
+			if !quiet {
+				printDryRun(os.Stderr, rep)
+			}
+			return nil
+		}
+		if opts.StatsFooter {
+			opts.Write.Footer = statsFooter(i18n.T("标准输入"), sheets, opts)
+		}
+		if opts.Output != "" {
+			err = writeOutput(ctx, opts.Output, sheets, opts)
+		} else {
+			err = withCode(exitWrite, render.WriteAsContext(ctx, opts.To, os.Stdout, sheets, opts.Write))
+		}
+		if err == nil {
+			fr.Output = opts.Output
+			if fr.Output == "" {
+				fr.Output = "-"
+			}
+			recordOutput(fr.Output)
+		}
+		fr.DurationMs = time.Since(start).Milliseconds()
+		rep.add(fr, err)
+		return err
+	}
+
+	if len(files) == 0 {
+		filePath, err := promptPath(ctx)
+		if err != nil {
+			return err
+		}
+		files = append(files, filePath)
+	}
+
+	inputs, batch, err := expandInputs(files, filter)
+	if err != nil {
+		return err
+	}
+	if batch && opts.Output != "" && !opts.Merge {
+		return withCode(exitUsage, i18n.Errorf("-o 只能用于单个输入文件"))
+	}
+	if opts.Output != "" && opts.OutDir != "" {
+		return withCode(exitUsage, i18n.Errorf("-o 与 -out-dir 不能同时使用"))
+	}
+	if opts.Index && (!batch || opts.Merge) {
+		return withCode(exitUsage, i18n.Errorf("-index 只能用于批量转换，且不能与 -merge 同时使用"))
+	}
+	if batch && opts.Clipboard {
+		return withCode(exitUsage, i18n.Errorf("-clipboard 只能用于单个输入文件"))
+	}
+
+	// 跳过上次转换后没有变化的本地输入，预览与复制到剪贴板时总是需要解析输入
+	// -bundle 时输出到临时目录，不使用缓存
+	if !opts.Preview && !opts.Clipboard && opts.Bundle == "" {
+		opts.Cache = loadBuildCache()
+	}
+	if !opts.DryRun {
+		defer opts.Cache.save()
+	}
+	if opts.Merge {
+		return mergeInputs(ctx, inputs, opts, rep, quiet)
+	}
+
+	// 标准错误为终端时在转换过程中显示进度，很快完成的转换不会显示
+	var prog *progress
+	if !opts.NoProgress && !opts.Preview && verbosity > levelQuiet && isTerminal(os.Stderr) {
+		prog = startProgress(os.Stderr, len(inputs))
+	}
+
+	// 单个文件保持原有的输出方式
+	if !batch && !opts.DryRun {
+		prog.begin(inputs[0].Path)
+		var fr fileReport
+		_, err := newEngine(opts, func(int) string { return inputs[0].Path }).Retry(ctx, func(ctx context.Context) (err error) {
+			fr, err = convertFile(ctx, inputs[0], opts)
+			return err
+		})
+		prog.finish(err)
+		prog.close()
+		rep.add(fr, err)
+		if err != nil {
+			return &fileError{path: inputs[0].Path, err: err}
+		}
+		// 输出到标准输出时不再打印提示，避免混入转换结果
+		for _, out := range fr.outputs() {
+			recordOutput(out)
+			switch {
+			case quiet || out == "-":
+			case fr.Skipped:
+				fmt.Printf(i18n.T("文件没有变化，已跳过: %s\n"), out)
+			default:
+				fmt.Printf(i18n.T("文件已生成: %s\n"), out)
+			}
+		}
+		if opts.Clipboard && !quiet {
+			fmt.Println(i18n.T("已复制到剪贴板"))
+		}
+		return nil
+	}
+
+	// 批量转换完成后以表格形式汇总每个文件的结果
+	failed := 0
+	var rows [][]string
+	planned := map[string]bool{}
+	convertBatch(ctx, inputs, opts, prog, func(in input, fr fileReport, err error) {
+		if err == nil && opts.DryRun {
+			err = planOutput(&fr, planned, opts)
+		}
+		rep.add(fr, err)
+		if err != nil {
+			failed++
+			rows = append(rows, []string{"✗", in.Path, briefError(err)})
+			// JSON 格式时每个失败的文件单独输出一条错误，便于脚本逐个处理
+			if errorFormat == "json" {
+				restore := suspendProgress()
+				printError(os.Stderr, errorFormat, &fileError{path: in.Path, err: err})
+				restore()
+			}
+			return
+		}
+		if fr.Skipped {
+			rows = append(rows, []string{"-", in.Path, strings.Join(fr.outputs(), ", ") + i18n.T("（没有变化）")})
+			return
+		}
+		for _, out := range fr.outputs() {
+			recordOutput(out)
+		}
+		output := strings.Join(fr.outputs(), ", ")
+		if output == "" {
+			output = i18n.T("（仅预览）")
+		}
+		rows = append(rows, []string{"✓", in.Path, output})
+	})
+	prog.close()
+	if opts.DryRun && !quiet {
+		printDryRun(os.Stdout, rep)
+	} else if !quiet {
+		printTable(os.Stdout, []string{"状态", "输入", "输出 / 错误"}, rows)
+		fmt.Printf(i18n.T("\n共 %d 个文件，成功 %d 个（其中 %d 个没有变化），失败 %d 个\n"), len(inputs), len(inputs)-failed, rep.Skipped, failed)
+	}
+	if opts.Index && !opts.DryRun && ctx.Err() == nil {
+		index, err := writeIndex(ctx, rep, opts)
+		if err != nil {
+			return err
+		}
+		if index != "" {
+			recordOutput(index)
+			if !quiet {
+				fmt.Printf(i18n.T("目录已生成: %s\n"), index)
+			}
+		}
+	}
+	if failed > 0 {
+		return withCode(exitPartial, i18n.Errorf("部分文件转换失败"))
+	}
+	return nil
+}
+
+// convertBatch 用 opts.Jobs 个 worker 并行转换 inputs，并按输入的顺序依次将每个文件的结果传给 handle
+// 预览时按顺序逐个转换，使预览的内容不会交错；ctx 取消后还没有开始的文件不再转换
+func convertBatch(ctx context.Context, inputs []input, opts convertOptions, prog *progress, handle func(in input, fr fileReport, err error)) {
+	e := newEngine(opts, func(i int) string { return inputs[i].Path })
+	if opts.Preview {
+		e.Workers = 1
+	}
+	reports := make([]fileReport, len(inputs))
+	e.Run(ctx, len(inputs), func(ctx context.Context, i int) error {
+		in := inputs[i]
+		if opts.Preview {
+			fmt.Printf("\n==> %s <==\n\n", in.Path)
+		}
+		prog.begin(in.Path)
+		fr, err := convertFile(ctx, in, opts)
+		err = interrupted(ctx, err)
+		prog.finish(err)
+		reports[i] = fr
+		return err
+	}, func(r batch.Result) {
+		in := inputs[r.Index]
+		if errors.Is(r.Err, batch.ErrNotStarted) {
+			handle(in, fileReport{Input: in.Path}, errInterrupted())
+			return
+		}
+		handle(in, reports[r.Index], r.Err)
+	})
+}
+
+// promptPath 在交互式终端中提示用户输入文件路径，等待输入时 ctx 取消（如按 Ctrl+C）则返回 errInterrupted
+func promptPath(ctx context.Context) (string, error) {
+	if !isTerminal(os.Stdin) {
+		return "", withCode(exitUsage, i18n.Errorf("必须指定思维导图文件路径"))
+	}
+	fmt.Print(i18n.T("请输入思维导图文件路径: "))
+	// 读取用户输入（去除两端空白字符及拖放文件时带上的引号）
+	type result struct {
+		line string
+		err  error
+	}
+	read := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		read <- result{line, err}
+	}()
+	var line string
+	var err error
+	select {
+	case r := <-read:
+		line, err = r.line, r.err
+	case <-ctx.Done():
+		fmt.Println()
+		return "", errInterrupted()
+	}
+	filePath := strings.Trim(strings.TrimSpace(line), `"`)
+	if filePath == "" {
+		if err != nil && err != io.EOF {
+			return "", i18n.Errorf("读取输入失败: %v", err)
+		}
+		return "", withCode(exitUsage, i18n.Errorf("必须指定思维导图文件路径"))
+	}
+	return filePath, nil
+}
+
+// readStdin 从标准输入读取文件内容并解析，format 为 auto 时根据内容识别格式
+func readStdin(format string) ([]xmind.Sheet, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, i18n.Errorf("读取标准输入失败: %v", err)
+	}
+	if len(data) == 0 {
+		return nil, withCode(exitUsage, i18n.Errorf("标准输入为空，必须指定思维导图文件路径"))
+	}
+	sheets, err := xmind.ParseAs(format, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, withCode(exitParse, err)
+	}
+	return sheets, nil
+}
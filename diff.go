@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var diffCommand = &command{
+	Name:  "diff",
+	Args:  "[参数] <旧文件> <新文件>",
+	Short: "比较两个思维导图，列出新增、删除、移动与改名的节点",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var from string
+		var asJSON, unified, exitStatus bool
+		fs.StringVar(&from, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出差异")
+		fs.BoolVar(&unified, "unified", false, "将两边写出为缩进文本，以 diff -u 的格式逐行比较")
+		fs.BoolVar(&exitStatus, "exit-code", false, "有差异时以退出码 11 结束，便于在脚本与 CI 中判断")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 2 {
+				return withCode(exitUsage, i18n.Errorf("diff 需要指定旧文件与新文件"))
+			}
+			if asJSON && unified {
+				return withCode(exitUsage, i18n.Errorf("-json 与 -unified 不能同时使用"))
+			}
+			old, err := loadWorkbook(ctx, args[0], from)
+			if err != nil {
+				return err
+			}
+			cur, err := loadWorkbook(ctx, args[1], from)
+			if err != nil {
+				return err
+			}
+			var changed bool
+			if unified {
+				changed, err = writeUnified(os.Stdout, old.Path, cur.Path, outlineLines(old.Sheets), outlineLines(cur.Sheets), 3)
+			} else {
+				d := diffWorkbooks(old, cur)
+				changed = d.changed()
+				if asJSON {
+					enc := json.NewEncoder(os.Stdout)
+					enc.SetIndent("", "  ")
+					err = enc.Encode(d)
+				} else {
+					err = writeDiffMarkdown(os.Stdout, d)
+				}
+			}
+			if err != nil {
+				return withCode(exitWrite, err)
+			}
+			if changed && exitStatus {
+				return withCode(exitDifferent, i18n.Errorf("两个文件的内容不同"))
+			}
+			return nil
+		}
+	},
+}
+
+// topicDiff 为 diff 报告中的一个节点
+type topicDiff struct {
+	ID string `json:"id,omitempty"`
+	// Path 为节点所在的位置，依次为画布（有标题时）与各级节点的标题，最后一项为节点本身
+	Path []string `json:"path"`
+	// Topics 为新增或删除的节点连同其子节点的总数
+	Topics int `json:"topics,omitempty"`
+	// From 与 To 为移动前后的位置（Path 为移动后的位置）或改名前后的标题
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// workbookDiff 为 diff 命令的结果
+type workbookDiff struct {
+	Old      string      `json:"old"`
+	New      string      `json:"new"`
+	Added    []topicDiff `json:"added"`
+	Removed  []topicDiff `json:"removed"`
+	Moved    []topicDiff `json:"moved"`
+	Retitled []topicDiff `json:"retitled"`
+}
+
+func (d workbookDiff) changed() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Moved)+len(d.Retitled) > 0
+}
+
+// diffEntry 为比较时的一个节点
+type diffEntry struct {
+	key, parent string
+	topic       *xmind.Topic
+	path        []string
+	// size 为节点连同其子节点的总数
+	size int
+}
+
+// diffIndex 为一个文件中所有节点按 key 建立的索引，order 保持先序遍历的顺序
+type diffIndex struct {
+	order []*diffEntry
+	byKey map[string]*diffEntry
+}
+
+// indexTopics 为 sheets 中的每个节点生成用于对应两个文件中同一节点的 key
+// XMind 中节点的 ID 在改名与移动后保持不变，优先按 ID 对应；没有 ID（如 OPML、缩进文本）或 ID 重复时按画布与标题路径对应，
+// 这时改名与移动的节点显示为删除后新增
+func indexTopics(sheets []xmind.Sheet) diffIndex {
+	idx := diffIndex{byKey: map[string]*diffEntry{}}
+	seen := map[string]int{}
+	for i := range sheets {
+		s := &sheets[i]
+		sheetKey := s.ID
+		if sheetKey == "" {
+			sheetKey = strconv.Itoa(i + 1)
+		}
+		// keys 为当前路径上各级节点的 key
+		var keys []string
+		s.Walk(func(path []*xmind.Topic, t *xmind.Topic) error {
+			keys = keys[:len(path)]
+			var titles []string
+			for _, p := range path {
+				titles = append(titles, p.Title)
+			}
+			titles = append(titles, t.Title)
+			key := "id:" + t.ID
+			if _, dup := idx.byKey[key]; t.ID == "" || dup {
+				key = "path:" + sheetKey + "/" + strings.Join(titles, "\x00")
+				seen[key]++
+				key += "#" + strconv.Itoa(seen[key])
+			}
+			if s.Title != "" {
+				titles = append([]string{s.Title}, titles...)
+			}
+			e := &diffEntry{key: key, topic: t, path: titles}
+			if len(keys) > 0 {
+				e.parent = keys[len(keys)-1]
+			} else {
+				e.parent = "sheet:" + sheetKey
+			}
+			keys = append(keys, key)
+			idx.byKey[key] = e
+			idx.order = append(idx.order, e)
+			return nil
+		})
+	}
+	// 先序遍历中子节点都在节点之后，倒序累加即可得到每个子树的大小
+	for i := len(idx.order) - 1; i >= 0; i-- {
+		e := idx.order[i]
+		e.size++
+		if p := idx.byKey[e.parent]; p != nil {
+			p.size += e.size
+		}
+	}
+	return idx
+}
+
+// diffWorkbooks 比较两个文件，新增与删除的子树只列出最上层的节点
+func diffWorkbooks(old, cur *workbook) workbookDiff {
+	d := workbookDiff{Old: old.Path, New: cur.Path, Added: []topicDiff{}, Removed: []topicDiff{}, Moved: []topicDiff{}, Retitled: []topicDiff{}}
+	a, b := indexTopics(old.Sheets), indexTopics(cur.Sheets)
+	for _, e := range a.order {
+		if b.byKey[e.key] != nil {
+			continue
+		}
+		if _, parentGone := a.byKey[e.parent]; parentGone && b.byKey[e.parent] == nil {
+			continue
+		}
+		d.Removed = append(d.Removed, topicDiff{ID: e.topic.ID, Path: e.path, Topics: e.size})
+	}
+	for _, e := range b.order {
+		o := a.byKey[e.key]
+		if o == nil {
+			if _, parentNew := b.byKey[e.parent]; parentNew && a.byKey[e.parent] == nil {
+				continue
+			}
+			d.Added = append(d.Added, topicDiff{ID: e.topic.ID, Path: e.path, Topics: e.size})
+			continue
+		}
+		if o.topic.Title != e.topic.Title {
+			d.Retitled = append(d.Retitled, topicDiff{ID: e.topic.ID, Path: e.path, From: o.topic.Title, To: e.topic.Title})
+		}
+		if o.parent != e.parent {
+			d.Moved = append(d.Moved, topicDiff{ID: e.topic.ID, Path: e.path, From: o.path[:len(o.path)-1], To: e.path[:len(e.path)-1]})
+		}
+	}
+	return d
+}
+
+// writeDiffMarkdown 输出 Markdown 格式的差异报告
+func writeDiffMarkdown(w io.Writer, d workbookDiff) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, i18n.T("# 思维导图差异: %s → %s\n\n"), d.Old, d.New)
+	if !d.changed() {
+		b.WriteString(i18n.T("两个文件的节点没有差异。\n"))
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	fmt.Fprintf(&b, i18n.T("新增 %d 处，删除 %d 处，移动 %d 个，改名 %d 个\n"), len(d.Added), len(d.Removed), len(d.Moved), len(d.Retitled))
+	section := func(title string, items []topicDiff, line func(topicDiff) string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", i18n.T(title))
+		for _, it := range items {
+			fmt.Fprintf(&b, "- %s\n", line(it))
+		}
+	}
+	subtree := func(t topicDiff) string {
+		s := diffPath(t.Path)
+		if t.Topics > 1 {
+			s += i18n.Sprintf("（含 %d 个子节点）", t.Topics-1)
+		}
+		return s
+	}
+	section("新增", d.Added, subtree)
+	section("删除", d.Removed, subtree)
+	section("移动", d.Moved, func(t topicDiff) string {
+		return fmt.Sprintf("%s: %s → %s", inlineCode(trailTitle(t.Path[len(t.Path)-1])), diffPath(t.From.([]string)), diffPath(t.To.([]string)))
+	})
+	section("改名", d.Retitled, func(t topicDiff) string {
+		return fmt.Sprintf("%s: %s → %s", diffPath(t.Path[:len(t.Path)-1]), inlineCode(t.From.(string)), inlineCode(t.To.(string)))
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// diffPath 将节点的路径输出为 `画布 > 节点 > 子节点`，空标题显示为“（无标题）”
+func diffPath(path []string) string {
+	titles := make([]string, len(path))
+	for i, p := range path {
+		titles[i] = trailTitle(p)
+	}
+	return inlineCode(strings.Join(titles, " > "))
+}
+
+// inlineCode 将 s 输出为 Markdown 的行内代码，s 中的换行替换为空格，反引号按需要加长定界符
+func inlineCode(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") || s == "" {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// outlineLines 将 sheets 写出为缩进文本并按行拆分，用于 unified 方式的逐行比较
+func outlineLines(sheets []xmind.Sheet) []string {
+	var buf bytes.Buffer
+	render.WriteText(&buf, sheets)
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
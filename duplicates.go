@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// duplicateScopes 为 duplicates 的查找范围：同一节点下的子节点，或整个文件中的所有节点
+var duplicateScopes = []string{"siblings", "map"}
+
+var duplicatesCommand = &command{
+	Name:  "duplicates",
+	Args:  "[参数] <文件>...",
+	Short: "列出标题相同（忽略大小写与多余的空白）的同级节点或整个文件中的重复节点",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var scope string
+		var asJSON bool
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.StringVar(&scope, "scope", "siblings", "查找范围: "+strings.Join(duplicateScopes, ", ")+"（siblings 为同一节点下的子节点，map 为整个文件）")
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出重复的节点")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return withCode(exitUsage, i18n.Errorf("duplicates 需要指定至少一个文件"))
+			}
+			if !oneOf(scope, duplicateScopes) {
+				return withCode(exitUsage, i18n.Errorf("不支持的查找范围: %s，可选: %s", scope, strings.Join(duplicateScopes, ", ")))
+			}
+			groups := []duplicateGroup{}
+			for _, in := range args {
+				sheets, _, err := readInputSheets(ctx, in, opts)
+				if err != nil {
+					return &fileError{path: in, err: err}
+				}
+				groups = append(groups, findDuplicates(in, sheets, scope == "map")...)
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(groups); err != nil {
+					return withCode(exitWrite, err)
+				}
+				return nil
+			}
+			printDuplicates(os.Stdout, groups)
+			return nil
+		}
+	},
+}
+
+// duplicateGroup 为一组标题相同的节点，Title 为第一个节点的标题
+type duplicateGroup struct {
+	File   string           `json:"file"`
+	Title  string           `json:"title"`
+	Topics []duplicateTopic `json:"topics"`
+}
+
+// duplicateTopic 为重复的节点之一，Path 依次为画布（有标题时）与各级节点的标题
+type duplicateTopic struct {
+	Path    []string `json:"path"`
+	TopicID string   `json:"topicId,omitempty"`
+}
+
+// findDuplicates 按出现的顺序返回 sheets 中标题相同的节点，wide 为 false 时只比较同一节点下的子节点，
+// 为 true 时比较文件中的所有节点；空标题的节点不参与比较
+func findDuplicates(file string, sheets []xmind.Sheet, wide bool) []duplicateGroup {
+	var groups []duplicateGroup
+	index := map[string]int{}
+	for i := range sheets {
+		s := &sheets[i]
+		s.Walk(func(path []*xmind.Topic, t *xmind.Topic) error {
+			key := duplicateKey(t.Title)
+			if key == "" {
+				return nil
+			}
+			if !wide {
+				// 同级节点的上级节点在 path 中的最后一个位置，用其地址区分不同的上级节点
+				if len(path) == 0 {
+					return nil
+				}
+				key = fmt.Sprintf("%d/%p/%s", i, path[len(path)-1], key)
+			}
+			g, ok := index[key]
+			if !ok {
+				g = len(groups)
+				index[key] = g
+				groups = append(groups, duplicateGroup{File: file, Title: trailTitle(t.Title)})
+			}
+			groups[g].Topics = append(groups[g].Topics, duplicateTopic{Path: topicTrail(s, path, t), TopicID: t.ID})
+			return nil
+		})
+	}
+	var dups []duplicateGroup
+	for _, g := range groups {
+		if len(g.Topics) > 1 {
+			dups = append(dups, g)
+		}
+	}
+	return dups
+}
+
+// duplicateKey 返回比较标题时使用的文本：转换为小写，去掉首尾空白，连续的空白合并为一个空格
+func duplicateKey(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
+
+// printDuplicates 每组重复的节点先输出一行标题与个数，再依次输出每个节点的位置
+func printDuplicates(w io.Writer, groups []duplicateGroup) {
+	for _, g := range groups {
+		fmt.Fprintf(w, i18n.T("%s: %d 个标题为 %q 的节点\n"), g.File, len(g.Topics), g.Title)
+		for _, t := range g.Topics {
+			fmt.Fprintf(w, "  %s\n", strings.Join(t.Path, " > "))
+		}
+	}
+	if len(groups) == 0 {
+		fmt.Fprintln(w, i18n.T("没有发现重复的节点"))
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// envPrefix 为参数对应的环境变量前缀
+const envPrefix = "XMIND2MD_"
+
+// envName 返回参数对应的环境变量名称，如 out-dir 对应 XMIND2MD_OUT_DIR
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv 将 XMIND2MD_* 环境变量设置到命令行没有指定的参数上，并记录到 set 中，使配置文件不再覆盖
+// 可重复指定的参数用逗号分隔多个值，如 XMIND2MD_EXCLUDE_FILES="archive/**,*.bak"
+func applyEnv(fs *flag.FlagSet, set map[string]bool) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		key := fmt.Sprintf("%p", f.Value)
+		value := os.Getenv(envName(f.Name))
+		if err != nil || value == "" || set[key] || conflictSet(fs, set, f.Name) {
+			return
+		}
+		values := []string{value}
+		switch f.Value.(type) {
+		case *stringList, stringMap:
+			values = strings.Split(value, ",")
+		}
+		for _, v := range values {
+			if serr := fs.Set(f.Name, strings.TrimSpace(v)); serr != nil {
+				err = withCode(exitUsage, i18n.Errorf("环境变量 %s 的值 %q 无效: %v", envName(f.Name), v, serr))
+				return
+			}
+		}
+		set[key] = true
+	})
+	return err
+}
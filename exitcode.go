@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// 退出码，便于脚本与 CI 根据失败原因分别处理
+const (
+	exitOK        = 0
+	exitFailure   = 1  // 其他错误，如下载失败
+	exitUsage     = 2  // 参数错误
+	exitNotFound  = 3  // 输入文件不存在
+	exitNotZip    = 4  // 不是有效的压缩包
+	exitNoContent = 5  // 缺少思维导图内容
+	exitParse     = 6  // 解析失败
+	exitWrite     = 7  // 写入输出失败
+	exitPartial   = 8  // 批量转换时部分文件失败
+	exitExists    = 9  // 输出文件已存在
+	exitEncrypted = 10 // 文件已设置密码
+	exitDifferent = 11 // diff --exit-code 时两个文件不同，或 check 发现已导出的文件过期
+	exitLint      = 12 // lint 发现了错误（或 --strict 时的警告）
+	exitLinks     = 13 // check-links 发现了失效的链接
+	// exitInterrupted 与 shell 中被 SIGINT 结束的进程相同
+	exitInterrupted = 130 // 收到中断信号（Ctrl+C）
+)
+
+// exitKinds 为各退出码在 JSON 错误信息中的名称
+var exitKinds = map[int]string{
+	exitFailure:     "error",
+	exitUsage:       "usage",
+	exitNotFound:    "not_found",
+	exitNotZip:      "not_zip",
+	exitNoContent:   "no_content",
+	exitParse:       "parse_error",
+	exitWrite:       "write_error",
+	exitPartial:     "partial_failure",
+	exitExists:      "exists",
+	exitEncrypted:   "encrypted",
+	exitDifferent:   "different",
+	exitLint:        "lint",
+	exitLinks:       "dead_links",
+	exitInterrupted: "interrupted",
+}
+
+// codeError 为错误附加退出码，错误信息保持不变
+type codeError struct {
+	code int
+	err  error
+}
+
+func (e *codeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codeError) Unwrap() error {
+	return e.err
+}
+
+// withCode 为 err 附加退出码，err 为 nil 时返回 nil
+func withCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codeError{code: code, err: err}
+}
+
+// fileError 记录出错的输入文件，错误信息保持不变
+type fileError struct {
+	path string
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+// exitCode 根据错误原因返回退出码
+// 解析阶段的错误优先按具体原因（文件不存在、不是压缩包、缺少内容）区分
+func exitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	var ce *codeError
+	if errors.As(err, &ce) && ce.code != exitParse {
+		return ce.code
+	}
+	switch {
+	case errors.Is(err, xmind.ErrNotZip):
+		return exitNotZip
+	case errors.Is(err, xmind.ErrNoContent):
+		return exitNoContent
+	case errors.Is(err, xmind.ErrEncrypted):
+		return exitEncrypted
+	case errors.Is(err, os.ErrNotExist):
+		return exitNotFound
+	}
+	if ce != nil {
+		return ce.code
+	}
+	return exitFailure
+}
+
+// briefError 返回不含输入文件路径的错误信息，用于已经单独列出输入文件的汇总表格与 JSON 错误
+// 解析错误的信息以文件路径开头（见 xmind.Error），err 的其他包装不改变错误信息
+func briefError(err error) string {
+	var pe *xmind.Error
+	if errors.As(err, &pe) && pe.File != "" && pe.Error() == err.Error() {
+		brief := *pe
+		brief.File = ""
+		return brief.Error()
+	}
+	return err.Error()
+}
+
+// errorRecord 为 -error-format=json 时输出的错误对象
+type errorRecord struct {
+	Code    int    `json:"code"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+}
+
+// printError 按 format（text 或 json）将错误输出到 w，json 格式每个错误占一行
+func printError(w io.Writer, format string, err error) {
+	if format != "json" {
+		fmt.Fprintln(w, err)
+		return
+	}
+	code := exitCode(err)
+	rec := errorRecord{Code: code, Kind: exitKinds[code], Message: err.Error()}
+	var fe *fileError
+	if errors.As(err, &fe) {
+		rec.File = fe.path
+		rec.Message = briefError(err)
+	}
+	data, _ := json.Marshal(rec)
+	fmt.Fprintln(w, string(data))
+}
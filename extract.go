@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var extractCommand = &command{
+	Name:  "extract",
+	Args:  "[参数] <文件>",
+	Short: "提取 .xmind 文件中的图片、附件与录音等资源",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var dir string
+		var opts convertOptions
+		fs.StringVar(&dir, "out", "", "保存资源的目录，默认为输入文件旁边与其同名的目录")
+		fs.StringVar(&dir, "out-dir", "", "同 -out")
+		fs.BoolVar(&opts.Force, "force", false, "覆盖已存在的输出文件")
+		fs.BoolVar(&opts.Backup, "backup", false, "覆盖已存在的输出文件前保留一份带时间戳的备份")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("extract 需要指定一个文件"))
+			}
+			wb, err := loadWorkbook(ctx, args[0], "auto")
+			if err != nil {
+				return err
+			}
+			if !wb.zipped() || wb.Format != "xmind" {
+				return withCode(exitUsage, i18n.Errorf("%s 不是 .xmind 文件，只有 .xmind 文件中保存了资源", wb.Path))
+			}
+			if dir == "" {
+				dir = strings.TrimSuffix(wb.Path, filepath.Ext(wb.Path))
+			}
+
+			opts.Cache = loadBuildCache()
+			defer opts.Cache.save()
+			create := func(p string) (io.WriteCloser, error) {
+				if err := protectOutput(p, opts); err != nil {
+					return nil, err
+				}
+				w, err := createOutput(ctx, p, opts.Fetch)
+				if err != nil {
+					opts.Cache.unclaim(p)
+					return nil, withCode(exitWrite, err)
+				}
+				return &extractWriter{WriteCloser: w, path: p, cache: opts.Cache}, nil
+			}
+			write := render.WriteOptions{OnEvent: func(e render.Event) {
+				opts.Cache.storeOutput(e.Path)
+				logf(levelVerbose, "已提取 %s（%s）", e.Path, formatBytes(e.Size), slog.String("resource", e.Resource), slog.String("output", e.Path), slog.Int64("size", e.Size))
+			}}
+			paths, err := render.ExtractResources(ctx, bytes.NewReader(wb.data), wb.Size, dir, create, write)
+			if err != nil {
+				// create 与 extractWriter 返回的错误已经带有退出码，创建目录失败为 *os.PathError；
+				// xmind.Resources 不会列出不安全的文件名，ErrUnsafePath 只可能是输出目录中的符号链接或同名文件；
+				// 其余为读取资源时的错误（如资源损坏或缺失），属于输入文件的问题
+				var ce *codeError
+				var pe *os.PathError
+				switch {
+				case ctx.Err() != nil:
+				case errors.As(err, &ce):
+				case errors.As(err, &pe), errors.Is(err, xmind.ErrUnsafePath):
+					err = withCode(exitWrite, err)
+				default:
+					err = withCode(exitParse, err)
+				}
+				return &fileError{path: wb.Path, err: err}
+			}
+			if verbosity > levelQuiet {
+				if len(paths) == 0 {
+					fmt.Printf(i18n.T("%s 中没有资源\n"), wb.Path)
+				} else {
+					fmt.Printf(i18n.T("已将 %d 个资源提取到: %s\n"), len(paths), dir)
+				}
+			}
+			return nil
+		}
+	},
+}
+
+// extractWriter 为写入资源失败的错误附加 exitWrite，使其与读取资源时的错误区分开，
+// 并在没有写成时撤销 claim，与 writeOutput 相同
+type extractWriter struct {
+	io.WriteCloser
+	path  string
+	cache *buildCache
+}
+
+func (w *extractWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	return n, w.fail(err)
+}
+
+func (w *extractWriter) Close() error {
+	return w.fail(w.WriteCloser.Close())
+}
+
+// Abort 放弃支持放弃的输出，如先写入临时文件再重命名的文件
+func (w *extractWriter) Abort() {
+	if a, ok := w.WriteCloser.(aborter); ok {
+		a.Abort()
+	} else {
+		w.WriteCloser.Close()
+	}
+	w.cache.unclaim(w.path)
+}
+
+func (w *extractWriter) fail(err error) error {
+	if err != nil {
+		w.cache.unclaim(w.path)
+	}
+	return withCode(exitWrite, err)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// fetchOptions 控制下载远程文件时的行为
+type fetchOptions struct {
+	// Timeout 为整个请求的超时时间
+	Timeout time.Duration
+	// MaxSize 为允许下载的最大字节数
+	MaxSize int64
+	// AuthHeader 形如 "Authorization: Bearer xxx"，为空时不附加
+	AuthHeader string
+	// DriveToken 为访问 Google Drive 使用的 OAuth 访问令牌
+	DriveToken string
+}
+
+// download 发送请求并读取响应内容，超过大小限制时返回错误
+func download(req *http.Request, opts fetchOptions) ([]byte, error) {
+	client := &http.Client{Timeout: opts.Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, i18n.Errorf("下载文件失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, i18n.Errorf("下载文件失败: %s", resp.Status)
+	}
+	if opts.MaxSize > 0 && resp.ContentLength > opts.MaxSize {
+		return nil, i18n.Errorf("文件大小超过限制 (%d 字节)", opts.MaxSize)
+	}
+
+	body := io.Reader(resp.Body)
+	if opts.MaxSize > 0 {
+		body = io.LimitReader(resp.Body, opts.MaxSize+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, i18n.Errorf("下载文件失败: %w", err)
+	}
+	if opts.MaxSize > 0 && int64(len(data)) > opts.MaxSize {
+		return nil, i18n.Errorf("文件大小超过限制 (%d 字节)", opts.MaxSize)
+	}
+	return data, nil
+}
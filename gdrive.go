@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// driveAPI 为 Google Drive v3 文件接口地址
+var driveAPI = "https://www.googleapis.com/drive/v3/files/"
+
+// driveSource 通过文件 ID 从 Google Drive 下载文件，需要 OAuth 访问令牌
+type driveSource struct {
+	fileID string
+}
+
+func (s driveSource) fetch(ctx context.Context, opts fetchOptions) (string, []byte, error) {
+	if s.fileID == "" {
+		return "", nil, i18n.Errorf("缺少 Google Drive 文件 ID")
+	}
+	if opts.DriveToken == "" {
+		return "", nil, i18n.Errorf("访问 Google Drive 需要通过 -drive-token 指定 OAuth 访问令牌")
+	}
+
+	// 先读取文件元数据获得文件名，再下载文件内容
+	meta, err := s.request(ctx, "fields=name", opts)
+	if err != nil {
+		return "", nil, err
+	}
+	var info struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(meta, &info); err != nil {
+		return "", nil, i18n.Errorf("解析 Google Drive 文件信息失败: %v", err)
+	}
+	name := path.Base(info.Name)
+	if name == "." || name == "/" {
+		name = s.fileID
+	}
+
+	data, err := s.request(ctx, "alt=media", opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, data, nil
+}
+
+// request 请求 Drive 文件接口，query 为附加的查询参数
+func (s driveSource) request(ctx context.Context, query string, opts fetchOptions) ([]byte, error) {
+	u := driveAPI + url.PathEscape(s.fileID) + "?supportsAllDrives=true&" + query
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, i18n.Errorf("无效的 Google Drive 文件 ID: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+opts.DriveToken)
+	return download(req, opts)
+}
+
+// driveFileID 从 Google Drive 的分享链接中提取文件 ID，不是 Drive 链接时返回空字符串
+// 支持 https://drive.google.com/file/d/<ID>/view 与 https://drive.google.com/open?id=<ID>
+func driveFileID(u *url.URL) string {
+	if !strings.EqualFold(u.Hostname(), "drive.google.com") {
+		return ""
+	}
+	if id := u.Query().Get("id"); id != "" {
+		return id
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "d" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
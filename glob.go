@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// hasGlobMeta 判断输入中是否包含通配符
+func hasGlobMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// expandGlob 展开通配符，支持用 ** 匹配任意层级的目录
+// 不依赖 shell，因此在不支持递归通配符的 shell（如 cmd.exe）中同样可用
+// 返回的相对路径以通配符之前的目录为起点
+func expandGlob(pattern string) ([]input, error) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	// 通配符之前的部分作为遍历的起始目录
+	n := 0
+	for n < len(segments) && !hasGlobMeta(segments[n]) {
+		n++
+	}
+	base := strings.Join(segments[:n], "/")
+	if base == "" {
+		base = "."
+		if n > 0 {
+			base = "/"
+		}
+	}
+	pat := segments[n:]
+
+	root := filepath.FromSlash(base)
+	var inputs []input
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 起始目录不存在时视为没有匹配的文件
+			if p == root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		matched := matchGlob(pat, strings.Split(filepath.ToSlash(rel), "/"))
+		if d.IsDir() {
+			// 目录形式的 MindNode bundle 作为单个文件处理，不再进入其中
+			if xmind.FormatOf(p) == "mindnode" {
+				if matched {
+					inputs = append(inputs, input{Path: p, Rel: rel})
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matched {
+			inputs = append(inputs, input{Path: p, Rel: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("展开 %s 失败: %v", pattern, err)
+	}
+	if len(inputs) == 0 {
+		return nil, withCode(exitNotFound, i18n.Errorf("没有与 %s 匹配的文件", pattern))
+	}
+	return inputs, nil
+}
+
+// matchGlob 逐段匹配路径，** 可以匹配零个或多个目录
+func matchGlob(pat, name []string) bool {
+	if len(pat) == 0 {
+		return len(name) == 0
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlob(pat[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pat[0], name[0])
+	return ok && matchGlob(pat[1:], name[1:])
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/internal/protowire"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// grpcService 为 proto/xmindtomarkdown/v1/converter.proto 中 Converter 服务的路径前缀
+const grpcService = "/xmindtomarkdown.v1.Converter/"
+
+// gRPC 状态码，见 https://grpc.github.io/grpc/core/md_doc_statuscodes.html
+const (
+	grpcOK                 = 0
+	grpcCanceled           = 1
+	grpcInvalidArgument    = 3
+	grpcDeadlineExceeded   = 4
+	grpcResourceExhausted  = 8
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+)
+
+// grpcChunkSize 为 Convert 返回的每个消息中输出内容的最大字节数
+const grpcChunkSize = 64 << 10
+
+// grpcError 为带有 gRPC 状态码的错误
+type grpcError struct {
+	code int
+	err  error
+}
+
+func (e *grpcError) Error() string { return e.err.Error() }
+
+// grpcHandler 在 HTTP/2 上实现 Converter 服务，只使用标准库，因此直接处理 gRPC 的消息分帧与状态
+type grpcHandler struct {
+	*service
+}
+
+func (h grpcHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, i18n.T("只接受 gRPC 请求"), http.StatusUnsupportedMediaType)
+		return
+	}
+	ctx := r.Context()
+	if t, ok := grpcTimeout(r.Header.Get("Grpc-Timeout")); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t)
+		defer cancel()
+	}
+	// 客户端指定的超时与 -request-timeout 中较短的一个生效
+	ctx, cancel := h.requestContext(ctx)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/grpc")
+
+	var err error
+	switch method := strings.TrimPrefix(r.URL.Path, grpcService); {
+	case r.URL.Path == grpcHealthCheck:
+		err = grpcHealth(w, r.Body)
+	case method == "Convert":
+		c := h.metrics.begin("grpc", "convert")
+		err = h.convert(ctx, w, r.Body, c)
+		c.done(err)
+	case method == "Inspect":
+		c := h.metrics.begin("grpc", "inspect")
+		err = h.inspect(ctx, w, r.Body, c)
+		c.done(err)
+	default:
+		err = &grpcError{grpcUnimplemented, i18n.Errorf("未知的方法: %s", r.URL.Path)}
+	}
+	code, msg := grpcOK, ""
+	if err != nil {
+		code, msg = grpcCode(ctx, err), errorMessage(err)
+		logf(levelVerbose, "gRPC %s 失败: %s", r.URL.Path, msg)
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", grpcEncodeMessage(msg))
+	}
+}
+
+// convert 实现 Convert，输出按 grpcChunkSize 拆分为多个消息依次发送
+func (h grpcHandler) convert(ctx context.Context, w http.ResponseWriter, body io.Reader, c *conversion) error {
+	req, err := h.decodeRequest(body, convertField)
+	defer req.close()
+	if err != nil {
+		return err
+	}
+	c.inSize = req.Size
+	if err := req.check(); err != nil {
+		return &grpcError{grpcInvalidArgument, err}
+	}
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	sheets, _, err := h.parse(ctx, &req)
+	if err != nil {
+		return err
+	}
+	cw := &grpcChunkWriter{w: w}
+	if err := render.WriteAsContext(ctx, req.Format, cw, sheets, req.Write); err != nil {
+		return err
+	}
+	c.outSize = cw.total
+	return cw.flush()
+}
+
+// inspect 实现 Inspect
+func (h grpcHandler) inspect(ctx context.Context, w http.ResponseWriter, body io.Reader, c *conversion) error {
+	// InspectRequest 中 from = 2
+	req, err := h.decodeRequest(body, func(f protowire.Field, req *serveRequest) error {
+		if f.Number == 2 {
+			req.From = string(f.Bytes)
+		}
+		return nil
+	})
+	defer req.close()
+	if err != nil {
+		return err
+	}
+	if req.From == "" {
+		req.From = "auto"
+	}
+	c.inSize = req.Size
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	sheets, format, err := h.parse(ctx, &req)
+	if err != nil {
+		return err
+	}
+	var resp protowire.Encoder
+	resp.String(1, format)
+	for i := range sheets {
+		var info protowire.Encoder
+		info.String(1, sheets[i].ID)
+		info.String(2, sheets[i].Title)
+		info.String(3, sheets[i].RootTopic.Title)
+		info.Varint(4, uint64(sheets[i].TopicCount()))
+		info.Varint(5, uint64(sheetDepth(&sheets[i])))
+		resp.Raw(2, info.Bytes())
+	}
+	return writeGRPCMessage(w, resp.Bytes())
+}
+
+// decodeRequest 读取请求中的一个消息并解码为 serveRequest：字段 1 为上传的文件内容，与 HTTP 接口相同经
+// service.spool 保存在内存或请求独占的临时文件中，不整个读入内存；其余字段由 field 解码，总大小不超过 spoolSize
+// 返回错误时 req 中可能已有临时文件，调用方总是需要调用 req.close
+func (h grpcHandler) decodeRequest(body io.Reader, field func(f protowire.Field, req *serveRequest) error) (serveRequest, error) {
+	var req serveRequest
+	msg, err := grpcMessageReader(body, h.maxSize)
+	if err != nil {
+		return req, err
+	}
+	err = protowire.DecodeReader(msg, func(n int) bool { return n == 1 }, spoolSize, func(f protowire.Field) error {
+		if f.Number != 1 {
+			return field(f, &req)
+		}
+		if f.Wire != protowire.WireBytes {
+			return nil
+		}
+		// 字段重复出现时以最后一个为准
+		req.close()
+		if err := h.spool(f.Reader, &req); err != nil {
+			return &grpcError{grpcInvalidArgument, i18n.Errorf("读取请求失败: %v", err)}
+		}
+		return nil
+	})
+	var ge *grpcError
+	switch {
+	case err == nil:
+	case errors.As(err, &ge):
+	case errors.Is(err, protowire.ErrTooLarge):
+		err = &grpcError{grpcResourceExhausted, i18n.Errorf("请求中的参数超过 %d 字节", spoolSize)}
+	default:
+		err = &grpcError{grpcInvalidArgument, err}
+	}
+	return req, err
+}
+
+// convertField 解码 ConvertRequest 中文件内容以外的字段
+func convertField(f protowire.Field, req *serveRequest) error {
+	switch f.Number {
+	case 2:
+		req.From = string(f.Bytes)
+	case 3:
+		req.Format = string(f.Bytes)
+	case 4:
+		req.Write.HeadingStart = int(int32(f.Num))
+	case 5:
+		req.Write.Notes = f.Num != 0
+	case 6:
+		req.Write.LeafStyle = string(f.Bytes)
+	case 7:
+		req.Write.MaxDepth = int(int32(f.Num))
+	case 8:
+		// map 字段的每一项为 key = 1、value = 2 的嵌套消息
+		var k, v string
+		if err := protowire.Decode(f.Bytes, func(e protowire.Field) error {
+			switch e.Number {
+			case 1:
+				k = string(e.Bytes)
+			case 2:
+				v = string(e.Bytes)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if req.Write.Markers == nil {
+			req.Write.Markers = map[string]string{}
+		}
+		req.Write.Markers[k] = v
+	}
+	return nil
+}
+
+// grpcMessageHead 读取请求中一个消息的开头，返回消息的长度
+// 消息以 1 字节的压缩标记与 4 字节的长度开头
+func grpcMessageHead(r io.Reader, maxSize int64) (int64, error) {
+	var head [5]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, &grpcError{grpcInvalidArgument, i18n.Errorf("读取请求失败: %v", err)}
+	}
+	if head[0] != 0 {
+		return 0, &grpcError{grpcUnimplemented, i18n.Errorf("不支持压缩的消息")}
+	}
+	n := int64(binary.BigEndian.Uint32(head[1:]))
+	if n > maxSize {
+		return 0, &grpcError{grpcResourceExhausted, i18n.Errorf("请求超过 %d 字节", maxSize)}
+	}
+	return n, nil
+}
+
+// grpcMessageReader 返回只读取请求中一个消息的内容的 Reader，内容不足时由解码报告消息不完整
+func grpcMessageReader(r io.Reader, maxSize int64) (io.Reader, error) {
+	n, err := grpcMessageHead(r, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return io.LimitReader(r, n), nil
+}
+
+// readGRPCMessage 将请求中的一个消息整个读入内存，只用于很小的消息，如健康检查
+func readGRPCMessage(r io.Reader, maxSize int64) ([]byte, error) {
+	n, err := grpcMessageHead(r, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, &grpcError{grpcInvalidArgument, i18n.Errorf("读取请求失败: %v", err)}
+	}
+	return msg, nil
+}
+
+// writeGRPCMessage 写出一个消息并立即发送
+func writeGRPCMessage(w io.Writer, msg []byte) error {
+	var head [5]byte
+	binary.BigEndian.PutUint32(head[1:], uint32(len(msg)))
+	if _, err := w.Write(append(head[:], msg...)); err != nil {
+		return err
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// grpcChunkWriter 将写入的内容攒够 grpcChunkSize 个字节后作为一个 ConvertResponse 发送
+type grpcChunkWriter struct {
+	w   io.Writer
+	buf []byte
+	// total 为写入的总字节数
+	total int64
+}
+
+func (c *grpcChunkWriter) Write(p []byte) (int, error) {
+	c.buf = append(c.buf, p...)
+	c.total += int64(len(p))
+	for len(c.buf) >= grpcChunkSize {
+		if err := c.send(c.buf[:grpcChunkSize]); err != nil {
+			return 0, err
+		}
+		c.buf = c.buf[grpcChunkSize:]
+	}
+	return len(p), nil
+}
+
+// flush 发送剩余的内容
+func (c *grpcChunkWriter) flush() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+	err := c.send(c.buf)
+	c.buf = nil
+	return err
+}
+
+func (c *grpcChunkWriter) send(chunk []byte) error {
+	var msg protowire.Encoder
+	msg.Raw(1, chunk)
+	return writeGRPCMessage(c.w, msg.Bytes())
+}
+
+// grpcHealthCheck 为 gRPC 标准健康检查服务 grpc.health.v1.Health 的 Check 方法，供负载均衡与 Kubernetes 的 grpc 探针使用
+const grpcHealthCheck = "/grpc.health.v1.Health/Check"
+
+// grpcHealth 实现 Check，服务只要能处理请求就返回 SERVING，不区分请求中的服务名
+func grpcHealth(w http.ResponseWriter, body io.Reader) error {
+	if _, err := readGRPCMessage(body, 1<<10); err != nil {
+		return err
+	}
+	// HealthCheckResponse 中 status = 1，取值 1 为 SERVING
+	var resp protowire.Encoder
+	resp.Varint(1, 1)
+	return writeGRPCMessage(w, resp.Bytes())
+}
+
+// grpcCode 返回错误对应的 gRPC 状态码
+func grpcCode(ctx context.Context, err error) int {
+	var ge *grpcError
+	switch {
+	case errors.As(err, &ge):
+		return ge.code
+	case errors.Is(err, errBusy):
+		return grpcUnavailable
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return grpcDeadlineExceeded
+	case ctx.Err() != nil:
+		return grpcCanceled
+	case errors.Is(err, xmind.ErrEncrypted):
+		return grpcFailedPrecondition
+	case errors.Is(err, xmind.ErrTooLarge):
+		return grpcResourceExhausted
+	case errors.Is(err, xmind.ErrNotZip), errors.Is(err, xmind.ErrNoContent), errors.Is(err, xmind.ErrBadJSON),
+		errors.Is(err, xmind.ErrBadXML), errors.Is(err, xmind.ErrUnsupported), errors.Is(err, xmind.ErrTooDeep):
+		return grpcInvalidArgument
+	}
+	return grpcInternal
+}
+
+// grpcTimeout 解析 grpc-timeout 请求头，如 10S、500m
+func grpcTimeout(v string) (time.Duration, bool) {
+	if len(v) < 2 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second, 'm': time.Millisecond, 'u': time.Microsecond, 'n': time.Nanosecond}
+	unit, ok := units[v[len(v)-1]]
+	return time.Duration(n) * unit, ok
+}
+
+// grpcEncodeMessage 按 gRPC 的要求对 grpc-message 中可见 ASCII 字符以外的字节与 % 进行百分号编码
+func grpcEncodeMessage(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// batchIndex 为 -index 生成的目录文件名
+const batchIndex = "README.md"
+
+// indexEntry 为目录中的一个文档，link 为相对于目录文件的路径，others 为 -sheet-regex 时其余画布的输出文件
+type indexEntry struct {
+	title  string
+	link   string
+	topics int
+	others []string
+}
+
+// writeIndex 在 -out-dir 指定的目录（未指定时为所有输出文件共同的目录）中写入链接到每个输出文件的 README.md，
+// 按路径排序，返回目录文件的路径；没有成功转换的文件时不写入，返回空字符串
+func writeIndex(ctx context.Context, r *runReport, opts convertOptions) (string, error) {
+	var outputs []string
+	for _, f := range r.Files {
+		for _, out := range f.outputs() {
+			if f.Error == "" && out != "-" && (opts.OutDir != "" || !isS3(out)) {
+				outputs = append(outputs, out)
+			}
+		}
+	}
+	if len(outputs) == 0 {
+		return "", nil
+	}
+	dir := opts.OutDir
+	if dir == "" {
+		var err error
+		if dir, err = commonDir(outputs); err != nil {
+			return "", withCode(exitWrite, i18n.Errorf("生成目录失败: %v", err))
+		}
+	}
+	index, err := outDirPath(dir, batchIndex, false)
+	if err != nil {
+		return "", err
+	}
+
+	var entries []indexEntry
+	for _, f := range r.Files {
+		if f.Error != "" || len(f.outputs()) == 0 || f.Output == "-" {
+			continue
+		}
+		var links []string
+		for _, out := range f.outputs() {
+			if sameFile(out, index) {
+				return "", withCode(exitUsage, i18n.Errorf("输出文件与 -index 生成的目录相同: %s", out))
+			}
+			links = append(links, indexLink(dir, out))
+		}
+		title := f.Title
+		if title == "" {
+			title = strings.TrimSuffix(path.Base(links[0]), path.Ext(links[0]))
+		}
+		entries = append(entries, indexEntry{title: title, link: links[0], topics: f.Topics, others: links[1:]})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].link < entries[j].link })
+
+	var b strings.Builder
+	name := filepath.Base(dir)
+	if isS3(dir) {
+		name = path.Base(strings.TrimSuffix(dir, "/"))
+	}
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	for _, e := range entries {
+		fmt.Fprintf(&b, "- [%s](%s)%s\n", e.title, linkEscaper.Replace(e.link), i18n.Sprintf("（%d 个节点）", e.topics))
+		for _, o := range e.others {
+			fmt.Fprintf(&b, "  - [%s](%s)\n", path.Base(o), linkEscaper.Replace(o))
+		}
+	}
+	if err := writeChunk(ctx, index, b.String(), opts); err != nil {
+		return "", err
+	}
+	opts.Cache.storeOutput(index)
+	return index, nil
+}
+
+// commonDir 返回本地路径 paths 共同所在的最深的目录，位于当前目录中时为相对路径
+func commonDir(paths []string) (string, error) {
+	var dir string
+	for i, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return "", err
+		}
+		if i == 0 {
+			dir = filepath.Dir(abs)
+			continue
+		}
+		for !within(dir, abs) && filepath.Dir(dir) != dir {
+			dir = filepath.Dir(dir)
+		}
+	}
+	if wd, err := os.Getwd(); err == nil && within(wd, dir) {
+		if rel, err := filepath.Rel(wd, dir); err == nil {
+			return rel, nil
+		}
+	}
+	return dir, nil
+}
+
+// within 判断绝对路径 p 是否为 dir 或位于 dir 中
+func within(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// indexLink 返回输出文件 out 相对于目录 dir 的链接路径
+func indexLink(dir, out string) string {
+	if isS3(dir) {
+		return strings.TrimPrefix(out, strings.TrimSuffix(dir, "/")+"/")
+	}
+	a, aerr := filepath.Abs(dir)
+	b, berr := filepath.Abs(out)
+	if aerr == nil && berr == nil {
+		if rel, err := filepath.Rel(a, b); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(out)
+}
+
+// sameFile 判断两个输出路径是否指向同一个文件
+func sameFile(a, b string) bool {
+	if isS3(a) || isS3(b) {
+		return a == b
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var infoCommand = &command{
+	Name:  "info",
+	Args:  "[参数] <文件>",
+	Short: "查看思维导图的画布、节点数、图标、标签与资源等概况",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var from string
+		var asJSON bool
+		fs.StringVar(&from, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("info 需要指定一个文件"))
+			}
+			wb, err := loadWorkbook(ctx, args[0], from)
+			if err != nil {
+				return err
+			}
+			info, err := inspectWorkbook(wb)
+			if err != nil {
+				return &fileError{path: wb.Path, err: withCode(exitParse, err)}
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			}
+			printInfo(os.Stdout, info)
+			return nil
+		}
+	},
+}
+
+// workbook 为读取到内存中的本地思维导图文件，供 info 等查看文件内容的命令使用
+type workbook struct {
+	Path   string
+	Format string
+	Size   int64
+	Sheets []xmind.Sheet
+	// data 为文件的内容，用于读取元数据与资源，目录形式的 MindNode bundle 为 nil
+	data []byte
+}
+
+// loadWorkbook 读取并解析本地文件 path，format 为 auto 时根据内容识别格式
+func loadWorkbook(ctx context.Context, path, format string) (*workbook, error) {
+	wb := &workbook{Path: path, Format: format}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, &fileError{path: path, err: i18n.Errorf("打开文件失败: %w", err)}
+	}
+	if !info.IsDir() {
+		if wb.data, err = os.ReadFile(path); err != nil {
+			return nil, &fileError{path: path, err: i18n.Errorf("打开文件失败: %w", err)}
+		}
+		wb.Size = int64(len(wb.data))
+	}
+	// 与 convert 相同由 ParseFileAsContext 解析，错误信息中带有文件名
+	wb.Sheets, err = xmind.ParseFileAsContext(ctx, path, format)
+	if err != nil {
+		return nil, &fileError{path: path, err: withCode(exitParse, err)}
+	}
+	if format == "auto" {
+		if wb.data == nil {
+			wb.Format = xmind.FormatOf(path)
+		} else if wb.Format, err = xmind.DetectFormat(bytes.NewReader(wb.data), wb.Size); err != nil {
+			return nil, &fileError{path: path, err: withCode(exitParse, err)}
+		}
+	}
+	return wb, nil
+}
+
+// zipped 判断文件是否为 .xmind 等压缩包，只有压缩包中有元数据与资源
+func (wb *workbook) zipped() bool {
+	return wb.data != nil && bytes.HasPrefix(wb.data, []byte("PK\x03\x04"))
+}
+
+// workbookInfo 为 info 命令输出的概况
+type workbookInfo struct {
+	File    string      `json:"file"`
+	Format  string      `json:"format"`
+	Size    int64       `json:"size"`
+	Creator string      `json:"creator,omitempty"`
+	Sheets  []sheetInfo `json:"sheets"`
+	Topics  int         `json:"topics"`
+	// Notes、Links 与 Images 为带有备注、链接与图片的节点数
+	Notes  int `json:"notes"`
+	Links  int `json:"links"`
+	Images int `json:"images"`
+	// Markers 与 Labels 为每个图标与标签被使用的次数，按次数从多到少排列
+	Markers []usage `json:"markers"`
+	Labels  []usage `json:"labels"`
+	// Resources 为文件中的资源数，ResourceSize 为其总字节数
+	Resources    int   `json:"resources"`
+	ResourceSize int64 `json:"resourceSize"`
+}
+
+type sheetInfo struct {
+	ID        string `json:"id,omitempty"`
+	Title     string `json:"title"`
+	RootTitle string `json:"rootTitle"`
+	Topics    int    `json:"topics"`
+	MaxDepth  int    `json:"maxDepth"`
+	Active    bool   `json:"active,omitempty"`
+}
+
+// usage 为一个图标或标签的使用次数
+type usage struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// inspectWorkbook 统计 wb 的概况
+func inspectWorkbook(wb *workbook) (workbookInfo, error) {
+	info := workbookInfo{File: wb.Path, Format: wb.Format, Size: wb.Size, Sheets: []sheetInfo{}, Markers: []usage{}, Labels: []usage{}}
+	var meta xmind.Metadata
+	if wb.zipped() {
+		ra := bytes.NewReader(wb.data)
+		var err error
+		if meta, err = xmind.ReadMetadata(ra, wb.Size); err != nil {
+			return info, err
+		}
+		info.Creator = strings.TrimSpace(meta.Creator.Name + " " + meta.Creator.Version)
+		res, err := xmind.Resources(ra, wb.Size)
+		if err != nil {
+			return info, err
+		}
+		info.Resources = len(res)
+		for _, r := range res {
+			info.ResourceSize += r.Size
+		}
+	}
+
+	markers, labels := map[string]int{}, map[string]int{}
+	for i := range wb.Sheets {
+		s := &wb.Sheets[i]
+		si := sheetInfo{ID: s.ID, Title: s.Title, RootTitle: s.RootTopic.Title, MaxDepth: sheetDepth(s),
+			Active: s.ID != "" && s.ID == meta.ActiveSheetID}
+		s.Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+			si.Topics++
+			if t.Notes != nil && t.Notes.Plain != nil && t.Notes.Plain.Content != "" {
+				info.Notes++
+			}
+			if t.Href != "" {
+				info.Links++
+			}
+			if t.Image != nil {
+				info.Images++
+			}
+			for _, m := range t.Markers {
+				markers[m.MarkerID]++
+			}
+			for _, l := range t.Labels {
+				labels[l]++
+			}
+			return nil
+		})
+		info.Topics += si.Topics
+		info.Sheets = append(info.Sheets, si)
+	}
+	info.Markers = append(info.Markers, usages(markers)...)
+	info.Labels = append(info.Labels, usages(labels)...)
+	return info, nil
+}
+
+// usages 将使用次数按从多到少排列，次数相同时按名称排列
+func usages(counts map[string]int) []usage {
+	var u []usage
+	for name, n := range counts {
+		u = append(u, usage{name, n})
+	}
+	sort.Slice(u, func(i, j int) bool {
+		if u[i].Count != u[j].Count {
+			return u[i].Count > u[j].Count
+		}
+		return u[i].Name < u[j].Name
+	})
+	return u
+}
+
+// printInfo 以给人看的格式输出概况
+func printInfo(w io.Writer, info workbookInfo) {
+	fmt.Fprintf(w, i18n.T("文件: %s\n"), info.File)
+	fmt.Fprintf(w, i18n.T("格式: %s\n"), info.Format)
+	if info.Size > 0 {
+		fmt.Fprintf(w, i18n.T("大小: %s\n"), formatBytes(info.Size))
+	}
+	if info.Creator != "" {
+		fmt.Fprintf(w, i18n.T("创建程序: %s\n"), info.Creator)
+	}
+	fmt.Fprintf(w, i18n.T("共 %d 个画布，%d 个节点；%d 个节点有备注，%d 个有链接，%d 个有图片\n\n"), len(info.Sheets), info.Topics, info.Notes, info.Links, info.Images)
+
+	var rows [][]string
+	for i, s := range info.Sheets {
+		mark := ""
+		if s.Active {
+			mark = "*"
+		}
+		rows = append(rows, []string{strconv.Itoa(i+1) + mark, s.Title, s.RootTitle, strconv.Itoa(s.Topics), strconv.Itoa(s.MaxDepth)})
+	}
+	printTable(w, []string{"序号", "画布", "根节点", "节点", "层数"}, rows)
+
+	if len(info.Markers) > 0 || len(info.Labels) > 0 || info.Resources > 0 {
+		fmt.Fprintln(w)
+	}
+	printUsages(w, "图标", info.Markers)
+	printUsages(w, "标签", info.Labels)
+	if info.Resources > 0 {
+		fmt.Fprintf(w, i18n.T("资源: %d 个，共 %s\n"), info.Resources, formatBytes(info.ResourceSize))
+	}
+}
+
+func printUsages(w io.Writer, title string, u []usage) {
+	if len(u) == 0 {
+		return
+	}
+	parts := make([]string, len(u))
+	for i, x := range u {
+		parts[i] = fmt.Sprintf("%s ×%d", x.Name, x.Count)
+	}
+	fmt.Fprintf(w, "%s: %s\n", i18n.T(title), strings.Join(parts, ", "))
+}
+
+// formatBytes 将字节数格式化为 KiB、MiB 等便于阅读的形式
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return i18n.Sprintf("%d 字节", n)
+	}
+	v, suffix := float64(n)/unit, "KiB"
+	for _, s := range []string{"MiB", "GiB"} {
+		if v < unit {
+			break
+		}
+		v, suffix = v/unit, s
+	}
+	return fmt.Sprintf("%.1f %s", v, suffix)
+}
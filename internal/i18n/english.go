@@ -0,0 +1,527 @@
+package i18n
+
+// english 为英文的消息目录
+var english = map[string]string{
+	"格式应为 key=value: %s": "expected key=value: %s",
+	"打开文件失败: %w":         "failed to open file: %w",
+	"没有找到可转换的文件":         "no convertible files found",
+	"正在转换 %s":            "converting %s",
+	"输出文件与输入文件相同: %s":    "output file is the same as the input: %s",
+	"已写入 %s":             "wrote %s",
+	"  画布 %d %q: %d 个节点": "  sheet %d %q: %d topics",
+	"%s 没有变化，跳过":         "%s is unchanged, skipping",
+	"输出文件已存在: %s，使用 --force 覆盖或 --backup 保留备份":    "output file already exists: %s (use --force to overwrite or --backup to keep a backup)",
+	"输出路径是一个目录: %s":                               "output path is a directory: %s",
+	"备份 %s 失败: %v":                                "failed to back up %s: %v",
+	"已将 %s 备份为 %s":                                "backed up %s as %s",
+	"写入输出文件失败: %w":                                "failed to write output file: %w",
+	"创建输出目录失败: %w":                                "failed to create output directory: %w",
+	"忽略无法读取的缓存 %s: %v":                            "ignoring unreadable cache %s: %v",
+	"写入剪贴板失败: %v %s":                              "failed to write to clipboard: %v %s",
+	"没有找到可用的剪贴板工具（需要 %s 之一）":                      "no clipboard tool found (need one of %s)",
+	"写入剪贴板失败: %v":                                 "failed to write to clipboard: %v",
+	"打开剪贴板失败: %v":                                 "failed to open clipboard: %v",
+	"清空剪贴板失败: %v":                                 "failed to empty clipboard: %v",
+	"未知命令: %s，使用 \"xmindtomarkdown help\" 查看所有命令": "unknown command: %s, run \"xmindtomarkdown help\" to list all commands",
+	"不支持的错误信息格式: %s":                              "unsupported error format: %s",
+	"在交互式终端中运行时，结束前等待按回车键退出":                      "when running in an interactive terminal, wait for Enter before exiting",
+	"错误信息的输出格式: text, json":                       "output format of error messages: text, json",
+	"配置文件路径，默认为 ~/.config/xmind2md/config.yaml":   "path of the configuration file, defaults to ~/.config/xmind2md/config.yaml",
+	"提示与错误信息使用的语言（zh 或 en），默认根据系统的区域设置选择":         "language of prompts and error messages (zh or en), chosen from the system locale by default",
+	"只输出错误信息":                                     "only print errors",
+	"输出正在处理的文件与转换时丢失的内容，可重复指定":                    "print the files being processed and content dropped during conversion, may be repeated",
+	"输出更详细的诊断信息，同 -v -v":                          "print more detailed diagnostics, same as -v -v",
+	"用法: xmindtomarkdown <命令> [参数]":               "Usage: xmindtomarkdown <command> [flags]",
+	"命令:": "Commands:",
+	"未指定命令时执行 %s，例如 `xmindtomarkdown a.xmind`\n":   "Runs %s when no command is given, e.g. `xmindtomarkdown a.xmind`\n",
+	"使用 \"xmindtomarkdown help <命令>\" 查看命令的参数":     "Run \"xmindtomarkdown help <command>\" to see the flags of a command",
+	"用法: xmindtomarkdown %s %s\n\n":                "Usage: xmindtomarkdown %s %s\n\n",
+	"%s\n\n参数:\n":                                  "%s\n\nFlags:\n",
+	"\n参数也可以通过环境变量 %s<参数名> 设置，如 --out-dir 对应 %s\n": "\nFlags can also be set with environment variables %s<FLAG>, e.g. --out-dir is %s\n",
+	"显示版本信息与支持的格式":                                 "show version information and supported formats",
+	"[命令]":                                         "[command]",
+	"显示命令的帮助信息":                                    "show help for a command",
+	"未知命令: %s":                                     "unknown command: %s",
+	"生成 shell 补全脚本":                                "generate shell completion scripts",
+	"必须指定 shell: %s":                               "a shell is required: %s",
+	"不支持的 shell: %s，可选: %s":                        "unsupported shell: %s, available: %s",
+	"# xmindtomarkdown 的 bash 补全脚本":                "# bash completion for xmindtomarkdown",
+	"# xmindtomarkdown 的 zsh 补全脚本":                 "# zsh completion for xmindtomarkdown",
+	"命令":                                           "command",
+	"值":                                            "value",
+	"文件":                                           "file",
+	"参数":                                           "argument",
+	"# xmindtomarkdown 的 fish 补全脚本":                "# fish completion for xmindtomarkdown",
+	"# xmindtomarkdown 的 PowerShell 补全脚本":          "# PowerShell completion for xmindtomarkdown",
+	"读取配置文件失败: %v":                                 "failed to read configuration file: %v",
+	"解析配置文件 %s 失败: %v":                             "failed to parse configuration file %s: %v",
+	"配置文件 %s 中的 %s 应为参数的映射":                        "%s in configuration file %s should be a mapping of flags",
+	"配置文件 %s: %v":                                  "configuration file %s: %v",
+	"未知的设置项 %s%s":                                  "unknown setting %s%s",
+	"%s%s.%s 应为单个值":                                "%s%s.%s should be a single value",
+	"%s%s 的值 %q 无效: %v":                            "invalid value %[3]q for %[1]s%[2]s: %[4]v",
+	"第 %d 行: 不能使用 Tab 缩进":                          "line %d: tabs cannot be used for indentation",
+	"第 %d 行: 缩进错误":                                 "line %d: bad indentation",
+	"第 %d 行: 顶层应为映射":                               "line %d: top level should be a mapping",
+	"第 %d 行: 映射中不能出现列表项":                           "line %d: list items cannot appear in a mapping",
+	"第 %d 行: 应为 key: value 形式":                     "line %d: expected key: value",
+	"第 %d 行: %v":                                   "line %d: %v",
+	"第 %d 行: 列表项只能是字符串":                            "line %d: list items can only be strings",
+	"无效的字符串 %s":                                    "invalid string %s",
+	"无效的列表 %s":                                     "invalid list %s",
+	"列表项只能是字符串":                                    "list items can only be strings",
+	"[参数] [文件...]":                                 "[flags] [files...]",
+	"将思维导图转换为 Markdown 等格式（默认命令）":                  "convert mind maps to Markdown and other formats (default command)",
+	"指定要转换的思维导图文件、目录、http(s) 地址、s3://bucket/key 或 gdrive://<文件ID>，可重复指定多个，- 表示从标准输入读取": "mind map file, directory, http(s) URL, s3://bucket/key or gdrive://<file ID> to convert, may be repeated, - reads from standard input",
+	"指定输出文件路径（本地路径或 s3://bucket/key），- 表示输出到标准输出，默认与输入文件同名":                            "output file path (local path or s3://bucket/key), - writes to standard output, defaults to the input name",
+	"同 -o":        "same as -o",
+	"下载远程文件的超时时间": "timeout for downloading remote files",
+	"允许下载的最大字节数":  "maximum number of bytes to download",
+	"下载远程文件时附加的认证请求头，如 \"Authorization: Bearer xxx\"":             "authentication header sent when downloading remote files, e.g. \"Authorization: Bearer xxx\"",
+	"访问 Google Drive（gdrive://<文件ID> 或 Drive 分享链接）使用的 OAuth 访问令牌": "OAuth access token for Google Drive (gdrive://<file ID> or Drive share links)",
+	"转换结束后输出转换报告，格式: json":                                        "print a conversion report when finished, format: json",
+	"将 JSON 格式的转换报告写入指定文件":                                        "write the JSON conversion report to the given file",
+	"不在终端中显示转换进度":                                                 "do not show conversion progress in the terminal",
+	"只解析输入并列出将要新建或覆盖的文件，不写入任何内容":                                  "only parse the inputs and list the files that would be created or overwritten, write nothing",
+	"将转换结果复制到系统剪贴板，同时指定 -o 或 -out-dir 时才写入文件":                     "copy the result to the system clipboard, files are written only when -o or -out-dir is given",
+	"转换结束后在文件管理器中打开输出文件夹":                                         "open the output folder in the file manager when finished",
+	"在终端中渲染转换得到的 Markdown，同时指定 -o 或 -out-dir 时才写入文件":              "render the resulting Markdown in the terminal, files are written only when -o or -out-dir is given",
+	"转换目录时只转换与模式匹配的文件（如 \"**/*.xmind\"），可重复指定":                    "when converting directories, only convert files matching the pattern (e.g. \"**/*.xmind\"), may be repeated",
+	"转换目录时跳过与模式匹配的文件或目录（如 \"archive/**\"），可重复指定":                  "when converting directories, skip files or directories matching the pattern (e.g. \"archive/**\"), may be repeated",
+	"输入格式: ": "input format: ",
+	"输出格式: ": "output format: ",
+	"同 -to":  "same as -to",
+	"指定输出目录（本地目录或 s3://bucket/prefix），转换目录时会在其中重建相对目录结构":                                      "output directory (local directory or s3://bucket/prefix), the relative layout of converted directories is recreated inside it",
+	"输出文件名模板，如 \"{{.Base}}-{{.Sheet}}-{{.Date}}{{.Ext}}\"，可用字段: Base, Sheet, Date, Ext, Slug": "output file name template, e.g. \"{{.Base}}-{{.Sheet}}-{{.Date}}{{.Ext}}\", fields: Base, Sheet, Date, Ext, Slug",
+	"将图标输出为标题前的文本，格式为 图标ID=文本（如 priority-1=🔴），可重复指定":                                          "output a marker as text before titles, as markerID=text (e.g. priority-1=🔴), may be repeated",
+	"覆盖已存在的输出文件":             "overwrite existing output files",
+	"覆盖已存在的输出文件前保留一份带时间戳的备份": "keep a timestamped backup before overwriting existing output files",
+	"即使输入没有变化也重新转换":          "convert again even if the input is unchanged",
+	"不支持的报告格式: %s":           "unsupported report format: %s",
+	"-clipboard 只支持文本格式的输出":  "-clipboard only supports text output formats",
+	"已复制到剪贴板":                "copied to clipboard",
+	"-o 只能用于单个输入文件":          "-o can only be used with a single input file",
+	"-o 与 -out-dir 不能同时使用":   "-o and -out-dir cannot be used together",
+	"-clipboard 只能用于单个输入文件":  "-clipboard can only be used with a single input file",
+	"文件没有变化，已跳过: %s\n":       "file unchanged, skipped: %s\n",
+	"文件已生成: %s\n":            "file generated: %s\n",
+	"（没有变化）":                 " (unchanged)",
+	"（仅预览）":                  "(preview only)",
+	"状态":                     "Status",
+	"输入":                     "Input",
+	"输出 / 错误":                "Output / Error",
+	"\n共 %d 个文件，成功 %d 个（其中 %d 个没有变化），失败 %d 个\n": "\n%d files, %d succeeded (%d unchanged), %d failed\n",
+	"部分文件转换失败":                                        "some files failed to convert",
+	"必须指定思维导图文件路径":                                    "a mind map file path is required",
+	"请输入思维导图文件路径: ":                                   "Enter the path of the mind map file: ",
+	"读取输入失败: %v":                                      "failed to read input: %v",
+	"读取标准输入失败: %v":                                    "failed to read standard input: %v",
+	"标准输入为空，必须指定思维导图文件路径":                             "standard input is empty, a mind map file path is required",
+	"环境变量 %s 的值 %q 无效: %v":                            "invalid value %[2]q for environment variable %[1]s: %[3]v",
+	"下载文件失败: %w":                                      "failed to download file: %w",
+	"下载文件失败: %s":                                      "failed to download file: %s",
+	"文件大小超过限制 (%d 字节)":                                "file exceeds the size limit (%d bytes)",
+	"缺少 Google Drive 文件 ID":                           "missing Google Drive file ID",
+	"访问 Google Drive 需要通过 -drive-token 指定 OAuth 访问令牌": "accessing Google Drive requires an OAuth access token given with -drive-token",
+	"解析 Google Drive 文件信息失败: %v":                      "failed to parse Google Drive file metadata: %v",
+	"无效的 Google Drive 文件 ID: %v":                      "invalid Google Drive file ID: %v",
+	"展开 %s 失败: %v":                                    "failed to expand %s: %v",
+	"没有与 %s 匹配的文件":                                    "no files match %s",
+	"不支持的语言: %s，可选: %s":                               "unsupported language: %s, available: %s",
+	"按回车键退出...":                                       "Press Enter to exit...",
+	"输入 o 并回车打开输出文件夹，直接按回车键退出...":                     "Enter o to open the output folder, or press Enter to exit...",
+	"无效的文件名模板: %v":                                    "invalid file name template: %v",
+	"生成文件名失败: %v":                                     "failed to generate file name: %v",
+	"文件名模板生成的文件名无效: %q":                               "file name template produced an invalid file name: %q",
+	"输出位于 %d 个文件夹中，只打开前 %d 个":                         "outputs are in %d folders, opening only the first %d",
+	"创建输出文件失败: %w":                                    "failed to create output file: %w",
+	"[参数] [文件]":                                       "[flags] [file]",
+	"在终端中浏览思维导图，勾选要导出的画布与分支并选择输出格式": "browse a mind map in the terminal, tick the sheets and branches to export and choose the output format",
+	"默认的输出格式: ":           "default output format: ",
+	"默认的输出文件路径，默认与输入文件同名": "default output file path, defaults to the input name",
+	"pick 只能打开一个文件":       "pick can only open one file",
+	"pick 需要在交互式终端中运行":    "pick must be run in an interactive terminal",
+	"画布 %d":               "Sheet %d",
+	"%s（%s）":              "%s (%s)",
+	"命令:\n  编号          勾选或取消勾选节点及其所有子节点\n  +编号 / -编号  展开或折叠节点，单独的 + / - 展开或折叠全部\n  a / n         全选 / 全不选\n  f 格式        设置输出格式（%s）\n  o 路径        设置输出文件路径\n  w 或回车      导出并退出\n  q             退出，不导出\n  ?             显示本帮助\n": "Commands:\n  number        tick or untick a topic and all its subtopics\n  +number / -number  expand or collapse a topic, a lone + / - expands or collapses everything\n  a / n         tick all / untick all\n  f format      set the output format (%s)\n  o path        set the output file path\n  w or Enter    export and quit\n  q             quit without exporting\n  ?             show this help\n",
+	"输出: %s（%s） > ":               "Output: %s (%s) > ",
+	"无效的命令: %s，输入 ? 查看帮助":         "invalid command: %s, enter ? for help",
+	"（分离）":                        " (detached)",
+	"没有勾选任何节点":                    "nothing is ticked",
+	"%s 已存在，是否覆盖？(y/N) ":          "%s already exists, overwrite? (y/N) ",
+	"无法识别的压缩包格式":                  "unrecognized archive format",
+	"读取文件失败: %v":                  "failed to read file: %v",
+	"无法识别的文件格式":                   "unrecognized file format",
+	"不是有效的压缩包":                    "not a valid zip archive",
+	"没有找到思维导图内容":                  "no mind map content found",
+	"解析 XML 失败: %v":               "failed to parse XML: %v",
+	"mm 文件中没有任何节点":                "no topics in the mm file",
+	"读取 contents.xml 失败: %w":      "failed to read contents.xml: %w",
+	"暂不支持二进制格式的 contents.xml":     "binary contents.xml is not supported yet",
+	"解析 contents.xml 失败: %v":      "failed to parse contents.xml: %v",
+	"MindNode 文件中没有任何节点":          "no topics in the MindNode file",
+	"opml 文件中没有任何节点":              "no topics in the opml file",
+	"%s 是一个目录":                    "%s is a directory",
+	"不支持的输入格式: %s":                "unsupported input format: %s",
+	"打开 %s 失败: %v":                "failed to open %s: %v",
+	"读取 %s 失败: %v":                "failed to read %s: %v",
+	"在压缩包中未找到 %s":                 "%s not found in the archive",
+	"smmx 文件中没有任何节点":              "no topics in the smmx file",
+	"读取文本失败: %v":                  "failed to read text: %v",
+	"文本中没有任何节点":                   "no topics in the text",
+	"%d 个节点的备注未输出":                "notes of %d topics were not output",
+	"%d 个节点的标签未输出":                "labels of %d topics were not output",
+	"%d 张图片未输出":                   "%d images were not output",
+	"%d 个图标没有映射为文本，未输出":           "%d markers are not mapped to text and were not output",
+	"%d 个节点的链接未输出":                "links of %d topics were not output",
+	"不支持的输出格式: %s":                "unsupported output format: %s",
+	"打开 content.json 失败: %v":      "failed to open content.json: %v",
+	"在 xmind 文件中未找到 content.json": "content.json not found in the xmind file",
+	"读取 content.json 失败: %v":      "failed to read content.json: %v",
+	"解析 JSON 失败: %v":              "failed to parse JSON: %v",
+	"生成 content.json 失败: %v":      "failed to generate content.json: %v",
+	"写入 %s 失败: %v":                "failed to write %s: %v",
+	"打开 content.xml 失败: %v":       "failed to open content.xml: %v",
+	"，失败 %d":                      ", %d failed",
+	"新建":                          "create",
+	"覆盖":                          "overwrite",
+	"上传":                          "upload",
+	"标准输出":                        "stdout",
+	"跳过":                          "skip",
+	"操作":                          "Action",
+	"画布":                          "Sheets",
+	"节点":                          "Topics",
+	"\n共 %d 个文件，将新建 %d 个，覆盖 %d 个，跳过 %d 个，失败 %d 个（未写入任何文件）\n": "\n%d files: %d to create, %d to overwrite, %d to skip, %d failed (nothing was written)\n",
+	"写入报告失败: %v":                         "failed to write report: %v",
+	"无效的 S3 地址: %s，格式应为 s3://bucket/key": "invalid S3 address: %s, expected s3://bucket/key",
+	"上传到 S3 失败: %v":                      "failed to upload to S3: %v",
+	"上传到 S3 失败: %s %s":                   "failed to upload to S3: %s %s",
+	"访问 S3 需要设置 AWS_ACCESS_KEY_ID 与 AWS_SECRET_ACCESS_KEY 环境变量": "accessing S3 requires the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables",
+	"无效的 S3 服务地址: %v":     "invalid S3 endpoint: %v",
+	"无效的 S3 地址: %v":       "invalid S3 address: %v",
+	"无效的地址: %v":           "invalid address: %v",
+	"不是远程地址: %s":          "not a remote address: %s",
+	"认证请求头格式应为 \"名称: 值\"": "authentication header should be \"Name: value\"",
+	"提交: %s\n":            "Commit: %s\n",
+	"构建时间: %s\n":          "Built: %s\n",
+	"Go 版本: %s %s/%s\n":   "Go version: %s %s/%s\n",
+	"输入格式: %s\n":          "Input formats: %s\n",
+	"输出格式: %s\n":          "Output formats: %s\n",
+	"读取目录失败: %v":          "failed to read directory: %v",
+	"[参数] <目录...>":        "[flags] <directories...>",
+	"监视目录，自动转换新增或修改的思维导图":                "watch directories and convert new or modified mind maps automatically",
+	"检查文件变化的间隔":                          "interval between checks for changes",
+	"文件停止变化多久后才开始转换，避免转换保存到一半的文件":        "how long a file must stop changing before it is converted, to avoid converting half-saved files",
+	"必须指定要监视的目录":                         "a directory to watch is required",
+	"打开目录失败: %w":                         "failed to open directory: %w",
+	"不是目录: %s":                           "not a directory: %s",
+	"正在监视 %d 个目录，按 Ctrl+C 退出":            "watching %d directories, press Ctrl+C to exit",
+	"已转换 %s -> %s":                       "converted %s -> %s",
+	"最多输出的层数，根节点为第 1 层，0 表示不限制":          "maximum number of levels to output, the root is level 1, 0 means no limit",
+	"在因 -max-depth 被截断的节点下输出“…（还有 n 层）”": "output \"… (n more levels)\" under topics cut off by -max-depth",
+	"-max-depth 不能为负数":                   "-max-depth cannot be negative",
+	"Markdown 中根节点的标题级别（1-6），子节点依次递增，如 2 表示根节点为 h2、子节点从 h3 开始": "heading level of the root in Markdown (1-6), children go one level deeper each, e.g. 2 makes the root h2 and children start at h3",
+	"-heading-start 应为 1 到 6 之间的整数": "-heading-start should be an integer between 1 and 6",
+	"Markdown 中叶子节点的输出方式: ":         "how leaf topics are written in Markdown: ",
+	"不支持的叶子节点输出方式: %s，可选: %s":       "unsupported leaf style: %s, available: %s",
+	"Markdown 列表项的标记: ":             "bullet marker of Markdown list items: ",
+	"Markdown 列表的缩进: ":              "indentation of Markdown lists: ",
+	"不支持的列表项标记: %s，可选: %s":          "unsupported bullet marker: %s, available: %s",
+	"不支持的列表缩进: %s，可选: %s":           "unsupported list indentation: %s, available: %s",
+	"文本格式输出使用的换行符: ":                "line ending of text output: ",
+	"在文本格式输出的开头写入 UTF-8 BOM":        "write a UTF-8 BOM at the start of text output",
+	"不支持的换行符: %s，可选: %s":            "unsupported line ending: %s, available: %s",
+	"Markdown 中节点标题特殊字符的转义方式: ":     "how special characters in topic titles are escaped in Markdown: ",
+	"不支持的转义方式: %s，可选: %s":           "unsupported escape mode: %s, available: %s",
+	"无效的正则表达式 %q: %v":               "invalid regular expression %q: %v",
+	"只输出标题与正则表达式匹配的节点及其子节点（上级节点同样保留），可重复指定":                     "only output topics whose title matches the regular expression, with their subtopics (ancestors are kept too), may be repeated",
+	"不输出标题与正则表达式匹配的节点及其子节点（如 \"^(内部|Draft)$\"），可重复指定":           "do not output topics whose title matches the regular expression, nor their subtopics (e.g. \"^(内部|Draft)$\"), may be repeated",
+	"只输出带有该图标的节点及其子节点（如 flag-red，flag 匹配所有旗帜），可重复指定":            "only output topics carrying the marker, with their subtopics (e.g. flag-red, flag matches all flags), may be repeated",
+	"只输出带有该标签的节点及其子节点，可重复指定":                                    "only output topics carrying the label, with their subtopics, may be repeated",
+	"去掉标题为空的节点，子树全部为空时一并去掉，否则子节点提升一级":                           "drop topics with empty titles, together with their subtree when it is all empty, otherwise their subtopics move up one level",
+	"同一节点下子节点的排列方式: ":                                           "order of sibling topics: ",
+	"不支持的排列方式: %s，可选: %s":                                       "unsupported sort order: %s, available: %s",
+	"将 Markdown 输出按该层级的节点拆分为多个文件（根节点为第 1 层），文件之间带有上一部分与下一部分的链接": "split Markdown output into several files at topics of this level (the root is level 1), with links to the previous and next part",
+	"拆分 Markdown 输出时每个文件尽量不超过的字节数，单独指定时按第 2 层拆分":                "size in bytes each file should stay under when splitting Markdown output, splits at level 2 when given alone",
+	"-chunk-level 与 -max-file-size 不能为负数":                       "-chunk-level and -max-file-size cannot be negative",
+	"-chunk-level 至少为 2，根节点所在的第 1 层不能拆分":                        "-chunk-level must be at least 2, level 1 holding the root cannot be split",
+	"已删除多余的 %s":                                      "removed leftover %s",
+	"-merge 需要用 -o 指定合并后的输出文件":                       "-merge requires -o to name the merged output file",
+	"-merge 不能与 -out-dir、-preview 或 -clipboard 同时使用": "-merge cannot be used with -out-dir, -preview or -clipboard",
+	"已将 %d 个文件合并到: %s\n":                             "merged %d files into: %s\n",
+	"将所有输入合并写入 -o 指定的一个文件，Markdown 中每个文件成为一个小节并在开头生成目录": "merge all inputs into the single file given with -o, in Markdown each file becomes a section listed in a table of contents at the top",
+	"批量转换时同时转换的文件数，0 表示与 CPU 核数相同":                      "number of files converted at the same time in batch runs, 0 uses one per CPU core",
+	"输出格式缺少名称或 Renderer":          "output format is missing a name or Renderer",
+	"输出格式 %s 已被注册":                "output format %s is already registered",
+	"在 Markdown 中将节点备注输出为节点下的引用块": "write topic notes as blockquotes under the topic in Markdown",
+	"已中断":          "interrupted",
+	"第 %d 个画布: %s": "sheet %d: %s",
+	"文件已设置密码，请在 XMind 中取消密码后再转换":          "the file is password-protected, remove the password in XMind before converting",
+	"%s 已加密，请先取消文件的密码":                    "%s is encrypted, remove the file's password first",
+	"文件中缺少资源 %s":                          "resource %s is missing from the file",
+	"解析 JSON 失败: 最外层不是数组":                 "failed to parse JSON: the top level is not an array",
+	"节点超过 %d 层":                           "topics are nested more than %d levels deep",
+	"不是中间格式文件":                            "not an intermediate representation file",
+	"不支持的中间格式版本: %d（支持 1 至 %d）":           "unsupported intermediate representation version: %d (supported: 1 to %d)",
+	"中间格式文件中没有任何画布":                       "the intermediate representation file contains no sheets",
+	"template 格式需要指定模板":                   "the template format requires a template",
+	"无效的模板: %v":                           "invalid template: %v",
+	"执行模板失败: %v":                          "failed to execute template: %v",
+	"-to template 需要同时用 -template 指定模板文件": "-to template requires a template file given with -template",
+	"-template 不能与 -to %s 同时使用":           "-template cannot be used with -to %s",
+	"读取模板失败: %v":                          "failed to read template: %v",
+	"使用 Go text/template 模板文件自定义输出内容，输出文件的扩展名取自模板文件名，如 report.html.tmpl 输出 .html": "customize the output with a Go text/template file; the output extension comes from the template name, e.g. report.html.tmpl writes .html",
+	"使用输出格式插件 %s":                   "using output format plugin %s",
+	"启动插件 %s 失败: %v":                "failed to start plugin %s: %v",
+	"插件 %s 执行失败: %v %s":             "plugin %s failed: %v %s",
+	"读取转换脚本失败: %v":                  "failed to read transform script: %v",
+	"执行转换脚本 %s 需要 %s，但没有在 PATH 中找到": "running transform script %s requires %s, which was not found in PATH",
+	"转换脚本 %s 执行失败: %v %s":           "transform script %s failed: %v %s",
+	"转换脚本 %s 的输出无效: %v":             "transform script %s produced invalid output: %v",
+	"在输出前用脚本改写节点（重命名、删除、添加标签或移动），脚本从标准输入读取中间格式并输出改写后的中间格式，可重复指定": "rewrite topics with a script before output (rename, drop, tag or move); the script reads the intermediate representation on stdin and writes the rewritten one to stdout; repeatable",
+	"convert 的第一个参数应为文件内容的 Uint8Array": "the first argument of convert must be a Uint8Array with the file contents",
+	"解析选项失败: %v": "failed to parse options: %v",
+	"gRPC 服务的监听地址，如 :50051，接口定义见 proto/xmindtomarkdown/v1/converter.proto": "listen address of the gRPC service, e.g. :50051; see proto/xmindtomarkdown/v1/converter.proto",
+	"每个请求允许上传的最大字节数":                                                       "maximum upload size in bytes per request",
+	"serve 不接受位置参数: %s":                                                    "serve does not take positional arguments: %s",
+	"监听 %s 失败: %v":                                                         "failed to listen on %s: %v",
+	"正在停止服务":                                                               "stopping the service",
+	"只接受 gRPC 请求":                                                          "only gRPC requests are accepted",
+	"未知的方法: %s":                                                            "unknown method: %s",
+	"gRPC %s 失败: %s":                                                       "gRPC %s failed: %s",
+	"读取请求失败: %v":                                                           "failed to read request: %v",
+	"不支持压缩的消息":                                                             "compressed messages are not supported",
+	"请求超过 %d 字节":                                                           "request exceeds %d bytes",
+	"%s 服务已启动: %s":                                                         "%s service listening on %s",
+	"HTTP 接口的监听地址，如 :8080，接口说明见 GET /openapi.json": "listen address of the HTTP API, e.g. :8080; see GET /openapi.json",
+	"只支持 POST 请求":                "only POST requests are supported",
+	"未知的接口: %s":                  "unknown endpoint: %s",
+	"HTTP %s 失败: %v":             "HTTP %s failed: %v",
+	"请求中没有上传文件":                  "the request contains no uploaded file",
+	"无效的参数值: %s":                 "invalid parameter value: %s",
+	"以服务方式提供转换接口（HTTP、gRPC、MCP）": "serve conversion APIs (HTTP, gRPC, MCP)",
+	"必须指定 -http、-grpc 或 -mcp":    "one of -http, -grpc or -mcp must be given",
+	"在标准输入输出上提供 MCP（Model Context Protocol）服务，供 AI 助手读取思维导图": "serve MCP (Model Context Protocol) on standard input and output so AI assistants can read mind maps",
+	"MCP 服务已启动": "MCP service started",
+	"未知的工具: %s": "unknown tool: %s",
+	"未知的工具":     "unknown tool",
+	"缺少参数: %s":  "missing argument: %s",
+	"%d. %s（根节点: %s，%d 个节点，%d 层）\n": "%d. %s (root: %s, %d topics, %d levels)\n",
+	"没有找到节点: %s":                    "topic not found: %s",
+	"没有找到画布: %s":                    "sheet not found: %s",
+	"压缩包中有 %d 个文件，超过限制 %d":          "the archive contains %d files, exceeding the limit of %d",
+	"%s 解压后超过 %d 字节":                "%s exceeds %d bytes when decompressed",
+	"JSON 嵌套超过 %d 层":                "JSON is nested deeper than %d levels",
+	"节点总数超过 %d":                     "more than %d topics",
+	"节点 %q 的标题或备注超过 %d 字节":          "the title or note of topic %q exceeds %d bytes",
+	"文件超过解析限制":                      "file exceeds a parsing limit",
+	"压缩包中允许的最大文件数，0 表示不限制":          "maximum number of files in an archive, 0 for no limit",
+	"从压缩包中读取的单个文件解压后的最大字节数，0 表示不限制":                                                           "maximum decompressed size in bytes of a file read from an archive, 0 for no limit",
+	"content.json 与中间格式中 JSON 的最大嵌套层数，0 表示不限制":                                                "maximum nesting depth of JSON in content.json and the intermediate format, 0 for no limit",
+	"一个文件中允许的最大节点总数，0 表示不限制":                                                                  "maximum total number of topics in a file, 0 for no limit",
+	"单个节点的标题或备注的最大字节数，0 表示不限制":                                                                "maximum size in bytes of a topic title or note, 0 for no limit",
+	"-max-entries、-max-decompressed-size、-max-json-depth、-max-topics 与 -max-topic-text 不能为负数": "-max-entries, -max-decompressed-size, -max-json-depth, -max-topics and -max-topic-text must not be negative",
+	"不安全的路径":       "unsafe path",
+	"不安全的资源路径: %q": "unsafe resource path: %q",
+	"不安全的资源路径: %q（%s 是符号链接或不是目录）": "unsafe resource path: %q (%s is a symbolic link or not a directory)",
+	"资源 %s 是符号链接":     "resource %s is a symbolic link",
+	"警告: ":            "warning: ",
+	"保存缓存失败: %v":      "failed to save cache: %v",
+	"打开文件夹 %s 失败: %v": "failed to open folder %s: %v",
+	"不支持的日志格式: %s":    "unsupported log format: %s",
+	"诊断信息（-v 等）的输出格式: text, json（每行一个 JSON 对象，带有级别与字段）":                              "format of diagnostic messages (-v etc.): text, json (one JSON object per line with level and fields)",
+	"单独提供 GET /metrics（Prometheus 格式）与 GET /healthz 的监听地址，如 :9090，HTTP 接口本身也提供这两个路径": "address serving GET /metrics (Prometheus format) and GET /healthz separately, e.g. :9090; the HTTP interface serves both paths as well",
+	"同时处理的最大转换请求数，其余请求排队等待，等待超过 -request-timeout 时返回服务繁忙":                            "maximum number of conversions processed at the same time; other requests wait in a queue and get a busy error after -request-timeout",
+	"每个请求从开始读取到完成转换的最长时间（包括上传与排队），0 表示不限制":                                           "maximum time per request from the start of reading to the end of conversion (including upload and queueing), 0 for no limit",
+	"-max-request-size 与 -max-concurrent 必须大于 0，-request-timeout 不能为负数":              "-max-request-size and -max-concurrent must be greater than 0, -request-timeout must not be negative",
+	"创建临时目录失败: %v": "failed to create temporary directory: %v",
+	"服务繁忙，请稍后重试":   "the service is busy, please retry later",
+	"读取输入或写入输出时遇到临时性错误（如网络超时、文件被其他程序锁定）后最多重试的次数，0 表示不重试": "maximum number of retries after a transient error while reading input or writing output (such as a network timeout or a file locked by another program), 0 to disable retries",
+	"第一次重试前等待的时间，之后每次加倍":                    "time to wait before the first retry, doubled after each retry",
+	"-jobs、-retries 与 -retry-backoff 不能为负数": "-jobs, -retries and -retry-backoff must not be negative",
+	"%s: %v，正在第 %d 次尝试":                     "%s: %v, starting attempt %d",
+	"没有开始处理: %v":                            "not started: %v",
+	"查看思维导图的画布、节点数、图标、标签与资源等概况":             "show an overview of a mind map: sheets, topic counts, markers, labels and resources",
+	"以 JSON 格式输出":                           "print as JSON",
+	"info 需要指定一个文件":                         "info requires exactly one file",
+	"文件: %s\n":                              "File: %s\n",
+	"格式: %s\n":                              "Format: %s\n",
+	"大小: %s\n":                              "Size: %s\n",
+	"创建程序: %s\n":                            "Created by: %s\n",
+	"共 %d 个画布，%d 个节点；%d 个节点有备注，%d 个有链接，%d 个有图片\n\n": "%d sheets, %d topics; %d with notes, %d with links, %d with images\n\n",
+	"序号":                      "No.",
+	"根节点":                     "Root topic",
+	"层数":                      "Depth",
+	"图标":                      "Markers",
+	"标签":                      "Labels",
+	"资源: %d 个，共 %s\n":         "Resources: %d, %s in total\n",
+	"%d 字节":                   "%d bytes",
+	"解析 metadata.json 失败: %v": "failed to parse metadata.json: %v",
+	"按第一层分支统计节点数、字数与任务完成情况": "count topics, words and task progress per top-level branch",
+	"stats 需要指定一个文件":        "stats requires exactly one file",
+	"画布 %d: %s\n":           "Sheet %d: %s\n",
+	"合计":                    "Total",
+	"分支":                    "Branch",
+	"叶子":                    "Leaves",
+	"词数":                    "Words",
+	"字符":                    "Chars",
+	"任务":                    "Tasks",
+	"比较两个思维导图，列出新增、删除、移动与改名的节点":         "Compare two mind maps and list added, removed, moved and retitled topics",
+	"[参数] <旧文件> <新文件>":                  "[flags] <old file> <new file>",
+	"有差异时以退出码 11 结束，便于在脚本与 CI 中判断":      "exit with code 11 when the files differ, for use in scripts and CI",
+	"diff 需要指定旧文件与新文件":                  "diff needs an old file and a new file",
+	"两个文件的内容不同":                         "the files differ",
+	"# 思维导图差异: %s → %s\n\n":             "# Mind map diff: %s → %s\n\n",
+	"两个文件的节点没有差异。\n":                    "The files have no topic differences.\n",
+	"新增 %d 处，删除 %d 处，移动 %d 个，改名 %d 个\n": "%d added, %d removed, %d moved, %d retitled\n",
+	"新增":          "Added",
+	"删除":          "Removed",
+	"移动":          "Moved",
+	"改名":          "Retitled",
+	"（含 %d 个子节点）": " (with %d subtopics)",
+	"将多个思维导图的画布合并为一个 .xmind 文件":            "Merge the sheets of several mind maps into one .xmind file",
+	"[参数] -o <输出文件> <文件>...":               "[flags] -o <output file> <file>...",
+	"输出的 .xmind 文件，- 表示标准输出":               "output .xmind file, - for standard output",
+	"将标题与根节点相同的画布合并为一个，并去掉内容完全相同的第一层分支":    "combine sheets with the same title and root topic, dropping identical top-level branches",
+	"merge 需要指定至少一个文件":                     "merge needs at least one file",
+	"merge 需要用 -o 指定输出文件":                  "merge needs an output file given with -o",
+	"已去掉 %d 个重复的分支":                        "dropped %d duplicate branches",
+	"生成 manifest.json 失败: %v":              "failed to build manifest.json: %v",
+	"将每个第一层分支写入单独的文件，并生成链接到各文件的 README.md": "Write each top-level branch to its own file plus a README.md linking them",
+	"split 需要指定一个文件":                       "split needs exactly one file",
+	"已将 %d 个分支写入: %s\n":                    "Wrote %d branches to: %s\n",
+	"提取 .xmind 文件中的图片、附件与录音等资源":            "Extract images, attachments and audio from a .xmind file",
+	"保存资源的目录，默认为输入文件旁边与其同名的目录":             "directory to save resources to, default: a directory named after the input next to it",
+	"同 -out":           "same as -out",
+	"extract 需要指定一个文件": "extract needs exactly one file",
+	"%s 不是 .xmind 文件，只有 .xmind 文件中保存了资源": "%s is not a .xmind file; only .xmind files contain resources",
+	"已提取 %s（%s）":         "extracted %s (%s)",
+	"%s 中没有资源\n":         "%s has no resources\n",
+	"已将 %d 个资源提取到: %s\n": "Extracted %d resources to: %s\n",
+	"创建目录失败: %w":         "failed to create directory: %w",
+	"检查思维导图中转换时可能出问题的地方，如空标题、重复的节点与失效的链接": "Check mind maps for conversion problems such as empty titles, duplicate topics and dead links",
+	"[参数] <文件>...": "[flags] <file>...",
+	"转换时根节点的标题级别（1-6），用于检查层数是否超过 Markdown 的 h6": "heading level of the root topic when converting (1-6), used to check depth against Markdown h6",
+	"以 JSON 格式输出发现的问题":                          "print the issues as JSON",
+	"有警告时同样以退出码 12 结束":                          "also exit with code 12 when there are warnings",
+	"lint 需要指定至少一个文件":                           "lint needs at least one file",
+	"检查未通过: %d 个错误，%d 个警告":                      "check failed: %d errors, %d warnings",
+	"节点标题为空":                                    "topic title is empty",
+	"有多个标题为 %q 的子节点":                            "several subtopics are titled %q",
+	"链接 %s 指向的节点不存在":                            "link %s points to a topic that does not exist",
+	"节点位于第 %d 层，转换为 Markdown 时标题超过 h6，与上级节点的标题级别相同": "topic is at level %d; its Markdown heading would exceed h6 and match its parent level",
+	"（无标题）":             "(untitled)",
+	"警告":                "warning",
+	"错误":                "error",
+	"没有发现问题":            "No problems found",
+	"共 %d 个错误，%d 个警告\n": "%d errors, %d warnings\n",
+	"在多个思维导图中查找标题与正则表达式匹配的节点，输出其所在的文件与位置": "Find topics whose titles match a regular expression across many mind maps, printing their file and path",
+	"[参数] <模式> <文件或目录>...":                       "[flags] <pattern> <file or directory>...",
+	"查找目录时只查找与模式匹配的文件（如 \"**/*.xmind\"），可重复指定":   "when searching directories, only search files matching the pattern (e.g. \"**/*.xmind\"); repeatable",
+	"查找目录时跳过与模式匹配的文件或目录（如 \"archive/**\"），可重复指定": "when searching directories, skip files or directories matching the pattern (e.g. \"archive/**\"); repeatable",
+	"忽略大小写": "ignore case",
+	"将模式作为普通文本而不是正则表达式":       "treat the pattern as plain text instead of a regular expression",
+	"同时查找节点备注":                "also search topic notes",
+	"以 JSON 格式输出匹配的节点":        "print the matching topics as JSON",
+	"search 需要指定模式与至少一个文件或目录": "search needs a pattern and at least one file or directory",
+	"在 %d 个文件中找到 %d 个匹配的节点":   "searched %d files, found %d matching topics",
+	"%d 个文件无法读取":              "%d files could not be read",
+	"%s: %s（备注: %s）\n":        "%s: %s (notes: %s)\n",
+	"没有找到 ID 为 %s 的节点":        "no topic with ID %s",
+	"-root 与 -root-id 不能同时指定": "-root and -root-id cannot be used together",
+	"-toc-depth 至少为 1":        "-toc-depth must be at least 1",
+	"检查思维导图中的外部链接是否可以访问，列出失效的链接及其所在的节点": "check that external links in mind maps are reachable and list dead links with their topics",
+	"check-links 需要指定至少一个文件":            "check-links requires at least one file",
+	"-jobs 与 -timeout 应大于 0":            "-jobs and -timeout must be greater than 0",
+	"%d 个链接失效":                          "%d dead links",
+	"检查了 %d 个链接（%d 个不同的地址）":             "checked %d links (%d distinct URLs)",
+	"没有发现失效的链接":                         "no dead links found",
+	"检查每个链接的超时时间":                       "timeout for checking each link",
+	"同时检查的链接数":                          "number of links to check concurrently",
+	"以 JSON 格式输出失效的链接":                  "print dead links as JSON",
+	"在根节点以外的每个节点标题前加上层级编号（1、1.1、1.1.2），与 XMind 中的编号设置无关": "prefix every topic except the root with its hierarchical number (1, 1.1, 1.1.2), regardless of XMind numbering settings",
+	"列出标题相同（忽略大小写与多余的空白）的同级节点或整个文件中的重复节点":                "list sibling topics, or topics anywhere in the file, with the same title (ignoring case and extra whitespace)",
+	"查找范围: ": "scope: ",
+	"duplicates 需要指定至少一个文件": "duplicates requires at least one file",
+	"不支持的查找范围: %s，可选: %s":   "unsupported scope: %s, available: %s",
+	"以 JSON 格式输出重复的节点":      "print duplicate topics as JSON",
+	"%s: %d 个标题为 %q 的节点\n":  "%s: %d topics titled %q\n",
+	"没有发现重复的节点":             "no duplicate topics found",
+	"合并同一节点下标题相同（忽略大小写与多余的空白）的子节点，后面节点的子节点移到第一个节点下":                                       "merge children of the same topic that share a title (ignoring case and extra whitespace), moving later children under the first one",
+	"在输出末尾写入节点数、字数、最大层数以及来源文件与转换时间":                                                       "append topic count, word count, maximum depth, source file and conversion time to the output",
+	"生成适合纳入 Git 的稳定输出：去掉行尾空白，合并连续的空行，-stats-footer 不写入转换时间（设置了 SOURCE_DATE_EPOCH 时使用其时间）": "produce stable, Git-friendly output: strip trailing whitespace, collapse blank lines, and omit the conversion time from -stats-footer (uses SOURCE_DATE_EPOCH when set)",
+	"忽略无效的 SOURCE_DATE_EPOCH: %s": "ignoring invalid SOURCE_DATE_EPOCH: %s",
+	"转换结束后将每个输出文件的路径、SHA-256 与对应的输入写入指定的 JSON 清单文件（-dry-run 时不写入）": "after converting, write the path, SHA-256 and source of every output file to the given JSON manifest (not written with -dry-run)",
+	"生成清单失败: %v":       "failed to build manifest: %v",
+	"写入清单失败: %v":       "failed to write manifest: %v",
+	"已写入清单 %s（%d 个文件）": "wrote manifest %s (%d files)",
+	"列出思维导图中每个画布的序号、标题、根节点与节点数":               "list the index, title, root topic and topic count of every sheet",
+	"sheets 需要指定一个文件":                         "sheets requires one file",
+	"在终端中以树形显示思维导图的节点层级，不生成任何文件":              "show the topic hierarchy of a mind map as a tree in the terminal without writing any file",
+	"最多显示的层数，根节点为第 1 层，0 表示不限制":               "maximum number of levels to show, the root topic is level 1, 0 means unlimited",
+	"只使用 ASCII 字符画树形的连线，用于不支持 Unicode 制表符的终端": "draw the tree with ASCII characters only, for terminals without Unicode box-drawing support",
+	"是否使用颜色: ":            "use colors: ",
+	"tree 需要指定一个文件":       "tree requires one file",
+	"不支持的颜色设置: %s，可选: %s": "unsupported color setting: %s, choose from: %s",
+	"-depth 不能小于 0":       "-depth must not be negative",
+	"画布 %d: %s":           "Sheet %d: %s",
+	"（还有 %d 个节点）":         " (%d more topics)",
+	"用类似 XPath 的表达式（如 //topic[label='风险']/title）选出节点或节点的内容": "select topics or their fields with an XPath-like expression such as //topic[label='risk']/title",
+	"以 JSON 格式输出结果":            "output results as JSON",
+	"query 需要指定表达式与至少一个文件":     "query requires an expression and at least one file",
+	"无效的查询: 字段 %s 只能是表达式的最后一步": "invalid query: field %s can only be the last step",
+	"无效的查询: 表达式为空":             "invalid query: empty expression",
+	"无效的查询: 表达式在应为 %s 的地方结束":   "invalid query: expression ends where %s is expected",
+	"无效的查询: 第 %d 个字符处应为 %s":    "invalid query: expected %[2]s at character %[1]d",
+	"字段名": "a field name",
+	"无效的查询: %s 只能按数字比较": "invalid query: %s only supports numeric comparison",
+	"无效的查询: %s 不能按数字比较": "invalid query: %s does not support numeric comparison",
+	"数字":       "a number",
+	"引号括起的字符串": "a quoted string",
+	"无效的查询: 无效的正则表达式 %q: %v": "invalid query: invalid regular expression %q: %v",
+	"只转换标题（没有标题时为根节点的标题）与正则表达式匹配的画布，每个画布写入单独的文件，文件名按 -name-template 生成，默认为 \"{{.Base}}-{{.Sheet}}{{.Ext}}\"": "only convert sheets whose title (the root topic title when empty) matches the regular expression, writing each to its own file named by -name-template, \"{{.Base}}-{{.Sheet}}{{.Ext}}\" by default",
+	"-sheet-regex 将每个画布写入单独的文件，不能与 -o、-merge 或 -clipboard 同时使用":                                              "-sheet-regex writes each sheet to its own file and cannot be used with -o, -merge or -clipboard",
+	"%s: 没有标题与 -sheet-regex 匹配的画布":           "%s: no sheet title matches -sheet-regex",
+	"重新转换思维导图并与已导出的文件比较，不一致时输出差异并以退出码 11 结束": "re-convert a mind map and compare it with an exported file, printing a diff and exiting with code 11 when they differ",
+	"差异中每处修改前后保留的行数":                         "number of context lines around each change in the diff",
+	"check 需要指定思维导图与已导出的文件":                  "check requires a mind map and an exported file",
+	"check 只支持文本格式的输出":                       "check only supports text output formats",
+	"-context 不能为负数":                         "-context must not be negative",
+	"%d 个文件与重新转换的结果不一致":                      "%d files differ from the re-converted result",
+	"%s 与 %s 一致": "%s is in sync with %s",
+	"%s 不存在":     "%s does not exist",
+	"%s（重新转换）":   "%s (re-converted)",
+	"%s 与重新转换的结果只有换行符不同": "%s differs from the re-converted result only in line endings",
+	"将转换得到的所有文件连同 .xmind 文件中的图片、附件等资源打包写入指定的 zip 文件（本地路径或 s3://bucket/key）": "package all converted files together with images, attachments and other resources of .xmind inputs into the given zip file (local path or s3://bucket/key)",
+	"-bundle 不能与 -o、-out-dir 或 -merge 同时使用":           "-bundle cannot be used with -o, -out-dir or -merge",
+	"-bundle 不能与 -dry-run、-preview 或 -clipboard 同时使用": "-bundle cannot be used with -dry-run, -preview or -clipboard",
+	"已打包到 %s（%d 个文件）\n":                               "Bundled into %s (%d files)\n",
+	"-sheet-regex 与 -bundle 不能用于从标准输入读取的内容":           "-sheet-regex and -bundle cannot be used with standard input",
+	"-index 只能用于批量转换，且不能与 -merge 同时使用":                "-index can only be used for batch conversion and not with -merge",
+	"批量转换后在输出目录（未指定 -out-dir 时为所有输出文件共同的目录）生成 README.md，列出并链接每个文档及其标题与节点数": "after a batch conversion write a README.md to the output directory (the common directory of all outputs without -out-dir) listing and linking every document with its title and topic count",
+	"生成目录失败: %v":               "failed to generate index: %v",
+	"输出文件与 -index 生成的目录相同: %s": "output file is the same as the -index file: %s",
+	"（%d 个节点）":                 " (%d topics)",
+	"目录已生成: %s\n":              "Index generated: %s\n",
+	"只输出叶子节点，每个画布输出为根节点下的平铺清单（Markdown 中 -leaf-style heading 按 bullet 输出）": "output only leaf topics as a flat checklist under each sheet root (in Markdown -leaf-style heading is written as bullet)",
+	"与 -leaves-only 一起使用，按第一层分支将叶子节点分组":                                    "with -leaves-only, group leaf topics by top-level branch",
+	"-group-leaves 需要与 -leaves-only 一起使用":                                  "-group-leaves requires -leaves-only",
+	"只转换标题为该值的第一个节点及其子树，并将其作为根节点；包含 / 时为从根节点的子节点开始的标题路径，如 \"第二章/2.1\"":     "only convert the first topic with this title and its subtree, making it the root; a value containing / is a title path starting from the children of the root, e.g. \"Chapter 2/2.1\"",
+	"只转换 ID 为该值的节点及其子树，并将其作为根节点":                                           "only convert the topic with this ID and its subtree, making it the root",
+	"目录": "Contents",
+	"在 Markdown 输出开头生成链接到各级标题的目录": "generate a table of contents linking to the headings at the start of Markdown output",
+	"-toc 生成的目录列出的层数，根节点为第 1 层":   "number of levels listed in the -toc table of contents, the root is level 1",
+	"…（还有 %d 层）":                       "… (levels omitted: %d)",
+	"请求中的参数超过 %d 字节":                   "request parameters exceed %d bytes",
+	"以 JSON 格式输出差异":                    "print the differences as JSON",
+	"将两边写出为缩进文本，以 diff -u 的格式逐行比较":     "write both sides as indented text and compare them line by line in diff -u format",
+	"-json 与 -unified 不能同时使用":          "-json and -unified cannot be used together",
+	"[← 上一部分](%s)":                     "[← Previous](%s)",
+	"第 %d / %d 部分":                     "Part %d of %d",
+	"[下一部分 →](%s)":                     "[Next →](%s)",
+	"%d 个节点，%d 字，最深 %d 层；由 %s 转换":      "%d topics, %d words, %d levels deep; converted from %s",
+	"%d 个节点，%d 字，最深 %d 层；由 %s 于 %s 转换": "%d topics, %d words, %d levels deep; converted from %s on %s",
+	"标准输入":                             "standard input",
+	"、":                                ", ",
+	"写入 %s 失败: %w":                     "failed to write %s: %w",
+}
@@ -0,0 +1,109 @@
+// Package i18n 翻译命令行与解析库输出的提示和错误信息
+//
+// 源语言为中文，消息以中文原文（含格式化占位符）为键在目录中查找译文，找不到时输出原文。
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// languages 为支持的语言及其消息目录，中文为源语言，不需要目录
+var languages = map[string]map[string]string{
+	"zh": nil,
+	"en": english,
+}
+
+var (
+	mu      sync.RWMutex
+	current = Detect()
+)
+
+// Languages 返回支持的语言
+func Languages() []string {
+	return []string{"zh", "en"}
+}
+
+// SetLanguage 设置输出信息使用的语言，lang 可以是 zh、en 或 zh_CN.UTF-8 这样的区域设置
+func SetLanguage(lang string) error {
+	l, ok := normalize(lang)
+	if !ok {
+		return fmt.Errorf(T("不支持的语言: %s，可选: %s"), lang, strings.Join(Languages(), ", "))
+	}
+	mu.Lock()
+	current = l
+	mu.Unlock()
+	return nil
+}
+
+// Language 返回当前使用的语言
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Detect 根据系统的区域设置选择语言：中文环境使用中文，其他明确指定的语言使用英文
+// 没有区域设置（如未设置 LANG 或为 C、POSIX）时保持中文
+func Detect() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return localeLanguage(v)
+		}
+	}
+	if v := systemLocale(); v != "" {
+		return localeLanguage(v)
+	}
+	return "zh"
+}
+
+func localeLanguage(locale string) string {
+	if l, ok := normalize(locale); ok {
+		return l
+	}
+	switch strings.ToUpper(locale) {
+	case "C", "POSIX":
+		return "zh"
+	}
+	return "en"
+}
+
+// normalize 将 zh_CN.UTF-8、en-US 等转换为支持的语言
+func normalize(lang string) (string, bool) {
+	l := strings.ToLower(lang)
+	if i := strings.IndexAny(l, "_-.@"); i >= 0 {
+		l = l[:i]
+	}
+	_, ok := languages[l]
+	return l, ok
+}
+
+// T 返回消息在当前语言中的译文
+// 以“前缀: 其余内容”拼接的消息（如带有可选值列表的参数说明）找不到时按前缀翻译
+func T(msg string) string {
+	catalog := languages[Language()]
+	if catalog == nil {
+		return msg
+	}
+	if s, ok := catalog[msg]; ok {
+		return s
+	}
+	if i := strings.Index(msg, ": "); i >= 0 {
+		if s, ok := catalog[msg[:i+2]]; ok {
+			return s + msg[i+2:]
+		}
+	}
+	return msg
+}
+
+// Errorf 与 fmt.Errorf 相同，格式字符串先翻译为当前语言
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// Sprintf 与 fmt.Sprintf 相同，格式字符串先翻译为当前语言
+func Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(T(format), args...)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package i18n
+
+// systemLocale 在 Windows 以外的系统上由 LANG 等环境变量决定，这里没有其他来源
+func systemLocale() string {
+	return ""
+}
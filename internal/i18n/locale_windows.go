@@ -0,0 +1,17 @@
+package i18n
+
+import "syscall"
+
+var procGetUserDefaultUILanguage = syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultUILanguage")
+
+// systemLocale 根据 Windows 的界面语言返回区域设置，中文的主语言 ID 为 0x04
+func systemLocale() string {
+	id, _, _ := procGetUserDefaultUILanguage.Call()
+	if id == 0 {
+		return ""
+	}
+	if id&0x3ff == 0x04 {
+		return "zh"
+	}
+	return "en"
+}
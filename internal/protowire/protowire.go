@@ -0,0 +1,186 @@
+// Package protowire 实现 serve 命令的 gRPC 接口所需的 Protocol Buffers 二进制编码，
+// 只支持 varint 与长度前缀两种类型，足以处理 proto/xmindtomarkdown/v1/converter.proto 中的消息
+package protowire
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// 字段的编码类型
+const (
+	WireVarint = 0
+	WireI64    = 1
+	WireBytes  = 2
+	WireI32    = 5
+)
+
+var (
+	// ErrTruncated 为消息在字段中间结束时的错误
+	ErrTruncated = errors.New("protobuf 消息不完整")
+	// ErrTooLarge 为 DecodeReader 读入内存的字段超过限制时的错误
+	ErrTooLarge = errors.New("protobuf 字段过大")
+)
+
+// Field 为解码得到的一个字段，Varint 类型的值在 Num 中，长度前缀类型的值在 Bytes 中
+// DecodeReader 流式读取的长度前缀字段的值在 Reader 中，此时 Bytes 为 nil
+type Field struct {
+	Number int
+	Wire   int
+	Num    uint64
+	Bytes  []byte
+	Reader io.Reader
+}
+
+// Decode 依次解码 data 中的字段并传给 fn，fn 返回错误时停止解码并返回该错误
+// 固定长度的字段会被跳过，不传给 fn
+func Decode(data []byte, fn func(f Field) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return ErrTruncated
+		}
+		data = data[n:]
+		f := Field{Number: int(key >> 3), Wire: int(key & 7)}
+		switch f.Wire {
+		case WireVarint:
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return ErrTruncated
+			}
+			f.Num, data = v, data[n:]
+		case WireBytes:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || l > uint64(len(data)-n) {
+				return ErrTruncated
+			}
+			f.Bytes, data = data[n:n+int(l)], data[n+int(l):]
+		case WireI64, WireI32:
+			size := 8
+			if f.Wire == WireI32 {
+				size = 4
+			}
+			if len(data) < size {
+				return ErrTruncated
+			}
+			data = data[size:]
+			continue
+		default:
+			return errors.New("不支持的 protobuf 字段类型")
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeReader 与 Decode 相同，但从 r 中依次读取字段：stream 返回 true 的长度前缀字段不读入内存，
+// 以 Field.Reader 传给 fn，fn 返回后没有读完的内容会被丢弃；其余长度前缀字段读入内存，
+// 总长度超过 limit 时返回 ErrTooLarge
+func DecodeReader(r io.Reader, stream func(number int) bool, limit int64, fn func(f Field) error) error {
+	br := bufio.NewReader(r)
+	var used int64
+	for {
+		key, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return ErrTruncated
+		}
+		f := Field{Number: int(key >> 3), Wire: int(key & 7)}
+		switch f.Wire {
+		case WireVarint:
+			if f.Num, err = binary.ReadUvarint(br); err != nil {
+				return ErrTruncated
+			}
+		case WireBytes:
+			l, err := binary.ReadUvarint(br)
+			if err != nil || l > 1<<62 {
+				return ErrTruncated
+			}
+			if stream(f.Number) {
+				body := &io.LimitedReader{R: br, N: int64(l)}
+				f.Reader = body
+				if err := fn(f); err != nil {
+					return err
+				}
+				// 内容不足字段的长度时消息不完整
+				if _, err := io.Copy(io.Discard, body); err != nil || body.N > 0 {
+					return ErrTruncated
+				}
+				continue
+			}
+			if used += int64(l); used > limit {
+				return ErrTooLarge
+			}
+			f.Bytes = make([]byte, l)
+			if _, err := io.ReadFull(br, f.Bytes); err != nil {
+				return ErrTruncated
+			}
+		case WireI64, WireI32:
+			size := int64(8)
+			if f.Wire == WireI32 {
+				size = 4
+			}
+			if _, err := io.CopyN(io.Discard, br, size); err != nil {
+				return ErrTruncated
+			}
+			continue
+		default:
+			return errors.New("不支持的 protobuf 字段类型")
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+}
+
+// Encoder 按字段编号追加字段，零值可以直接使用
+// 与 proto3 一致，值为零或空的字段不写出
+type Encoder struct {
+	buf []byte
+}
+
+// Bytes 返回已编码的消息
+func (e *Encoder) Bytes() []byte {
+	return e.buf
+}
+
+// key 写出字段编号与编码类型
+func (e *Encoder) key(number, wire int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(number)<<3|uint64(wire))
+}
+
+// Varint 写出整数字段
+func (e *Encoder) Varint(number int, v uint64) {
+	if v == 0 {
+		return
+	}
+	e.key(number, WireVarint)
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+// Bool 写出布尔字段
+func (e *Encoder) Bool(number int, v bool) {
+	if v {
+		e.Varint(number, 1)
+	}
+}
+
+// Raw 写出长度前缀字段，如 bytes 字段或嵌套的消息，空值同样写出，用于 repeated 字段
+func (e *Encoder) Raw(number int, b []byte) {
+	e.key(number, WireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// String 写出字符串字段
+func (e *Encoder) String(number int, s string) {
+	if s != "" {
+		e.Raw(number, []byte(s))
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// notifyInterrupt 返回收到第一个中断信号（Ctrl+C）时取消的 ctx，之后恢复默认的信号处理，
+// 转换没有及时停止时再按一次 Ctrl+C 会直接结束程序；结束时调用返回的 stop
+func notifyInterrupt(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// errInterrupted 返回中断转换时的错误，未完成的文件同样使用该错误
+func errInterrupted() error {
+	return withCode(exitInterrupted, i18n.Errorf("已中断"))
+}
+
+// interrupted 在 ctx 已经取消时将 err 替换为 errInterrupted，使下载、解析等各处的取消错误统一输出
+func interrupted(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return errInterrupted()
+	}
+	return err
+}
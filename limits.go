@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// defineLimitFlags 定义解析时的资源限制参数，默认值为 xmind.DefaultLimits
+func defineLimitFlags(fs *flag.FlagSet, l *xmind.Limits) {
+	d := xmind.DefaultLimits
+	fs.IntVar(&l.MaxEntries, "max-entries", d.MaxEntries, "压缩包中允许的最大文件数，0 表示不限制")
+	fs.Int64Var(&l.MaxDecompressedSize, "max-decompressed-size", d.MaxDecompressedSize, "从压缩包中读取的单个文件解压后的最大字节数，0 表示不限制")
+	fs.IntVar(&l.MaxJSONDepth, "max-json-depth", d.MaxJSONDepth, "content.json 与中间格式中 JSON 的最大嵌套层数，0 表示不限制")
+	fs.IntVar(&l.MaxTopics, "max-topics", d.MaxTopics, "一个文件中允许的最大节点总数，0 表示不限制")
+	fs.IntVar(&l.MaxTopicText, "max-topic-text", d.MaxTopicText, "单个节点的标题或备注的最大字节数，0 表示不限制")
+}
+
+// applyLimits 检查资源限制参数并使其对之后的解析生效
+func applyLimits(l xmind.Limits) error {
+	if l.MaxEntries < 0 || l.MaxDecompressedSize < 0 || l.MaxJSONDepth < 0 || l.MaxTopics < 0 || l.MaxTopicText < 0 {
+		return withCode(exitUsage, i18n.Errorf("-max-entries、-max-decompressed-size、-max-json-depth、-max-topics 与 -max-topic-text 不能为负数"))
+	}
+	xmind.DefaultLimits = l
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// lint 发现的问题的级别：错误表示转换结果有误（如链接失效），警告表示结果可能不是想要的
+const (
+	lintError   = "error"
+	lintWarning = "warning"
+)
+
+var lintCommand = &command{
+	Name:  "lint",
+	Args:  "[参数] <文件>...",
+	Short: "检查思维导图中转换时可能出问题的地方，如空标题、重复的节点与失效的链接",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var from string
+		var headingStart int
+		var asJSON, strict bool
+		fs.StringVar(&from, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.IntVar(&headingStart, "heading-start", 1, "转换时根节点的标题级别（1-6），用于检查层数是否超过 Markdown 的 h6")
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出发现的问题")
+		fs.BoolVar(&strict, "strict", false, "有警告时同样以退出码 12 结束")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return withCode(exitUsage, i18n.Errorf("lint 需要指定至少一个文件"))
+			}
+			if headingStart < 1 || headingStart > 6 {
+				return withCode(exitUsage, i18n.Errorf("-heading-start 应为 1 到 6 之间的整数"))
+			}
+			issues := []lintIssue{}
+			for _, in := range args {
+				wb, err := loadWorkbook(ctx, in, from)
+				if err != nil {
+					return err
+				}
+				found, err := lintWorkbook(wb, headingStart)
+				if err != nil {
+					return &fileError{path: wb.Path, err: withCode(exitParse, err)}
+				}
+				issues = append(issues, found...)
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(issues); err != nil {
+					return withCode(exitWrite, err)
+				}
+			} else {
+				printLint(os.Stdout, issues)
+			}
+			errs, warnings := countIssues(issues)
+			if errs > 0 || (strict && warnings > 0) {
+				return withCode(exitLint, i18n.Errorf("检查未通过: %d 个错误，%d 个警告", errs, warnings))
+			}
+			return nil
+		}
+	},
+}
+
+// lintIssue 为 lint 发现的一个问题
+type lintIssue struct {
+	File string `json:"file"`
+	// Path 为节点的位置，依次为画布（有标题时）与各级节点的标题
+	Path     []string `json:"path"`
+	TopicID  string   `json:"topicId,omitempty"`
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// lintWorkbook 检查 wb 中的所有画布：
+//   - empty-title：节点标题为空
+//   - duplicate-title：同一节点下有标题相同的子节点
+//   - dead-link：xmind:#ID 形式的内部链接指向不存在的节点或画布
+//   - missing-resource：图片或附件引用的资源不在文件中
+//   - too-deep：节点的标题级别超过 Markdown 的 h6，转换后与上级节点的标题级别相同
+func lintWorkbook(wb *workbook, headingStart int) ([]lintIssue, error) {
+	var issues []lintIssue
+	ids := map[string]bool{}
+	for i := range wb.Sheets {
+		s := &wb.Sheets[i]
+		ids[s.ID] = true
+		s.Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+			ids[t.ID] = true
+			return nil
+		})
+	}
+	delete(ids, "")
+	// resources 为 nil 时（不是 .xmind 文件）不检查资源
+	var resources map[string]bool
+	if wb.zipped() && wb.Format == "xmind" {
+		res, err := xmind.Resources(bytes.NewReader(wb.data), wb.Size)
+		if err != nil {
+			return nil, err
+		}
+		resources = map[string]bool{}
+		for _, r := range res {
+			resources[r.Name] = true
+		}
+	}
+
+	for i := range wb.Sheets {
+		s := &wb.Sheets[i]
+		s.Walk(func(path []*xmind.Topic, t *xmind.Topic) error {
+			report := func(rule, severity, msg string) {
+				issues = append(issues, lintIssue{File: wb.Path, Path: topicTrail(s, path, t), TopicID: t.ID, Rule: rule, Severity: severity, Message: msg})
+			}
+			if strings.TrimSpace(t.Title) == "" && t.Image == nil {
+				report("empty-title", lintWarning, i18n.T("节点标题为空"))
+			}
+			seen := map[string]bool{}
+			for _, c := range childTopics(t) {
+				title := strings.TrimSpace(c.Title)
+				if title != "" && seen[title] {
+					report("duplicate-title", lintWarning, i18n.Sprintf("有多个标题为 %q 的子节点", title))
+				}
+				seen[title] = true
+			}
+			if id := strings.TrimPrefix(t.Href, "xmind:#"); id != t.Href && !ids[id] {
+				report("dead-link", lintError, i18n.Sprintf("链接 %s 指向的节点不存在", t.Href))
+			}
+			for _, src := range topicResources(t) {
+				name, err := xmind.ResourceName(src)
+				switch {
+				case err != nil:
+					report("missing-resource", lintError, err.Error())
+				case resources != nil && !resources[name]:
+					report("missing-resource", lintError, i18n.Sprintf("文件中缺少资源 %s", name))
+				}
+			}
+			// 更深的节点同样超过，只报告最上面的一个
+			if headingStart+len(path) > 6 {
+				report("too-deep", lintWarning, i18n.Sprintf("节点位于第 %d 层，转换为 Markdown 时标题超过 h6，与上级节点的标题级别相同", len(path)+1))
+				return xmind.SkipChildren
+			}
+			return nil
+		})
+	}
+	return issues, nil
+}
+
+// childTopics 返回节点的子节点与分离的节点
+func childTopics(t *xmind.Topic) []xmind.Topic {
+	var children []xmind.Topic
+	if t.Children != nil {
+		children = append(children, t.Children.Attached...)
+	}
+	return append(children, t.Detached...)
+}
+
+// topicResources 返回节点引用的资源：图片与 xap: 形式的附件链接
+func topicResources(t *xmind.Topic) []string {
+	var srcs []string
+	if t.Image != nil && t.Image.Src != "" && !strings.Contains(t.Image.Src, "://") {
+		srcs = append(srcs, t.Image.Src)
+	}
+	if strings.HasPrefix(t.Href, "xap:") {
+		srcs = append(srcs, t.Href)
+	}
+	return srcs
+}
+
+// topicTrail 返回节点的位置，依次为画布（有标题时）、path 中各级节点与 t 的标题，空标题显示为“（无标题）”
+func topicTrail(s *xmind.Sheet, path []*xmind.Topic, t *xmind.Topic) []string {
+	var trail []string
+	if s.Title != "" {
+		trail = append(trail, s.Title)
+	}
+	for _, p := range path {
+		trail = append(trail, trailTitle(p.Title))
+	}
+	return append(trail, trailTitle(t.Title))
+}
+
+func trailTitle(title string) string {
+	if title = strings.Join(strings.Fields(title), " "); title == "" {
+		return i18n.T("（无标题）")
+	}
+	return title
+}
+
+func countIssues(issues []lintIssue) (errs, warnings int) {
+	for _, is := range issues {
+		if is.Severity == lintError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	return errs, warnings
+}
+
+// printLint 每个问题输出一行，格式为 文件: 位置: 级别: 说明 [规则]，最后输出问题的总数
+func printLint(w io.Writer, issues []lintIssue) {
+	for _, is := range issues {
+		severity := i18n.T("警告")
+		if is.Severity == lintError {
+			severity = i18n.T("错误")
+		}
+		fmt.Fprintf(w, "%s: %s: %s: %s [%s]\n", is.File, strings.Join(is.Path, " > "), severity, is.Message, is.Rule)
+	}
+	errs, warnings := countIssues(issues)
+	if len(issues) == 0 {
+		fmt.Fprintln(w, i18n.T("没有发现问题"))
+		return
+	}
+	fmt.Fprintf(w, i18n.T("共 %d 个错误，%d 个警告\n"), errs, warnings)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// 输出的详细程度，由 -q、-v 与 -vv 控制
+const (
+	levelQuiet   = -1 // 只输出错误
+	levelNormal  = 0  // 输出生成的文件与批量转换的汇总
+	levelVerbose = 1  // 另外输出正在处理的文件与转换时丢失的内容
+	levelDebug   = 2  // 另外输出每个画布的节点数等细节
+)
+
+// verbosity 为当前的详细程度
+var verbosity = levelNormal
+
+// logFormat 对应 -log-format 参数，为 text 或 json
+var logFormat = "text"
+
+// logger 为诊断信息使用的 slog.Logger，由 setupLogger 根据 -log-format 创建
+// 是否输出由 verbosity 决定，logger 本身不再按级别过滤
+var logger = slog.New(newTextHandler(os.Stderr))
+
+// verbosityFlag 实现 -q、-v 与 -vv，-v 可以重复指定以提高详细程度
+type verbosityFlag struct {
+	// delta 为每次指定时增加的详细程度，-q 为 0
+	delta int
+}
+
+func (f *verbosityFlag) IsBoolFlag() bool { return true }
+
+func (f *verbosityFlag) String() string { return "false" }
+
+func (f *verbosityFlag) Set(v string) error {
+	on, err := strconv.ParseBool(v)
+	if err != nil || !on {
+		return err
+	}
+	if f.delta == 0 {
+		verbosity = levelQuiet
+		return nil
+	}
+	if verbosity < levelNormal {
+		verbosity = levelNormal
+	}
+	verbosity += f.delta
+	return nil
+}
+
+// setupLogger 按 -log-format 创建 logger
+// json 时每条诊断信息为一行 JSON，包含时间、级别、信息与 logf 附加的字段，便于在服务与批量转换中按字段筛选
+func setupLogger() error {
+	switch logFormat {
+	case "text":
+		logger = slog.New(newTextHandler(os.Stderr))
+	case "json":
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.Level(-100)}))
+	default:
+		format := logFormat
+		logFormat = "text"
+		return withCode(exitUsage, i18n.Errorf("不支持的日志格式: %s", format))
+	}
+	return nil
+}
+
+// slogLevel 返回详细程度对应的 slog 级别：默认输出的信息为 Info，-v 与 -vv 才输出的信息为 Debug 及更低的级别
+func slogLevel(level int) slog.Level {
+	if level <= levelNormal {
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug - slog.Level(4*(level-levelVerbose))
+}
+
+// logf 在详细程度不低于 level 时输出一条诊断信息，format 与 args 生成信息的内容
+// args 中的 slog.Attr 不参与格式化，而是作为 JSON 日志中的字段，如 logf(levelVerbose, "正在转换 %s", in, slog.String("input", in))
+func logf(level int, format string, args ...interface{}) {
+	logAt(level, slogLevel(level), format, args)
+}
+
+// warnf 与 logf 相同，但信息的级别为 Warn，文本格式时以“警告: ”开头
+func warnf(level int, format string, args ...interface{}) {
+	logAt(level, slog.LevelWarn, format, args)
+}
+
+func logAt(level int, sl slog.Level, format string, args []interface{}) {
+	if verbosity < level {
+		return
+	}
+	var attrs []slog.Attr
+	values := args[:0:0]
+	for _, a := range args {
+		if attr, ok := a.(slog.Attr); ok {
+			attrs = append(attrs, attr)
+			continue
+		}
+		values = append(values, a)
+	}
+	r := slog.NewRecord(time.Now(), sl, fmt.Sprintf(i18n.T(format), values...), 0)
+	r.AddAttrs(attrs...)
+	defer suspendProgress()()
+	logger.Handler().Handle(context.Background(), r)
+}
+
+// textHandler 为默认的文本格式，每条信息一行，只输出信息本身，附加的字段已经包含在信息中，不再重复输出
+type textHandler struct {
+	w io.Writer
+}
+
+func newTextHandler(w io.Writer) slog.Handler {
+	return textHandler{w: w}
+}
+
+func (h textHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h textHandler) Handle(_ context.Context, r slog.Record) error {
+	msg := r.Message
+	if r.Level >= slog.LevelWarn && r.Level < slog.LevelError {
+		msg = i18n.T("警告: ") + msg
+	}
+	_, err := fmt.Fprintln(h.w, msg)
+	return err
+}
+
+func (h textHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h textHandler) WithGroup(string) slog.Handler { return h }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// manifest 为 -manifest 写入的清单，列出本次批量转换得到的每个输出文件及其 SHA-256，
+// 供发布流程校验文件是否完整
+type manifest struct {
+	// Complete 表示所有输入都已成功转换且没有被中断，为 false 时清单中只有成功的部分
+	Complete bool `json:"complete"`
+	Total    int  `json:"total"`
+	Failed   int  `json:"failed"`
+	// Files 按路径排序，路径相对于清单所在的目录
+	Files []manifestFile `json:"files"`
+}
+
+// manifestFile 为清单中的一个输出文件，Source 为对应的输入
+type manifestFile struct {
+	Path   string `json:"path"`
+	Source string `json:"source"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// writeManifest 按转换报告中的输出文件生成清单写入 file，输入没有变化而跳过的文件同样列出，
+// 拆分输出时包括每个部分；标准输出与 S3 上的输出不列出。runErr 为转换返回的错误
+func writeManifest(file string, r *runReport, runErr error, opts convertOptions) error {
+	m := manifest{Complete: runErr == nil && r.Failed == 0, Total: r.Total, Failed: r.Failed, Files: []manifestFile{}}
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return withCode(exitWrite, i18n.Errorf("生成清单失败: %v", err))
+	}
+	for _, f := range r.Files {
+		if f.Error != "" || f.Output == "" || f.Output == "-" || isS3(f.Output) {
+			continue
+		}
+		var outputs []string
+		for _, out := range f.outputs() {
+			outputs = append(outputs, out)
+			if !opts.chunked() {
+				continue
+			}
+			for i := 1; ; i++ {
+				p := chunkPath(out, i)
+				if _, err := os.Stat(p); err != nil {
+					break
+				}
+				outputs = append(outputs, p)
+			}
+		}
+		for _, out := range outputs {
+			sum, err := hashFile(out)
+			if err != nil {
+				return withCode(exitWrite, i18n.Errorf("生成清单失败: %v", err))
+			}
+			fi, err := os.Stat(out)
+			if err != nil {
+				return withCode(exitWrite, i18n.Errorf("生成清单失败: %v", err))
+			}
+			p := out
+			if abs, err := filepath.Abs(out); err == nil {
+				if rel, err := filepath.Rel(dir, abs); err == nil {
+					p = rel
+				}
+			}
+			m.Files = append(m.Files, manifestFile{Path: filepath.ToSlash(p), Source: f.Input, SHA256: sum, Size: fi.Size()})
+		}
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0o644); err != nil {
+		return withCode(exitWrite, i18n.Errorf("写入清单失败: %v", err))
+	}
+	logf(levelVerbose, "已写入清单 %s（%d 个文件）", file, len(m.Files))
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// mcpProtocolVersion 为支持的 MCP（Model Context Protocol）版本，客户端请求其他版本时仍以该版本回复
+const mcpProtocolVersion = "2025-06-18"
+
+// JSON-RPC 错误码
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// rpcMessage 为 JSON-RPC 2.0 的请求、通知或响应，通知没有 ID
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// mcpTool 为 tools/list 返回的一个工具
+type mcpTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// mcpArgs 为所有工具的参数，各工具只使用其中的一部分
+type mcpArgs struct {
+	Path         string `json:"path"`
+	Format       string `json:"format"`
+	Sheet        string `json:"sheet"`
+	Topic        string `json:"topic"`
+	HeadingStart int    `json:"headingStart"`
+	MaxDepth     int    `json:"maxDepth"`
+	Notes        *bool  `json:"notes"`
+}
+
+// mcpTools 为 MCP 服务提供的工具，描述面向调用工具的模型，因此使用英文
+var mcpTools = []mcpTool{
+	{
+		Name:        "convert_xmind",
+		Description: "Convert a local mind map file (XMind, SimpleMind, MindNode, FreeMind, OPML or indented text) to Markdown or another text format. Topic notes are included by default.",
+		InputSchema: mcpSchema([]string{"path"}, map[string]interface{}{
+			"path":         mcpString("Path of the mind map file."),
+			"format":       map[string]interface{}{"type": "string", "enum": []string{"md", "txt", "ir"}, "description": "Output format, md by default. ir is a JSON tree."},
+			"sheet":        mcpString("Only convert the sheet with this title or 1-based index."),
+			"headingStart": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 6, "description": "Markdown heading level of the root topic."},
+			"maxDepth":     map[string]interface{}{"type": "integer", "minimum": 0, "description": "Maximum number of levels, the root topic being level 1."},
+			"notes":        map[string]interface{}{"type": "boolean", "description": "Include topic notes, true by default."},
+		}),
+	},
+	{
+		Name:        "list_sheets",
+		Description: "List the sheets of a local mind map file with their root topic, number of topics and depth.",
+		InputSchema: mcpSchema([]string{"path"}, map[string]interface{}{
+			"path": mcpString("Path of the mind map file."),
+		}),
+	},
+	{
+		Name:        "extract_subtree",
+		Description: "Return one branch of a mind map as Markdown, with the chosen topic as the top heading. topic is a title, or a path of titles separated by / starting below the root, e.g. \"Goals/Q3\".",
+		InputSchema: mcpSchema([]string{"path", "topic"}, map[string]interface{}{
+			"path":     mcpString("Path of the mind map file."),
+			"topic":    mcpString("Title of the topic, or a / separated path of titles."),
+			"sheet":    mcpString("Sheet title or 1-based index; all sheets are searched by default."),
+			"maxDepth": map[string]interface{}{"type": "integer", "minimum": 0, "description": "Maximum number of levels below and including the topic."},
+		}),
+	},
+}
+
+func mcpSchema(required []string, props map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": props, "required": required}
+}
+
+func mcpString(desc string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": desc}
+}
+
+// serveMCP 在 r 与 w 上提供 MCP 服务，每行一个 JSON-RPC 消息，r 结束时返回，工具调用记录在 metrics 中
+// 请求按顺序逐个处理，ctx 取消后不再读取新的请求
+func serveMCP(ctx context.Context, r io.Reader, w io.Writer, metrics *metricsRegistry) error {
+	send := func(m rpcMessage) error {
+		m.JSONRPC = "2.0"
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req rpcMessage
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := send(rpcMessage{ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		// 通知与客户端发来的响应不需要回复
+		if len(req.ID) == 0 || req.Method == "" {
+			continue
+		}
+		result, rerr := handleMCP(ctx, req, metrics)
+		resp := rpcMessage{ID: req.ID, Result: result, Error: rerr}
+		if err := send(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// handleMCP 处理一个请求，返回结果或 JSON-RPC 错误
+func handleMCP(ctx context.Context, req rpcMessage, metrics *metricsRegistry) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		ver, _, _ := buildInfo()
+		return map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "xmindtomarkdown", "version": ver},
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": mcpTools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string  `json:"name"`
+			Arguments mcpArgs `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &p); err != nil {
+			return nil, &rpcError{rpcInvalidParams, err.Error()}
+		}
+		c := metrics.begin("mcp", p.Name)
+		text, err := callMCPTool(ctx, p.Name, p.Arguments)
+		if err != errUnknownTool {
+			c.outSize = int64(len(text))
+			c.done(err)
+		}
+		if err == errUnknownTool {
+			return nil, &rpcError{rpcInvalidParams, i18n.Sprintf("未知的工具: %s", p.Name)}
+		}
+		// 工具执行失败时按 MCP 的约定在结果中返回错误，使模型可以看到错误信息
+		if err != nil {
+			text = err.Error()
+		}
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": text}},
+			"isError": err != nil,
+		}, nil
+	}
+	return nil, &rpcError{rpcMethodNotFound, i18n.Sprintf("未知的方法: %s", req.Method)}
+}
+
+// errUnknownTool 为调用不存在的工具时 callMCPTool 返回的错误
+var errUnknownTool = i18n.Errorf("未知的工具")
+
+// callMCPTool 执行工具，返回给模型的文本
+func callMCPTool(ctx context.Context, name string, args mcpArgs) (string, error) {
+	switch name {
+	case "convert_xmind", "list_sheets", "extract_subtree":
+	default:
+		return "", errUnknownTool
+	}
+	if args.Path == "" {
+		return "", i18n.Errorf("缺少参数: %s", "path")
+	}
+	sheets, err := xmind.ParseFileAsContext(ctx, args.Path, "auto")
+	if err != nil {
+		return "", err
+	}
+	if args.Sheet != "" {
+		if sheets, err = selectMCPSheet(sheets, args.Sheet); err != nil {
+			return "", err
+		}
+	}
+	write := render.WriteOptions{Notes: args.Notes == nil || *args.Notes, HeadingStart: args.HeadingStart, MaxDepth: args.MaxDepth}
+	var buf bytes.Buffer
+	switch name {
+	case "convert_xmind":
+		format := args.Format
+		if format == "" {
+			format = "md"
+		}
+		if !oneOf(format, []string{"md", "txt", "ir"}) {
+			return "", i18n.Errorf("不支持的输出格式: %s", format)
+		}
+		err = render.WriteAsContext(ctx, format, &buf, sheets, write)
+	case "list_sheets":
+		for i := range sheets {
+			s := &sheets[i]
+			buf.WriteString(i18n.Sprintf("%d. %s（根节点: %s，%d 个节点，%d 层）\n", i+1, s.Title, s.RootTopic.Title, s.TopicCount(), sheetDepth(s)))
+		}
+	case "extract_subtree":
+		if args.Topic == "" {
+			return "", i18n.Errorf("缺少参数: %s", "topic")
+		}
+		t, i := findTopic(sheets, args.Topic)
+		if t == nil {
+			return "", i18n.Errorf("没有找到节点: %s", args.Topic)
+		}
+		err = render.WriteAsContext(ctx, "md", &buf, []xmind.Sheet{{Title: sheets[i].Title, RootTopic: *t}}, write)
+	}
+	return buf.String(), err
+}
+
+// selectMCPSheet 按标题或从 1 开始的序号选出一个画布
+func selectMCPSheet(sheets []xmind.Sheet, sel string) ([]xmind.Sheet, error) {
+	for i, s := range sheets {
+		if s.Title == sel || strconv.Itoa(i+1) == sel {
+			return []xmind.Sheet{s}, nil
+		}
+	}
+	return nil, i18n.Errorf("没有找到画布: %s", sel)
+}
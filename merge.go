@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// mergeInputs 将所有输入合并写入 opts.Output 一个文件
+// 输出 Markdown 时每个输入成为一个以文件名为标题的一级小节，文档开头带有链接到各小节的目录；
+// 其他格式依次写出所有输入的画布。任何一个输入失败时不写入输出
+func mergeInputs(ctx context.Context, inputs []input, opts convertOptions, rep *runReport, quiet bool) error {
+	if opts.Output == "" {
+		return withCode(exitUsage, i18n.Errorf("-merge 需要用 -o 指定合并后的输出文件"))
+	}
+	if opts.OutDir != "" || opts.Preview || opts.Clipboard {
+		return withCode(exitUsage, i18n.Errorf("-merge 不能与 -out-dir、-preview 或 -clipboard 同时使用"))
+	}
+	var sections []mergeSection
+	for _, in := range inputs {
+		start := time.Now()
+		logf(levelVerbose, "正在转换 %s", in.Path, slog.String("input", in.Path))
+		sheets, name, err := readInput(ctx, in.Path, opts)
+		fr := newFileReport(in.Path, sheets, opts)
+		fr.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			rep.add(fr, err)
+			return &fileError{path: in.Path, err: err}
+		}
+		logSheets(fr, sheets)
+		fr.Output = opts.Output
+		if opts.DryRun {
+			fr.Action = outputAction(opts.Output)
+		}
+		rep.add(fr, nil)
+		base := path.Base(filepath.ToSlash(name))
+		sections = append(sections, mergeSection{title: strings.TrimSuffix(base, path.Ext(base)), source: base, sheets: sheets})
+	}
+	if opts.DryRun {
+		if !quiet {
+			printDryRun(os.Stdout, rep)
+		}
+		return nil
+	}
+	merged := mergeSheets(sections, &opts)
+	if opts.StatsFooter {
+		// 统计合并前的画布，不计入 Markdown 中为每个文件添加的根节点
+		var names []string
+		var sheets []xmind.Sheet
+		for _, s := range sections {
+			names = append(names, s.source)
+			sheets = append(sheets, s.sheets...)
+		}
+		opts.Write.Footer = statsFooter(strings.Join(names, i18n.T("、")), sheets, opts)
+	}
+	if err := writeOutput(ctx, opts.Output, merged, opts); err != nil {
+		return err
+	}
+	opts.Cache.storeOutput(opts.Output)
+	recordOutput(opts.Output)
+	if !quiet && opts.Output != "-" {
+		fmt.Printf(i18n.T("已将 %d 个文件合并到: %s\n"), len(sections), opts.Output)
+	}
+	return nil
+}
+
+// mergeSection 为合并时的一个输入，source 为输入的文件名，title 为去掉扩展名后的小节标题
+type mergeSection struct {
+	title  string
+	source string
+	sheets []xmind.Sheet
+}
+
+// readInput 读取本地或远程的输入并执行转换脚本，返回解析得到的画布与用于命名的文件名
+func readInput(ctx context.Context, in string, opts convertOptions) ([]xmind.Sheet, string, error) {
+	sheets, name, err := readInputSheets(ctx, in, opts)
+	if err != nil {
+		return nil, name, err
+	}
+	sheets, err = transformSheets(ctx, sheets, opts)
+	return sheets, name, err
+}
+
+// readInputSheets 读取并解析本地或远程的输入
+func readInputSheets(ctx context.Context, in string, opts convertOptions) ([]xmind.Sheet, string, error) {
+	if isRemote(in) {
+		return readRemote(ctx, in, opts.From, opts.Fetch)
+	}
+	sheets, err := xmind.ParseFileAsContext(ctx, in, opts.From)
+	if err != nil {
+		return nil, in, withCode(exitParse, err)
+	}
+	return sheets, in, nil
+}
+
+// mergeSheets 返回合并后要写出的画布
+// Markdown 中每个输入成为一个根节点，其下依次为各画布的根节点，并在开头生成目录
+func mergeSheets(sections []mergeSection, opts *convertOptions) []xmind.Sheet {
+	var merged []xmind.Sheet
+	if opts.To != "md" {
+		for _, s := range sections {
+			merged = append(merged, s.sheets...)
+		}
+		return merged
+	}
+	for _, s := range sections {
+		root := xmind.Topic{Title: s.title, Children: &xmind.Children{}}
+		for _, sheet := range s.sheets {
+			root.Children.Attached = append(root.Children.Attached, sheet.RootTopic)
+		}
+		merged = append(merged, xmind.Sheet{Title: s.title, RootTopic: root})
+	}
+	// 没有指定 -toc 时目录只列出各个文件
+	if !opts.Write.TOC {
+		opts.Write.TOC = true
+		opts.Write.TOCDepth = 1
+	}
+	return merged
+}
+
+var mergeCommand = &command{
+	Name:  "merge",
+	Args:  "[参数] -o <输出文件> <文件>...",
+	Short: "将多个思维导图的画布合并为一个 .xmind 文件",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var from, output string
+		var dedupe, force, backup bool
+		fs.StringVar(&from, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.StringVar(&output, "o", "", "输出的 .xmind 文件，- 表示标准输出")
+		fs.StringVar(&output, "output", "", "同 -o")
+		fs.BoolVar(&dedupe, "dedupe", false, "将标题与根节点相同的画布合并为一个，并去掉内容完全相同的第一层分支")
+		fs.BoolVar(&force, "force", false, "覆盖已存在的输出文件")
+		fs.BoolVar(&backup, "backup", false, "覆盖已存在的输出文件前保留一份带时间戳的备份")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return withCode(exitUsage, i18n.Errorf("merge 需要指定至少一个文件"))
+			}
+			if output == "" {
+				return withCode(exitUsage, i18n.Errorf("merge 需要用 -o 指定输出文件"))
+			}
+			var wbs []*workbook
+			for _, in := range args {
+				wb, err := loadWorkbook(ctx, in, from)
+				if err != nil {
+					return err
+				}
+				wbs = append(wbs, wb)
+			}
+			m, err := mergeWorkbooks(wbs, dedupe)
+			if err != nil {
+				return err
+			}
+			if m.Dropped > 0 {
+				logf(levelVerbose, "已去掉 %d 个重复的分支", m.Dropped, slog.Int("dropped", m.Dropped))
+			}
+			if err := protectOutput(output, convertOptions{Force: force, Backup: backup}); err != nil {
+				return err
+			}
+			out, err := createOutput(ctx, output, fetchOptions{})
+			if err != nil {
+				return withCode(exitWrite, err)
+			}
+			err = render.WriteXMindResources(out, m.Sheets, m.Resources)
+			if a, ok := out.(aborter); ok && err != nil {
+				a.Abort()
+			} else if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return withCode(exitWrite, i18n.Errorf("写入 %s 失败: %v", output, err))
+			}
+			recordOutput(output)
+			if verbosity > levelQuiet && output != "-" {
+				fmt.Printf(i18n.T("文件已生成: %s\n"), output)
+			}
+			return nil
+		}
+	},
+}
+
+// mergeResult 为合并后的画布与资源，Dropped 为去掉的重复分支数
+type mergeResult struct {
+	Sheets    []xmind.Sheet
+	Resources map[string][]byte
+	Dropped   int
+}
+
+// mergeWorkbooks 按顺序合并 wbs 中的所有画布与资源
+// 不同文件中同名而内容不同的资源会重新命名，并修改引用它的节点图片与链接
+// dedupe 时标题与根节点标题相同的画布合并为一个（以先出现的画布为准），后面画布中与已有分支内容完全相同的第一层分支不再添加
+func mergeWorkbooks(wbs []*workbook, dedupe bool) (mergeResult, error) {
+	m := mergeResult{Resources: map[string][]byte{}}
+	// merged 为 dedupe 时每个画布在 m.Sheets 中的位置，branches 为其中已有分支的内容
+	merged := map[string]int{}
+	branches := map[int]map[string]bool{}
+	for _, wb := range wbs {
+		renamed, err := m.addResources(wb)
+		if err != nil {
+			return m, &fileError{path: wb.Path, err: withCode(exitParse, err)}
+		}
+		for _, s := range wb.Sheets {
+			if len(renamed) > 0 {
+				renameResources(&s, renamed)
+			}
+			if !dedupe {
+				m.Sheets = append(m.Sheets, s)
+				continue
+			}
+			key := s.Title + "\x00" + s.RootTopic.Title
+			i, ok := merged[key]
+			if !ok {
+				i = len(m.Sheets)
+				merged[key] = i
+				branches[i] = map[string]bool{}
+				s.RootTopic.Children, s.RootTopic.Detached = mergeBranches(nil, &s.RootTopic, branches[i], &m.Dropped)
+				m.Sheets = append(m.Sheets, s)
+				continue
+			}
+			root := &m.Sheets[i].RootTopic
+			root.Children, root.Detached = mergeBranches(root, &s.RootTopic, branches[i], &m.Dropped)
+		}
+	}
+	return m, nil
+}
+
+// mergeBranches 将 from 的第一层分支追加到 into 的分支之后，seen 中已有的分支不再添加，返回合并后的子节点与分离的节点
+func mergeBranches(into, from *xmind.Topic, seen map[string]bool, dropped *int) (*xmind.Children, []xmind.Topic) {
+	var attached, detached []xmind.Topic
+	if into != nil {
+		if into.Children != nil {
+			attached = into.Children.Attached
+		}
+		detached = into.Detached
+	}
+	add := func(list []xmind.Topic, t xmind.Topic) []xmind.Topic {
+		fp := topicFingerprint(t)
+		if seen[fp] {
+			*dropped++
+			return list
+		}
+		seen[fp] = true
+		return append(list, t)
+	}
+	if from.Children != nil {
+		for _, t := range from.Children.Attached {
+			attached = add(attached, t)
+		}
+	}
+	for _, t := range from.Detached {
+		detached = add(detached, t)
+	}
+	var children *xmind.Children
+	if from.Children != nil || (into != nil && into.Children != nil) || len(attached) > 0 {
+		children = &xmind.Children{Attached: attached}
+	}
+	return children, detached
+}
+
+// topicFingerprint 返回节点及其子节点除 ID 以外的内容，用于判断两个分支是否相同
+func topicFingerprint(t xmind.Topic) string {
+	s := xmind.Sheet{RootTopic: t}
+	// 清除 ID 之前先复制整棵树，避免修改原来的节点
+	data, _ := json.Marshal(s)
+	json.Unmarshal(data, &s)
+	s.Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+		t.ID = ""
+		return nil
+	})
+	data, _ = json.Marshal(s.RootTopic)
+	return string(data)
+}
+
+// addResources 将 .xmind 文件 wb 中的资源加入 m.Resources，返回因为重名而重新命名的资源（原文件名到新文件名）
+func (m *mergeResult) addResources(wb *workbook) (map[string]string, error) {
+	if !wb.zipped() || wb.Format != "xmind" {
+		return nil, nil
+	}
+	ra := bytes.NewReader(wb.data)
+	res, err := xmind.Resources(ra, wb.Size)
+	if err != nil {
+		return nil, err
+	}
+	renamed := map[string]string{}
+	for _, r := range res {
+		data, err := xmind.ReadResource(ra, wb.Size, r.Name)
+		if err != nil {
+			return nil, err
+		}
+		name := r.Name
+		ext := path.Ext(name)
+		for n := 2; m.Resources[name] != nil && !bytes.Equal(m.Resources[name], data); n++ {
+			name = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(r.Name, ext), n, ext)
+		}
+		if name != r.Name {
+			renamed[r.Name] = name
+		}
+		m.Resources[name] = data
+	}
+	return renamed, nil
+}
+
+// renameResources 将 s 中引用了重新命名的资源的节点图片与 xap: 链接（附件）改为新的文件名
+func renameResources(s *xmind.Sheet, renamed map[string]string) {
+	rename := func(src string) string {
+		name, err := xmind.ResourceName(src)
+		if err != nil {
+			return src
+		}
+		if to, ok := renamed[name]; ok {
+			return "xap:" + to
+		}
+		return src
+	}
+	s.Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+		if t.Image != nil {
+			t.Image = &xmind.Image{Src: rename(t.Image.Src)}
+		}
+		if strings.HasPrefix(t.Href, "xap:") {
+			t.Href = rename(t.Href)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 监控指标使用 Prometheus 的文本格式，由 GET /metrics 返回，只依赖标准库，因此不使用 Prometheus 的客户端库
+
+// durationBuckets 为转换耗时直方图的分桶上限（秒）
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// sizeBuckets 为请求与输出大小直方图的分桶上限（字节）
+var sizeBuckets = []float64{1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20}
+
+// metricFamily 为一个指标及其所有标签组合的取值
+type metricFamily struct {
+	name, help, kind string
+	// buckets 不为空时为直方图
+	buckets []float64
+	series  map[string]*metricSeries
+}
+
+type metricSeries struct {
+	labels string
+	// value 为计数器或仪表的值，直方图为所有观测值之和
+	value  float64
+	count  uint64
+	counts []uint64
+}
+
+// metricsRegistry 保存服务运行以来的所有指标
+type metricsRegistry struct {
+	mu       sync.Mutex
+	families []*metricFamily
+	start    time.Time
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{start: time.Now()}
+}
+
+// family 返回名为 name 的指标，不存在时创建
+func (m *metricsRegistry) family(name, help, kind string, buckets []float64) *metricFamily {
+	for _, f := range m.families {
+		if f.name == name {
+			return f
+		}
+	}
+	f := &metricFamily{name: name, help: help, kind: kind, buckets: buckets, series: map[string]*metricSeries{}}
+	m.families = append(m.families, f)
+	return f
+}
+
+func (f *metricFamily) get(labels []string) *metricSeries {
+	key := formatLabels(labels)
+	s := f.series[key]
+	if s == nil {
+		s = &metricSeries{labels: key, counts: make([]uint64, len(f.buckets))}
+		f.series[key] = s
+	}
+	return s
+}
+
+// add 为计数器或仪表加上 v，labels 为交替出现的标签名与取值
+func (m *metricsRegistry) add(name, help, kind string, v float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.family(name, help, kind, nil).get(labels).value += v
+}
+
+// observe 在直方图中记录一个观测值
+func (m *metricsRegistry) observe(name, help string, buckets []float64, v float64, labels ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.family(name, help, "histogram", buckets).get(labels)
+	s.value += v
+	s.count++
+	for i, b := range buckets {
+		if v <= b {
+			s.counts[i]++
+		}
+	}
+}
+
+// conversion 记录一次请求，由 begin 创建，请求结束时调用 done
+type conversion struct {
+	m               *metricsRegistry
+	server, op      string
+	start           time.Time
+	inSize, outSize int64
+}
+
+// begin 开始记录 server（http、grpc 或 mcp）上的一次 op 请求，如 convert、inspect 或 MCP 的工具名
+// m 为 nil 时不记录，返回的 conversion 仍然可以使用
+func (m *metricsRegistry) begin(server, op string) *conversion {
+	if m != nil {
+		m.add("xmind2md_requests_in_flight", "正在处理的请求数。", "gauge", 1, "server", server)
+	}
+	return &conversion{m: m, server: server, op: op, start: time.Now(), inSize: -1, outSize: -1}
+}
+
+// done 记录请求的结果、耗时以及上传与输出的大小，err 不为 nil 时按错误类型（同 -error-format=json 中的 kind）计数
+func (c *conversion) done(err error) {
+	m := c.m
+	if m == nil {
+		return
+	}
+	m.add("xmind2md_requests_in_flight", "正在处理的请求数。", "gauge", -1, "server", c.server)
+	result := "ok"
+	if err != nil {
+		result = "error"
+		m.add("xmind2md_errors_total", "失败的请求数，kind 为错误类型。", "counter", 1, "server", c.server, "op", c.op, "kind", exitKinds[exitCode(err)])
+	}
+	m.add("xmind2md_requests_total", "处理完成的请求数。", "counter", 1, "server", c.server, "op", c.op, "result", result)
+	m.observe("xmind2md_request_duration_seconds", "处理请求的耗时。", durationBuckets, time.Since(c.start).Seconds(), "server", c.server, "op", c.op)
+	if c.inSize >= 0 {
+		m.observe("xmind2md_input_bytes", "上传的思维导图文件的大小。", sizeBuckets, float64(c.inSize), "server", c.server)
+	}
+	if c.outSize >= 0 {
+		m.observe("xmind2md_output_bytes", "转换结果的大小。", sizeBuckets, float64(c.outSize), "server", c.server)
+	}
+}
+
+// write 以 Prometheus 文本格式写出所有指标，指标与标签组合均按名称排序，输出稳定
+func (m *metricsRegistry) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+	ver, _, _ := buildInfo()
+	fmt.Fprintf(&b, "# HELP xmind2md_build_info 版本信息，取值总为 1。\n# TYPE xmind2md_build_info gauge\nxmind2md_build_info%s 1\n", formatLabels([]string{"version", ver}))
+	fmt.Fprintf(&b, "# HELP xmind2md_start_time_seconds 服务启动的 Unix 时间。\n# TYPE xmind2md_start_time_seconds gauge\nxmind2md_start_time_seconds %d\n", m.start.Unix())
+
+	families := append([]*metricFamily(nil), m.families...)
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+	for _, f := range families {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			s := f.series[k]
+			if f.buckets == nil {
+				fmt.Fprintf(&b, "%s%s %s\n", f.name, s.labels, formatValue(s.value))
+				continue
+			}
+			for i, le := range f.buckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, withLabel(s.labels, "le", formatValue(le)), s.counts[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", f.name, withLabel(s.labels, "le", "+Inf"), s.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", f.name, s.labels, formatValue(s.value))
+			fmt.Fprintf(&b, "%s_count%s %d\n", f.name, s.labels, s.count)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatLabels 将交替出现的标签名与取值格式化为 {a="1",b="2"}，没有标签时为空
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[i+1])
+		fmt.Fprintf(&b, "%s=\"%s\"", labels[i], v)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel 在已格式化的标签中追加一个标签，用于直方图的 le
+func withLabel(labels, name, value string) string {
+	l := fmt.Sprintf("%s=%q", name, value)
+	if labels == "" {
+		return "{" + l + "}"
+	}
+	return labels[:len(labels)-1] + "," + l + "}"
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// metricsHandler 提供 GET /metrics 与 GET /healthz，用于 -metrics 指定的单独端口
+type metricsHandler struct {
+	m *metricsRegistry
+}
+
+func (h metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/metrics" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		h.m.write(w)
+	case r.URL.Path == "/healthz" && r.Method == http.MethodGet:
+		io.WriteString(w, "ok\n")
+	default:
+		http.NotFound(w, r)
+	}
+}
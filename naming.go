@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// nameData 为 -name-template 中可以使用的字段
+type nameData struct {
+	// Base 为输入文件名去掉扩展名
+	Base string
+	// Sheet 为第一个画布的标题
+	Sheet string
+	// Date 为转换当天的日期，如 2024-01-02
+	Date string
+	// Ext 为输出格式的扩展名，如 .md
+	Ext string
+	// Slug 为 Base 转换为小写并以 - 连接单词后的结果
+	Slug string
+}
+
+// parseNameTemplate 解析 -name-template 指定的输出文件名模板
+func parseNameTemplate(text string) (*template.Template, error) {
+	t, err := template.New("name").Funcs(template.FuncMap{
+		"slug":  slugify,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}).Parse(text)
+	if err == nil {
+		// 提前用空字段执行一次，使拼错的字段名在转换开始前报错
+		err = t.Execute(io.Discard, nameData{})
+	}
+	if err != nil {
+		return nil, withCode(exitUsage, i18n.Errorf("无效的文件名模板: %v", err))
+	}
+	return t, nil
+}
+
+// renderName 按模板生成输出文件名，base 为输入文件名去掉扩展名
+// 字段中不能用于文件名的字符会被替换为 _，生成的文件名不能为空或包含目录
+func renderName(t *template.Template, base, ext string, sheets []xmind.Sheet) (string, error) {
+	data := nameData{
+		Base: safeName(base),
+		Date: time.Now().Format("2006-01-02"),
+		Ext:  ext,
+		Slug: slugify(base),
+	}
+	if len(sheets) > 0 {
+		data.Sheet = safeName(sheetTitle(sheets[0]))
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", withCode(exitUsage, i18n.Errorf("生成文件名失败: %v", err))
+	}
+	name := strings.TrimSpace(b.String())
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", withCode(exitUsage, i18n.Errorf("文件名模板生成的文件名无效: %q", name))
+	}
+	return name, nil
+}
+
+// safeName 将不能用于文件名的字符替换为 _
+func safeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
+
+// slugify 转换为小写，字母与数字（包括中文）以外的字符视为分隔，单词之间以 - 连接
+func slugify(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
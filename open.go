@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+var (
+	// generatedFiles 记录本次运行中生成的本地文件，用于结束时打开输出文件夹
+	generatedFiles []string
+	// foldersOpened 表示已经打开过输出文件夹
+	foldersOpened bool
+)
+
+// recordOutput 记录生成的本地输出文件，标准输出与 S3 不记录
+func recordOutput(out string) {
+	if out == "" || out == "-" || isS3(out) {
+		return
+	}
+	generatedFiles = append(generatedFiles, out)
+}
+
+// maxOpenFolders 为一次最多打开的文件夹数，避免批量转换时打开大量窗口
+const maxOpenFolders = 5
+
+// openOutputFolders 在文件管理器中打开生成的文件所在的文件夹
+func openOutputFolders(files []string) {
+	foldersOpened = true
+	seen := map[string]bool{}
+	var dirs []string
+	for _, f := range files {
+		dir := filepath.Dir(f)
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) > maxOpenFolders {
+		logf(levelNormal, "输出位于 %d 个文件夹中，只打开前 %d 个", len(dirs), maxOpenFolders)
+		dirs = dirs[:maxOpenFolders]
+	}
+	for _, dir := range dirs {
+		if err := openFolder(dir); err != nil {
+			warnf(levelNormal, "打开文件夹 %s 失败: %v", dir, err)
+		}
+	}
+}
+
+// openFolder 使用系统的文件管理器打开文件夹
+func openFolder(dir string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("explorer", dir)
+	case "darwin":
+		cmd = exec.Command("open", dir)
+	default:
+		cmd = exec.Command("xdg-open", dir)
+	}
+	// 不等待文件管理器退出；explorer 即使成功也可能返回非零状态码
+	return cmd.Start()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// aborter 由需要在写入失败时放弃输出的目标实现，放弃后不再调用 Close
+type aborter interface {
+	Abort()
+}
+
+// createOutput 创建输出目标，path 可以是本地路径、s3://bucket/key 或表示标准输出的 -
+// 本地文件先写入同一目录下的临时文件，Close 时再重命名为目标文件，写入中途失败不会留下不完整的文件；
+// S3 上的文件在 Close 时上传，ctx 被取消时中止上传
+func createOutput(ctx context.Context, path string, opts fetchOptions) (io.WriteCloser, error) {
+	if path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	if isS3(path) {
+		obj, err := parseS3(path)
+		if err != nil {
+			return nil, err
+		}
+		return &s3Writer{ctx: ctx, obj: obj, timeout: opts.Timeout}, nil
+	}
+	return createAtomic(path)
+}
+
+// atomicFile 先写入临时文件，Close 时同步到磁盘并重命名为目标文件
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+func createAtomic(path string) (*atomicFile, error) {
+	// 先确认可以在目标位置创建文件，使错误信息与直接创建时一致
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return nil, i18n.Errorf("创建输出文件失败: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "."+base+".*.tmp")
+	if err != nil {
+		return nil, i18n.Errorf("创建输出文件失败: %w", err)
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+func (f *atomicFile) Close() error {
+	tmp := f.File.Name()
+	err := f.File.Sync()
+	if cerr := f.File.Close(); err == nil {
+		err = cerr
+	}
+	// 临时文件的权限为 0600，改为已存在文件的权限或普通文件的默认权限
+	mode := os.FileMode(0o644)
+	if info, serr := os.Stat(f.path); serr == nil {
+		mode = info.Mode().Perm()
+	}
+	if err == nil {
+		err = os.Chmod(tmp, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp, f.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+func (f *atomicFile) Abort() {
+	f.File.Close()
+	os.Remove(f.File.Name())
+}
+
+// s3Writer 先在内存中缓存输出内容，Close 时一次性上传
+type s3Writer struct {
+	ctx     context.Context
+	obj     s3Object
+	timeout time.Duration
+	buf     bytes.Buffer
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	return w.obj.put(w.ctx, w.buf.Bytes(), w.timeout)
+}
+
+// Abort 放弃上传
+func (w *s3Writer) Abort() {
+	w.buf.Reset()
+}
+
+// nopCloser 包装标准输出，Close 时不关闭底层文件
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
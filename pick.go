@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var pickCommand = &command{
+	Name:  "pick",
+	Args:  "[参数] [文件]",
+	Short: "在终端中浏览思维导图，勾选要导出的画布与分支并选择输出格式",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.StringVar(&opts.To, "to", "md", "默认的输出格式: "+strings.Join(render.OutputFormats(), ", "))
+		fs.StringVar(&opts.Output, "o", "", "默认的输出文件路径，默认与输入文件同名")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) > 1 {
+				return withCode(exitUsage, i18n.Errorf("pick 只能打开一个文件"))
+			}
+			if !isTerminal(os.Stdin) {
+				return withCode(exitUsage, i18n.Errorf("pick 需要在交互式终端中运行"))
+			}
+			if err := checkFormat(opts.To); err != nil {
+				return withCode(exitUsage, err)
+			}
+			var in string
+			if len(args) == 1 {
+				in = args[0]
+			} else {
+				p, err := promptPath(ctx)
+				if err != nil {
+					return err
+				}
+				in = p
+			}
+			sheets, err := xmind.ParseFileAsContext(ctx, in, opts.From)
+			if err != nil {
+				return &fileError{path: in, err: withCode(exitParse, err)}
+			}
+			p := newPicker(in, sheets, opts, bufio.NewReader(os.Stdin), os.Stdout)
+			return p.run(ctx)
+		}
+	},
+}
+
+// pickNode 为选择界面中的一个节点，画布本身也是一个节点，其子节点为根节点的子节点
+type pickNode struct {
+	topic    xmind.Topic
+	title    string
+	children []*pickNode
+	// detached 表示分离的节点，导出时仍放回 Topic.Detached
+	detached bool
+	checked  bool
+	expanded bool
+	depth    int
+}
+
+// picker 为逐行读取命令的选择界面，不依赖终端的原始模式，在 cmd.exe 中同样可用
+type picker struct {
+	in string
+	// orig 为解析得到的画布，sheets 为对应的节点
+	orig   []xmind.Sheet
+	sheets []*pickNode
+	opts   convertOptions
+	r      *bufio.Reader
+	w      io.Writer
+	// visible 为当前显示的节点，编号从 1 开始
+	visible []*pickNode
+}
+
+func newPicker(in string, sheets []xmind.Sheet, opts convertOptions, r *bufio.Reader, w io.Writer) *picker {
+	p := &picker{in: in, orig: sheets, opts: opts, r: r, w: w}
+	for i, s := range sheets {
+		title := s.Title
+		if title == "" {
+			title = fmt.Sprintf(i18n.T("画布 %d"), i+1)
+		}
+		n := newPickNode(s.RootTopic, 0, false)
+		n.title = fmt.Sprintf(i18n.T("%s（%s）"), title, s.RootTopic.Title)
+		n.expanded = len(sheets) == 1
+		p.sheets = append(p.sheets, n)
+	}
+	return p
+}
+
+func newPickNode(t xmind.Topic, depth int, detached bool) *pickNode {
+	n := &pickNode{topic: t, title: t.Title, detached: detached, checked: true, depth: depth}
+	if t.Children != nil {
+		for _, c := range t.Children.Attached {
+			n.children = append(n.children, newPickNode(c, depth+1, false))
+		}
+	}
+	for _, c := range t.Detached {
+		n.children = append(n.children, newPickNode(c, depth+1, true))
+	}
+	return n
+}
+
+const pickHelp = `命令:
+  编号          勾选或取消勾选节点及其所有子节点
+  +编号 / -编号  展开或折叠节点，单独的 + / - 展开或折叠全部
+  a / n         全选 / 全不选
+  f 格式        设置输出格式（%s）
+  o 路径        设置输出文件路径
+  w 或回车      导出并退出
+  q             退出，不导出
+  ?             显示本帮助
+`
+
+// run 显示节点树并逐行执行命令，直到导出或退出
+func (p *picker) run(ctx context.Context) error {
+	fmt.Fprintf(p.w, i18n.T(pickHelp), strings.Join(render.OutputFormats(), ", "))
+	for {
+		p.render()
+		fmt.Fprintf(p.w, i18n.T("输出: %s（%s） > "), p.output(), p.opts.To)
+		line, err := p.r.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && line == "" {
+			return nil
+		}
+		done, err := p.exec(ctx, line)
+		if err != nil {
+			fmt.Fprintf(p.w, "%v\n", err)
+			continue
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// exec 执行一条命令，done 表示已经导出或退出
+func (p *picker) exec(ctx context.Context, line string) (done bool, err error) {
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.Trim(strings.TrimSpace(arg), `"`)
+	switch {
+	case line == "" || line == "w":
+		return p.export(ctx)
+	case line == "q":
+		return true, nil
+	case line == "?":
+		fmt.Fprintf(p.w, i18n.T(pickHelp), strings.Join(render.OutputFormats(), ", "))
+	case line == "a" || line == "n":
+		for _, s := range p.sheets {
+			setChecked(s, line == "a")
+		}
+	case line == "+" || line == "-":
+		for _, s := range p.sheets {
+			setExpanded(s, line == "+")
+		}
+	case cmd == "f":
+		if err := checkFormat(arg); err != nil {
+			return false, err
+		}
+		p.opts.To = arg
+	case cmd == "o":
+		p.opts.Output = arg
+	default:
+		expand := strings.HasPrefix(line, "+")
+		collapse := strings.HasPrefix(line, "-")
+		n, err := p.node(strings.TrimLeft(line, "+-"))
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case expand:
+			n.expanded = true
+		case collapse:
+			n.expanded = false
+		default:
+			setChecked(n, state(n) != 1)
+		}
+	}
+	return false, nil
+}
+
+// node 按显示的编号查找节点
+func (p *picker) node(s string) (*pickNode, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 1 || i > len(p.visible) {
+		return nil, i18n.Errorf("无效的命令: %s，输入 ? 查看帮助", s)
+	}
+	return p.visible[i-1], nil
+}
+
+// render 输出当前展开的节点，[x] 为已勾选，[-] 为部分子节点已勾选，▸ 表示可以展开
+func (p *picker) render() {
+	p.visible = p.visible[:0]
+	var walk func(n *pickNode)
+	walk = func(n *pickNode) {
+		p.visible = append(p.visible, n)
+		fold := " "
+		if len(n.children) > 0 {
+			fold = "▸"
+			if n.expanded {
+				fold = "▾"
+			}
+		}
+		box := [...]string{"[ ]", "[x]", "[-]"}[state(n)]
+		title := n.title
+		if n.detached {
+			title += i18n.T("（分离）")
+		}
+		fmt.Fprintf(p.w, "%3d %s%s %s %s\n", len(p.visible), strings.Repeat("  ", n.depth), fold, box, title)
+		if n.expanded {
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+	}
+	fmt.Fprintln(p.w)
+	for _, s := range p.sheets {
+		walk(s)
+	}
+}
+
+// output 返回输出文件路径
+func (p *picker) output() string {
+	if p.opts.Output != "" {
+		return p.opts.Output
+	}
+	ext, _ := render.OutputExt(p.opts.To)
+	return strings.TrimSuffix(p.in, filepath.Ext(p.in)) + ext
+}
+
+// export 写出勾选的画布与分支，输出文件已存在时先确认是否覆盖
+func (p *picker) export(ctx context.Context) (bool, error) {
+	var sheets []xmind.Sheet
+	for i, s := range p.sheets {
+		if t, ok := selected(s); ok {
+			sheet := p.orig[i]
+			sheet.RootTopic = t
+			sheets = append(sheets, sheet)
+		}
+	}
+	if len(sheets) == 0 {
+		return false, i18n.Errorf("没有勾选任何节点")
+	}
+	out := p.output()
+	if outputAction(out) == "overwrite" {
+		fmt.Fprintf(p.w, i18n.T("%s 已存在，是否覆盖？(y/N) "), out)
+		line, _ := p.r.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+			return false, nil
+		}
+		p.opts.Force = true
+	}
+	if err := writeOutput(ctx, out, sheets, p.opts); err != nil {
+		return false, err
+	}
+	fmt.Fprintf(p.w, i18n.T("文件已生成: %s\n"), out)
+	return true, nil
+}
+
+// selected 返回只包含勾选节点的 Topic，未勾选但有勾选的子节点的节点保留以维持层级
+func selected(n *pickNode) (xmind.Topic, bool) {
+	t := n.topic
+	t.Children, t.Detached = nil, nil
+	var attached []xmind.Topic
+	for _, c := range n.children {
+		ct, ok := selected(c)
+		if !ok {
+			continue
+		}
+		if c.detached {
+			t.Detached = append(t.Detached, ct)
+		} else {
+			attached = append(attached, ct)
+		}
+	}
+	if attached != nil {
+		t.Children = &xmind.Children{Attached: attached}
+	}
+	return t, n.checked || attached != nil || t.Detached != nil
+}
+
+// state 返回节点的勾选状态：0 未勾选，1 节点及所有子节点都已勾选，2 部分勾选
+func state(n *pickNode) int {
+	all, some := n.checked, n.checked
+	for _, c := range n.children {
+		switch state(c) {
+		case 0:
+			all = false
+		case 1:
+			some = true
+		case 2:
+			all, some = false, true
+		}
+	}
+	switch {
+	case all:
+		return 1
+	case some:
+		return 2
+	}
+	return 0
+}
+
+func setChecked(n *pickNode, v bool) {
+	n.checked = v
+	for _, c := range n.children {
+		setChecked(c, v)
+	}
+}
+
+func setExpanded(n *pickNode, v bool) {
+	n.expanded = v
+	for _, c := range n.children {
+		setExpanded(c, v)
+	}
+}
@@ -0,0 +1,250 @@
+// Package batch 提供批量转换使用的任务队列：固定数量的 worker 并行处理、临时性 I/O 错误的重试与汇总结果。
+//
+// 命令行的批量转换、watch 与 serve 使用同一个 Engine，因此并发数、重试与取消的处理方式一致：
+//
+//	e := batch.New(4)
+//	sum := e.Run(ctx, len(files), func(ctx context.Context, i int) error {
+//		return convert(ctx, files[i])
+//	}, func(r batch.Result) {
+//		fmt.Println(files[r.Index], r.Err)
+//	})
+package batch
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// 重试的默认设置，由 New 填入 Engine
+const (
+	DefaultRetries = 2
+	DefaultBackoff = 100 * time.Millisecond
+)
+
+// maxBackoff 为两次重试之间最长的等待时间
+const maxBackoff = 5 * time.Second
+
+// ErrNotStarted 表示任务还在排队时 ctx 已经结束，任务没有开始处理
+// 返回的错误同时包含 ctx 的错误，可以用 errors.Is(err, context.DeadlineExceeded) 等判断原因
+var ErrNotStarted = errors.New("没有开始处理")
+
+// Engine 为并行处理任务的队列，同一个 Engine 上所有的 Run 与 Acquire 共用 Workers 个处理名额
+// 字段需要在第一次使用前设置，Engine 可以在多个 goroutine 中同时使用
+type Engine struct {
+	// Workers 为同时处理的最大任务数，不大于 0 时与 CPU 核数相同
+	Workers int
+	// Retries 为任务因临时性错误失败后最多重试的次数，0 表示不重试
+	Retries int
+	// Backoff 为第一次重试前等待的时间，之后每次加倍，最长为 5 秒
+	Backoff time.Duration
+	// Retryable 判断错误是否值得重试，为 nil 时使用 Transient
+	Retryable func(error) bool
+	// OnRetry 在每次重试前调用，item 为 Run 中任务的序号（Retry 单独调用时为 -1），attempt 为即将开始的第几次尝试
+	OnRetry func(item, attempt int, err error)
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+// New 返回同时处理 workers 个任务、使用默认重试设置的 Engine
+func New(workers int) *Engine {
+	return &Engine{Workers: workers, Retries: DefaultRetries, Backoff: DefaultBackoff}
+}
+
+// Result 为一个任务的处理结果
+type Result struct {
+	// Index 为任务的序号
+	Index int
+	// Err 为最后一次尝试的错误，成功时为 nil；没有开始处理时属于 ErrNotStarted
+	Err error
+	// Attempts 为尝试的次数，没有开始处理时为 0
+	Attempts int
+	// Elapsed 为处理任务（包括重试前的等待）的耗时，不包括排队的时间
+	Elapsed time.Duration
+}
+
+// Summary 汇总一次 Run 中所有任务的结果
+type Summary struct {
+	// Total 为任务总数，Succeeded 与 Failed 为处理后成功与失败的任务数，NotStarted 为因 ctx 结束没有处理的任务数
+	Total, Succeeded, Failed, NotStarted int
+	// Retried 为至少重试过一次的任务数，无论最终是否成功
+	Retried int
+	// Elapsed 为 Run 的总耗时
+	Elapsed time.Duration
+}
+
+func (e *Engine) workers() int {
+	if e.Workers <= 0 {
+		return runtime.NumCPU()
+	}
+	return e.Workers
+}
+
+// Acquire 等待空闲的处理名额，返回释放名额的函数；ctx 结束前仍没有空闲名额时返回属于 ErrNotStarted 的错误
+func (e *Engine) Acquire(ctx context.Context) (release func(), err error) {
+	e.once.Do(func() { e.slots = make(chan struct{}, e.workers()) })
+	// ctx 已经结束时不再与空闲的名额随机选择
+	if err := ctx.Err(); err != nil {
+		return nil, notStarted(err)
+	}
+	select {
+	case e.slots <- struct{}{}:
+		return func() { <-e.slots }, nil
+	case <-ctx.Done():
+		return nil, notStarted(ctx.Err())
+	}
+}
+
+// Retry 调用 fn，失败的原因为临时性错误时按 Retries 与 Backoff 重试，返回尝试的次数与最后一次的错误
+// Retry 不占用处理名额，需要限制并发时先调用 Acquire；等待重试时 ctx 结束则返回最后一次的错误
+func (e *Engine) Retry(ctx context.Context, fn func(ctx context.Context) error) (attempts int, err error) {
+	return e.retry(ctx, -1, fn)
+}
+
+func (e *Engine) retry(ctx context.Context, item int, fn func(ctx context.Context) error) (int, error) {
+	retryable := e.Retryable
+	if retryable == nil {
+		retryable = Transient
+	}
+	delay := e.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || attempt > e.Retries || ctx.Err() != nil || !retryable(err) {
+			return attempt, err
+		}
+		if e.OnRetry != nil {
+			e.OnRetry(item, attempt+1, err)
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return attempt, err
+		}
+		if delay *= 2; delay > maxBackoff {
+			delay = maxBackoff
+		}
+	}
+}
+
+// Do 占用一个处理名额并调用 fn，失败时按 Retry 重试
+func (e *Engine) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	release, err := e.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = e.Retry(ctx, fn)
+	return err
+}
+
+// Run 并行处理序号为 0 到 n-1 的 n 个任务，do 处理第 i 个任务，按序号的顺序依次将每个任务的结果传给 handle
+// handle 在调用 Run 的 goroutine 中执行，可以为 nil；ctx 结束后还没有开始的任务不再处理，结果属于 ErrNotStarted
+func (e *Engine) Run(ctx context.Context, n int, do func(ctx context.Context, i int) error, handle func(Result)) Summary {
+	start := time.Now()
+	workers := e.workers()
+	if workers > n {
+		workers = n
+	}
+	results := make([]chan Result, n)
+	for i := range results {
+		results[i] = make(chan Result, 1)
+	}
+	next := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			next <- i
+		}
+		close(next)
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range next {
+				results[i] <- e.runItem(ctx, i, do)
+			}
+		}()
+	}
+
+	sum := Summary{Total: n}
+	for i := range results {
+		r := <-results[i]
+		switch {
+		case r.Err == nil:
+			sum.Succeeded++
+		case r.Attempts == 0:
+			sum.NotStarted++
+		default:
+			sum.Failed++
+		}
+		if r.Attempts > 1 {
+			sum.Retried++
+		}
+		if handle != nil {
+			handle(r)
+		}
+	}
+	sum.Elapsed = time.Since(start)
+	return sum
+}
+
+func (e *Engine) runItem(ctx context.Context, i int, do func(ctx context.Context, i int) error) Result {
+	release, err := e.Acquire(ctx)
+	if err != nil {
+		return Result{Index: i, Err: err}
+	}
+	defer release()
+	start := time.Now()
+	attempts, err := e.retry(ctx, i, func(ctx context.Context) error { return do(ctx, i) })
+	return Result{Index: i, Err: err, Attempts: attempts, Elapsed: time.Since(start)}
+}
+
+// notStartedError 为没有开始处理的任务的错误，errors.Is 对 ErrNotStarted 与 ctx 的错误均成立
+type notStartedError struct {
+	err error
+}
+
+func notStarted(err error) error {
+	return &notStartedError{err: err}
+}
+
+func (e *notStartedError) Error() string {
+	return i18n.Sprintf("没有开始处理: %v", e.err)
+}
+
+func (e *notStartedError) Unwrap() error {
+	return e.err
+}
+
+func (e *notStartedError) Is(target error) bool {
+	return target == ErrNotStarted
+}
+
+// Transient 判断 err 是否为可能在稍后自动恢复的 I/O 错误，如网络超时、连接被重置、文件被其他程序锁定
+// 解析失败、文件不存在等重试也不会成功的错误以及取消均不属于临时性错误
+// http.Client.Timeout 等内部的超时属于临时性错误；传给 Retry 的 ctx 结束时无论错误是什么都不再重试
+func Transient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+	for _, errno := range transientErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// transientErrnos 为各系统共有的临时性错误，transient_windows.go 中另外加入 Windows 特有的错误
+var transientErrnos = []syscall.Errno{syscall.EAGAIN, syscall.EBUSY, syscall.EINTR, syscall.ETIMEDOUT, syscall.ECONNRESET, syscall.ECONNABORTED}
@@ -0,0 +1,11 @@
+package batch
+
+import "syscall"
+
+// XMind 等程序保存文件期间，Windows 上打开同一个文件会因共享冲突或锁定失败，稍后重试即可成功
+func init() {
+	transientErrnos = append(transientErrnos,
+		syscall.Errno(32), // ERROR_SHARING_VIOLATION
+		syscall.Errno(33), // ERROR_LOCK_VIOLATION
+	)
+}
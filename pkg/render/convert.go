@@ -0,0 +1,176 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"text/template"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// Option 调整 Convert 的转换方式，见 WithFormat、WithHeadingStart 等
+type Option func(*config)
+
+// config 为 Convert 使用的全部配置，零值为默认行为：自动识别输入格式并输出 Markdown
+type config struct {
+	from        string
+	to          string
+	write       WriteOptions
+	sheetFilter func(xmind.Sheet) bool
+}
+
+// WithInputFormat 指定输入格式，取值见 xmind.InputFormats，默认根据文件内容识别
+func WithInputFormat(format string) Option {
+	return func(c *config) { c.from = format }
+}
+
+// WithFormat 指定输出格式，取值见 OutputFormats，默认为 md
+func WithFormat(format string) Option {
+	return func(c *config) { c.to = format }
+}
+
+// WithWriteOptions 以 opts 替换全部写出选项，之后的 Option 在此基础上继续调整
+func WithWriteOptions(opts WriteOptions) Option {
+	return func(c *config) { c.write = opts }
+}
+
+// WithHeadingStart 指定 Markdown 中根节点的标题级别，见 WriteOptions.HeadingStart
+func WithHeadingStart(level int) Option {
+	return func(c *config) { c.write.HeadingStart = level }
+}
+
+// WithNotes 指定是否在 Markdown 中输出节点备注，见 WriteOptions.Notes
+func WithNotes(notes bool) Option {
+	return func(c *config) { c.write.Notes = notes }
+}
+
+// WithTemplate 指定 template 格式使用的模板并将输出格式设为 template，见 ParseTemplate
+func WithTemplate(t *template.Template) Option {
+	return func(c *config) {
+		c.to = "template"
+		c.write.Template = t
+	}
+}
+
+// WithLeafStyle 指定 Markdown 中叶子节点的输出方式，见 WriteOptions.LeafStyle
+func WithLeafStyle(style string) Option {
+	return func(c *config) { c.write.LeafStyle = style }
+}
+
+// WithLeavesOnly 只输出叶子节点的平铺列表，group 为 true 时按第一层分支分组，见 WriteOptions.LeavesOnly
+func WithLeavesOnly(group bool) Option {
+	return func(c *config) { c.write.LeavesOnly, c.write.GroupLeaves = true, group }
+}
+
+// WithMaxDepth 指定最多输出的层数，见 WriteOptions.MaxDepth
+func WithMaxDepth(depth int) Option {
+	return func(c *config) { c.write.MaxDepth = depth }
+}
+
+// WithTOC 在 Markdown 输出开头生成列出前 depth 层标题的目录，depth 不大于 0 时为 3，见 WriteOptions.TOC
+func WithTOC(depth int) Option {
+	return func(c *config) { c.write.TOC, c.write.TOCDepth = true, depth }
+}
+
+// WithMarkers 将图标 ID 映射为输出在节点标题前的文本，可以多次指定，见 WriteOptions.Markers
+func WithMarkers(markers map[string]string) Option {
+	return func(c *config) {
+		if c.write.Markers == nil {
+			c.write.Markers = map[string]string{}
+		}
+		for id, text := range markers {
+			c.write.Markers[id] = text
+		}
+	}
+}
+
+// WithEvents 在转换过程中将事件传给 fn，用于显示进度，见 WriteOptions.OnEvent
+func WithEvents(fn func(Event)) Option {
+	return func(c *config) { c.write.OnEvent = fn }
+}
+
+// WithSheetFilter 只输出 keep 返回 true 的画布，多次指定时画布需要同时满足所有条件
+func WithSheetFilter(keep func(xmind.Sheet) bool) Option {
+	return func(c *config) {
+		prev := c.sheetFilter
+		c.sheetFilter = func(s xmind.Sheet) bool {
+			return (prev == nil || prev(s)) && keep(s)
+		}
+	}
+}
+
+// Convert 解析 path 指向的本地文件，按 opts 转换后返回输出的内容
+//
+//	out, err := render.Convert("plan.xmind", render.WithHeadingStart(2), render.WithNotes(true))
+func Convert(path string, opts ...Option) ([]byte, error) {
+	return ConvertContext(context.Background(), path, opts...)
+}
+
+// ConvertContext 与 Convert 相同，ctx 被取消或超时后停止转换并返回 ctx.Err()
+func ConvertContext(ctx context.Context, path string, opts ...Option) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ConvertToContext(ctx, &buf, path, opts...); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ConvertTo 解析 path 指向的本地文件，按 opts 转换后写入 w，w 可以是 HTTP 响应、管道等任意 io.Writer
+// 解析失败时不会向 w 写入任何内容
+func ConvertTo(w io.Writer, path string, opts ...Option) error {
+	return ConvertToContext(context.Background(), w, path, opts...)
+}
+
+// ConvertToContext 与 ConvertTo 相同，ctx 被取消或超时后停止转换并返回 ctx.Err()
+func ConvertToContext(ctx context.Context, w io.Writer, path string, opts ...Option) error {
+	c := newConfig(opts)
+	sheets, err := xmind.ParseFileAsContext(ctx, path, c.from)
+	if err != nil {
+		return err
+	}
+	return c.output(ctx, w, sheets)
+}
+
+// ConvertBytes 解析内存中的文件内容 data，按 opts 转换后返回输出的内容，
+// 用于没有文件系统的环境，如浏览器中的 WebAssembly 或通过 HTTP 上传的文件
+func ConvertBytes(data []byte, opts ...Option) ([]byte, error) {
+	return ConvertBytesContext(context.Background(), data, opts...)
+}
+
+// ConvertBytesContext 与 ConvertBytes 相同，ctx 被取消或超时后停止转换并返回 ctx.Err()
+func ConvertBytesContext(ctx context.Context, data []byte, opts ...Option) ([]byte, error) {
+	c := newConfig(opts)
+	sheets, err := xmind.ParseAsContext(ctx, c.from, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.output(ctx, &buf, sheets); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newConfig 依次应用 opts，返回最终的配置
+func newConfig(opts []Option) config {
+	c := config{from: "auto", to: "md"}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// output 按配置筛选画布并写出到 w
+func (c config) output(ctx context.Context, w io.Writer, sheets []xmind.Sheet) error {
+	if c.sheetFilter != nil {
+		var kept []xmind.Sheet
+		for _, s := range sheets {
+			if c.sheetFilter(s) {
+				kept = append(kept, s)
+			}
+		}
+		sheets = kept
+	}
+	return WriteAsContext(ctx, c.to, w, sheets, c.write)
+}
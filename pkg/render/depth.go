@@ -0,0 +1,74 @@
+package render
+
+import (
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// limitDepth 返回去掉超过 opts.MaxDepth 层的节点后的 sheets，根节点为第 1 层
+// opts.DepthNote 为 true 时在被截断的节点上记录省略的层数（xmind.Topic.OmittedLevels），由各格式以文字注明，
+// 不作为节点输出；MaxDepth 不大于 0 时原样返回
+func limitDepth(sheets []xmind.Sheet, opts WriteOptions) []xmind.Sheet {
+	if opts.MaxDepth <= 0 {
+		return sheets
+	}
+	out := make([]xmind.Sheet, len(sheets))
+	for i, s := range sheets {
+		s.RootTopic = limitTopic(s.RootTopic, 1, opts)
+		out[i] = s
+	}
+	return out
+}
+
+func limitTopic(t xmind.Topic, level int, opts WriteOptions) xmind.Topic {
+	if level >= opts.MaxDepth {
+		if n := topicDepth(t) - 1; n > 0 {
+			t.Children, t.Detached = nil, nil
+			if opts.DepthNote {
+				t.OmittedLevels = n
+			}
+		}
+		return t
+	}
+	if t.Children != nil {
+		attached := make([]xmind.Topic, len(t.Children.Attached))
+		for i, c := range t.Children.Attached {
+			attached[i] = limitTopic(c, level+1, opts)
+		}
+		t.Children = &xmind.Children{Attached: attached}
+	}
+	if t.Detached != nil {
+		detached := make([]xmind.Topic, len(t.Detached))
+		for i, c := range t.Detached {
+			detached[i] = limitTopic(c, level+1, opts)
+		}
+		t.Detached = detached
+	}
+	return t
+}
+
+// topicDepth 返回以 t 为根的子树的层数，没有子节点时为 1
+func topicDepth(t xmind.Topic) int {
+	depth := 0
+	if t.Children != nil {
+		for _, c := range t.Children.Attached {
+			if d := topicDepth(c); d > depth {
+				depth = d
+			}
+		}
+	}
+	for _, c := range t.Detached {
+		if d := topicDepth(c); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// depthNote 返回被截断的节点下注明省略层数的文字，没有省略时为空
+func depthNote(t xmind.Topic) string {
+	if t.OmittedLevels <= 0 {
+		return ""
+	}
+	return i18n.Sprintf("…（还有 %d 层）", t.OmittedLevels)
+}
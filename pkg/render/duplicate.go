@@ -0,0 +1,61 @@
+package render
+
+import (
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// mergeDuplicates 合并同一节点下标题相同（忽略大小写与多余的空白）的子节点：后面节点的子节点依次移到第一个节点下，
+// 其余内容（备注、图标、链接等）以第一个节点为准；合并后的子节点同样递归合并，空标题的节点不合并
+func mergeDuplicates(sheets []xmind.Sheet) []xmind.Sheet {
+	out := make([]xmind.Sheet, len(sheets))
+	for i, s := range sheets {
+		s.RootTopic = mergeDuplicateChildren(s.RootTopic)
+		out[i] = s
+	}
+	return out
+}
+
+func mergeDuplicateChildren(t xmind.Topic) xmind.Topic {
+	if t.Children != nil {
+		t.Children = &xmind.Children{Attached: mergeDuplicateTopics(t.Children.Attached)}
+	}
+	if t.Detached != nil {
+		t.Detached = mergeDuplicateTopics(t.Detached)
+	}
+	return t
+}
+
+func mergeDuplicateTopics(topics []xmind.Topic) []xmind.Topic {
+	var out []xmind.Topic
+	first := map[string]int{}
+	for _, t := range topics {
+		key := strings.ToLower(strings.Join(strings.Fields(t.Title), " "))
+		i, ok := first[key]
+		if key == "" || !ok {
+			first[key] = len(out)
+			out = append(out, t)
+			continue
+		}
+		// 复制第一个节点的子节点列表，不修改输入的 sheets
+		d := &out[i]
+		var attached []xmind.Topic
+		if d.Children != nil {
+			attached = append(attached, d.Children.Attached...)
+		}
+		if t.Children != nil {
+			attached = append(attached, t.Children.Attached...)
+		}
+		if attached != nil {
+			d.Children = &xmind.Children{Attached: attached}
+		}
+		if len(t.Detached) > 0 {
+			d.Detached = append(append([]xmind.Topic(nil), d.Detached...), t.Detached...)
+		}
+	}
+	for i := range out {
+		out[i] = mergeDuplicateChildren(out[i])
+	}
+	return out
+}
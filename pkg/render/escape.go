@@ -0,0 +1,105 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+)
+
+// escapes 为 WriteOptions.Escape 支持的取值：smart 只转义会改变渲染结果的字符，all 转义所有特殊字符，off 原样输出
+var escapes = []string{"off", "smart", "all"}
+
+// Escapes 返回 WriteOptions.Escape 支持的取值
+func Escapes() []string {
+	return append([]string(nil), escapes...)
+}
+
+// markdownSpecial 为 all 模式下转义的字符
+const markdownSpecial = "\\`*_{}[]<>()#+-!|"
+
+var (
+	// blockStart 与 orderedStart 匹配行首会被识别为标题、引用或列表的标记
+	blockStart   = regexp.MustCompile(`^(\s*)(#{1,6}|>|[-+*])(\s|$)`)
+	orderedStart = regexp.MustCompile(`^(\s*\d{1,9})([.)])(\s|$)`)
+	// intraword 匹配两侧都是字母或数字的 _，这样的 _ 不会形成强调
+	intraword = regexp.MustCompile(`[\p{L}\p{N}]_+[\p{L}\p{N}]`)
+	// tagStart 匹配可能被识别为 HTML 标签或自动链接的 <
+	tagStart = regexp.MustCompile(`<[A-Za-z/!?]`)
+)
+
+// escapeMarkdown 按 mode 转义节点标题中的 Markdown 特殊字符
+func escapeMarkdown(text, mode string) string {
+	switch mode {
+	case "all":
+		var b strings.Builder
+		for _, r := range text {
+			if strings.ContainsRune(markdownSpecial, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		// 有序列表的 . 不在特殊字符中，单独处理
+		lines := strings.Split(b.String(), "\n")
+		for i, line := range lines {
+			lines[i] = escapeLineStart(line)
+		}
+		return strings.Join(lines, "\n")
+	case "smart":
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = escapeSmart(line)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return text
+}
+
+// escapeSmart 只转义会被识别为 Markdown 语法的字符：成对的 *、_ 与 `，链接、HTML 标签以及行首的标题、引用和列表标记
+func escapeSmart(line string) string {
+	var b strings.Builder
+	keep := map[int]bool{}
+	for _, m := range intraword.FindAllStringIndex(line, -1) {
+		for i := m[0]; i < m[1]; i++ {
+			if line[i] == '_' {
+				keep[i] = true
+			}
+		}
+	}
+	tags := map[int]bool{}
+	for _, m := range tagStart.FindAllStringIndex(line, -1) {
+		tags[m[0]] = true
+	}
+	pairs := func(c string) bool { return strings.Count(line, c) >= 2 }
+	link := strings.Contains(line, "](") || strings.Contains(line, "][")
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		escape := false
+		switch c {
+		case '\\':
+			escape = i+1 < len(line) && strings.IndexByte(markdownSpecial, line[i+1]) >= 0
+		case '*', '`':
+			escape = pairs(string(c))
+		case '_':
+			escape = !keep[i] && pairs("_")
+		case '[', ']':
+			escape = link
+		case '<':
+			escape = tags[i]
+		}
+		if escape {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return escapeLineStart(b.String())
+}
+
+// escapeLineStart 转义行首会被识别为标题、引用或列表的标记，已被转义的标记不会再匹配
+func escapeLineStart(line string) string {
+	if m := blockStart.FindStringSubmatchIndex(line); m != nil {
+		return line[:m[4]] + "\\" + line[m[4]:]
+	}
+	if m := orderedStart.FindStringSubmatchIndex(line); m != nil {
+		return line[:m[4]] + "\\" + line[m[4]:]
+	}
+	return line
+}
@@ -0,0 +1,74 @@
+package render
+
+import "github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+
+// EventKind 为转换过程中发生的事件的类型，见 WriteOptions.OnEvent
+type EventKind int
+
+const (
+	// EventTopicCount 在开始写出之前发生一次，Topics 为所有画布中将要写出的节点总数，可以作为进度的总量
+	EventTopicCount EventKind = iota
+	// EventSheetStart 在开始写出一个画布时发生，Sheet、Title 与 Topics 为该画布的序号、标题与节点数
+	EventSheetStart
+	// EventWarning 在写出时会丢失内容（见 Warnings）时发生，每类内容一次，Message 为说明
+	EventWarning
+	// EventResource 在提取出图片等资源之后发生，Resource 为资源在文件中的名称，Path 与 Size 为保存的位置与字节数
+	EventResource
+)
+
+// Event 为转换过程中的一个事件，只有与 Kind 相关的字段有值
+type Event struct {
+	Kind EventKind
+	// Sheet 为画布的序号，从 1 开始，与具体画布无关时为 0
+	Sheet int
+	// Title 为画布的标题，画布没有标题时为根节点的标题
+	Title string
+	// Topics 为节点数
+	Topics int
+	// Message 为警告的内容
+	Message string
+	// Resource、Path 与 Size 为提取的资源
+	Resource string
+	Path     string
+	Size     int64
+}
+
+// emit 在设置了 OnEvent 时调用它
+func (o WriteOptions) emit(e Event) {
+	if o.OnEvent != nil {
+		o.OnEvent(e)
+	}
+}
+
+// sheetStart 发出第 i 个画布（从 0 开始）的 EventSheetStart
+func (o WriteOptions) sheetStart(i int, s xmind.Sheet) {
+	if o.OnEvent == nil {
+		return
+	}
+	title := s.Title
+	if title == "" {
+		title = s.RootTopic.Title
+	}
+	o.OnEvent(Event{Kind: EventSheetStart, Sheet: i + 1, Title: title, Topics: s.TopicCount()})
+}
+
+// emitStart 在写出之前发出警告与节点总数，prepared 为已经按 opts 处理过节点的 sheets
+// 内置的 Markdown 与缩进文本在写到每个画布时发出 EventSheetStart，其他格式一次写出所有画布，在这里依次发出
+func emitStart(f Format, sheets, prepared []xmind.Sheet, opts WriteOptions) {
+	if opts.OnEvent == nil {
+		return
+	}
+	for _, msg := range Warnings(f.Name, sheets, opts) {
+		opts.emit(Event{Kind: EventWarning, Message: msg})
+	}
+	total := 0
+	for _, s := range prepared {
+		total += s.TopicCount()
+	}
+	opts.emit(Event{Kind: EventTopicCount, Topics: total})
+	if !f.sheetEvents {
+		for i, s := range prepared {
+			opts.sheetStart(i, s)
+		}
+	}
+}
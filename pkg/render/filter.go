@@ -0,0 +1,204 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// prepareSheets 按 opts 合并、筛选、平铺叶子节点、排序、编号节点并限制层数，返回实际要写出的 sheets
+func prepareSheets(sheets []xmind.Sheet, opts WriteOptions) []xmind.Sheet {
+	if opts.PruneEmpty {
+		sheets = pruneEmpty(sheets)
+	}
+	if opts.MergeDuplicates {
+		sheets = mergeDuplicates(sheets)
+	}
+	sheets = filterTopics(sheets, opts)
+	// 先按原来的结构筛选，平铺后的叶子节点再排序
+	if opts.LeavesOnly {
+		sheets = leavesOnly(sheets, opts.GroupLeaves)
+	}
+	sheets = sortSheets(sheets, opts.Sort)
+	// 编号在筛选与排序之后进行，保持连续
+	if opts.Numbering {
+		sheets = numberSheets(sheets)
+	}
+	return limitDepth(sheets, opts)
+}
+
+// pruneEmpty 去掉标题为空的节点：整个子树都为空时连同子树一起去掉，否则用其子节点代替该节点
+// 根节点始终保留
+func pruneEmpty(sheets []xmind.Sheet) []xmind.Sheet {
+	out := make([]xmind.Sheet, len(sheets))
+	for i, s := range sheets {
+		root := s.RootTopic
+		var attached []xmind.Topic
+		if root.Children != nil {
+			attached = pruneEmptyTopics(root.Children.Attached)
+		}
+		root.Children = nil
+		if attached != nil {
+			root.Children = &xmind.Children{Attached: attached}
+		}
+		root.Detached = pruneEmptyTopics(root.Detached)
+		s.RootTopic = root
+		out[i] = s
+	}
+	return out
+}
+
+// pruneEmptyTopics 依次处理同一层级的节点，返回去掉空节点后的列表
+func pruneEmptyTopics(topics []xmind.Topic) []xmind.Topic {
+	var out []xmind.Topic
+	for _, t := range topics {
+		var attached []xmind.Topic
+		if t.Children != nil {
+			attached = pruneEmptyTopics(t.Children.Attached)
+		}
+		detached := pruneEmptyTopics(t.Detached)
+		if isEmptyTopic(t) {
+			// 空节点下仍有内容时，子节点提升一级放在空节点原来的位置
+			out = append(out, attached...)
+			out = append(out, detached...)
+			continue
+		}
+		t.Children = nil
+		if attached != nil {
+			t.Children = &xmind.Children{Attached: attached}
+		}
+		t.Detached = detached
+		out = append(out, t)
+	}
+	return out
+}
+
+// isEmptyTopic 判断节点是否只是没有填写内容的占位节点：标题为空白，也没有链接与图片
+func isEmptyTopic(t xmind.Topic) bool {
+	return strings.TrimSpace(t.Title) == "" && t.Href == "" && t.Image == nil
+}
+
+// filterTopics 去掉标题与 opts.Exclude 匹配的节点及其子树，再依次只保留标题与 opts.Include 匹配、
+// 带有 opts.FilterMarkers 中的图标与带有 opts.FilterLabels 中的标签的节点
+// 保留的节点连同其所有子节点与上级节点一起输出，根节点被排除时整个 sheet 不输出
+func filterTopics(sheets []xmind.Sheet, opts WriteOptions) []xmind.Sheet {
+	var filters []func(xmind.Topic) bool
+	if len(opts.Include) > 0 {
+		filters = append(filters, func(t xmind.Topic) bool { return matchAny(opts.Include, t.Title) })
+	}
+	if len(opts.FilterMarkers) > 0 {
+		filters = append(filters, func(t xmind.Topic) bool { return hasMarker(t, opts.FilterMarkers) })
+	}
+	if len(opts.FilterLabels) > 0 {
+		filters = append(filters, func(t xmind.Topic) bool { return hasLabel(t, opts.FilterLabels) })
+	}
+	if len(filters) == 0 && len(opts.Exclude) == 0 {
+		return sheets
+	}
+	out := make([]xmind.Sheet, 0, len(sheets))
+	for _, s := range sheets {
+		if matchAny(opts.Exclude, s.RootTopic.Title) {
+			continue
+		}
+		root := pruneTopic(s.RootTopic, func(t xmind.Topic) bool { return !matchAny(opts.Exclude, t.Title) })
+		for _, match := range filters {
+			if !match(root) {
+				root.Children, root.Detached = keepTopics(root, match)
+			}
+		}
+		s.RootTopic = root
+		out = append(out, s)
+	}
+	return out
+}
+
+// pruneTopic 返回只保留 keep 为 true 的子节点的 t，keep 为 false 的节点连同子树一起去掉
+func pruneTopic(t xmind.Topic, keep func(xmind.Topic) bool) xmind.Topic {
+	if t.Children != nil {
+		var attached []xmind.Topic
+		for _, c := range t.Children.Attached {
+			if keep(c) {
+				attached = append(attached, pruneTopic(c, keep))
+			}
+		}
+		t.Children = nil
+		if attached != nil {
+			t.Children = &xmind.Children{Attached: attached}
+		}
+	}
+	if t.Detached != nil {
+		var detached []xmind.Topic
+		for _, c := range t.Detached {
+			if keep(c) {
+				detached = append(detached, pruneTopic(c, keep))
+			}
+		}
+		t.Detached = detached
+	}
+	return t
+}
+
+// keepTopics 返回 t 的子节点中 match 为 true 的节点及其上级节点，与 match 匹配的节点保留整个子树
+func keepTopics(t xmind.Topic, match func(xmind.Topic) bool) (*xmind.Children, []xmind.Topic) {
+	var attached, detached []xmind.Topic
+	if t.Children != nil {
+		for _, c := range t.Children.Attached {
+			if k, ok := keepMatched(c, match); ok {
+				attached = append(attached, k)
+			}
+		}
+	}
+	for _, c := range t.Detached {
+		if k, ok := keepMatched(c, match); ok {
+			detached = append(detached, k)
+		}
+	}
+	var children *xmind.Children
+	if attached != nil {
+		children = &xmind.Children{Attached: attached}
+	}
+	return children, detached
+}
+
+func keepMatched(t xmind.Topic, match func(xmind.Topic) bool) (xmind.Topic, bool) {
+	if match(t) {
+		return t, true
+	}
+	t.Children, t.Detached = keepTopics(t, match)
+	return t, t.Children != nil || t.Detached != nil
+}
+
+// hasMarker 判断节点是否带有 ids 中的图标，如 flag 同时匹配 flag-red 等同一组的图标
+func hasMarker(t xmind.Topic, ids []string) bool {
+	for _, m := range t.Markers {
+		for _, id := range ids {
+			if m.MarkerID == id || strings.HasPrefix(m.MarkerID, id+"-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasLabel 判断节点是否带有 labels 中的标签
+func hasLabel(t xmind.Topic, labels []string) bool {
+	for _, l := range t.Labels {
+		for _, want := range labels {
+			if strings.TrimSpace(l) == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchAny 判断 s 是否与 patterns 中的任意一个匹配
+func matchAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// Heading 为 Markdown 输出中的一个标题，见 MarkdownHeadings
+type Heading struct {
+	// Level 为标题级别（1-6），Layer 为对应节点的层数，根节点为第 1 层，WriteOptions.TOC 生成的目录标题为 0
+	Level int
+	Layer int
+	// Text 为标题的文字，包括图标映射的文本，多行的标题合并为一行
+	Text string
+	// IDs 为内容位于该标题下的节点与画布的 ID：标题对应的节点、其后不输出为标题的节点，根节点的标题还包括画布的 ID
+	IDs []string
+
+	// markdown 为 Text 按 WriteOptions.Escape 转义后的文本，用于目录中的链接；parent 为上级节点中最近的标题的序号，没有时为 -1
+	markdown string
+	parent   int
+}
+
+// MarkdownHeadings 按输出的顺序返回 sheets 按 opts 输出为 Markdown 时的全部标题，
+// 用于把输出拆分为多个文件时计算每个节点所在的文件与锚点
+func MarkdownHeadings(sheets []xmind.Sheet, opts WriteOptions) []Heading {
+	return markdownHeadings(prepareSheets(sheets, opts), opts)
+}
+
+// HeadingAnchors 返回同一个文件中依次出现的 headings 的锚点，规则与 GitHub 相同：
+// 转换为小写，去掉标点，空格替换为 -，重复的锚点依次加上 -1、-2 等后缀
+func HeadingAnchors(headings []Heading) []string {
+	seen := map[string]int{}
+	anchors := make([]string, len(headings))
+	for i, h := range headings {
+		anchors[i] = anchorSlug(h.Text, seen)
+	}
+	return anchors
+}
+
+// markdownHeadings 返回已经按 opts 处理过节点的 sheets 中的标题，与 writeMarkdown 的输出一致
+func markdownHeadings(sheets []xmind.Sheet, opts WriteOptions) []Heading {
+	var headings []Heading
+	if opts.TOC {
+		title := i18n.T("目录")
+		headings = append(headings, Heading{Level: len(headingPrefix(0, opts)), Text: title, markdown: title, parent: -1})
+	}
+	var walk func(t xmind.Topic, layer, parent int)
+	walk = func(t xmind.Topic, layer, parent int) {
+		// 根节点总是输出为标题
+		if layer == 1 || headingTopic(t, opts) {
+			text := strings.Join(strings.Fields(t.Title), " ")
+			headings = append(headings, Heading{
+				Level:    len(headingPrefix(layer-1, opts)),
+				Layer:    layer,
+				Text:     markerPrefix(t, opts) + text,
+				markdown: markerPrefix(t, opts) + escapeMarkdown(text, opts.Escape),
+				parent:   parent,
+			})
+			parent = len(headings) - 1
+		}
+		if t.ID != "" {
+			last := &headings[len(headings)-1]
+			last.IDs = append(last.IDs, t.ID)
+		}
+		if t.Children != nil {
+			for _, c := range t.Children.Attached {
+				walk(c, layer+1, parent)
+			}
+		}
+		for _, c := range t.Detached {
+			walk(c, layer+1, parent)
+		}
+	}
+	for _, s := range sheets {
+		root := len(headings)
+		walk(s.RootTopic, 1, -1)
+		if s.ID != "" {
+			headings[root].IDs = append(headings[root].IDs, s.ID)
+		}
+	}
+	return headings
+}
+
+// headingTopic 判断根节点以外的节点是否输出为标题，与 writeTopicMarkdown 一致
+func headingTopic(t xmind.Topic, opts WriteOptions) bool {
+	if isLeaf(t) && (opts.LeafStyle == "paragraph" || opts.LeafStyle == "bullet") {
+		return false
+	}
+	return t.Href == ""
+}
+
+// linkTarget 返回链接 href 实际输出的地址，xmind:#ID 形式的内部链接在 opts.Links 中有对应的值时替换为该值
+func linkTarget(href string, opts WriteOptions) string {
+	if id := strings.TrimPrefix(href, "xmind:#"); id != href {
+		if target, ok := opts.Links[id]; ok {
+			return target
+		}
+	}
+	return href
+}
+
+// anchorSlug 按 GitHub 的规则生成标题的锚点，seen 记录已经使用过的锚点
+func anchorSlug(title string, seen map[string]int) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, strings.TrimSpace(title))
+	n := seen[slug]
+	seen[slug] = n + 1
+	if n > 0 {
+		return fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
@@ -0,0 +1,53 @@
+package render
+
+import "github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+
+// leavesOnly 将每个画布改写为根节点下只有叶子节点的平铺列表，叶子节点按原来的先后顺序排列，
+// 分离的节点排在其他子节点之后；group 为 true 时以第一层分支为分组，本身就是叶子节点的第一层节点
+// 直接列在根节点下并排在所有分组之前，使 Markdown 中这些列表项不会落在某个分组的标题之下
+// 没有子节点的根节点保持原样
+func leavesOnly(sheets []xmind.Sheet, group bool) []xmind.Sheet {
+	out := make([]xmind.Sheet, len(sheets))
+	for i, s := range sheets {
+		root := s.RootTopic
+		var attached, groups []xmind.Topic
+		for _, t := range childTopics(root) {
+			if !group || isLeaf(t) {
+				attached = appendLeaves(attached, t)
+				continue
+			}
+			branch := t
+			branch.Detached = nil
+			branch.Children = &xmind.Children{Attached: appendLeaves(nil, t)}
+			groups = append(groups, branch)
+		}
+		attached = append(attached, groups...)
+		root.Children, root.Detached = nil, nil
+		if attached != nil {
+			root.Children = &xmind.Children{Attached: attached}
+		}
+		s.RootTopic = root
+		out[i] = s
+	}
+	return out
+}
+
+// appendLeaves 将 t 子树中的叶子节点依次追加到 leaves
+func appendLeaves(leaves []xmind.Topic, t xmind.Topic) []xmind.Topic {
+	if isLeaf(t) {
+		return append(leaves, t)
+	}
+	for _, c := range childTopics(t) {
+		leaves = appendLeaves(leaves, c)
+	}
+	return leaves
+}
+
+// childTopics 返回 t 的子节点，分离的节点排在最后
+func childTopics(t xmind.Topic) []xmind.Topic {
+	var children []xmind.Topic
+	if t.Children != nil {
+		children = append(children, t.Children.Attached...)
+	}
+	return append(children, t.Detached...)
+}
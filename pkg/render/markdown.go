@@ -0,0 +1,224 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// WriteMarkdown 针对每个 sheet 输出 Markdown 内容，不检查写入错误，需要检查时使用 WriteAs
+func WriteMarkdown(w io.Writer, sheets []xmind.Sheet) {
+	WriteMarkdownOptions(w, sheets, WriteOptions{})
+}
+
+// WriteMarkdownOptions 按 opts 针对每个 sheet 输出 Markdown 内容
+func WriteMarkdownOptions(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) {
+	writeMarkdown(w, prepareSheets(sheets, opts), opts)
+}
+
+// writeMarkdown 输出已经按 opts 筛选过节点的 sheets
+func writeMarkdown(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) {
+	if opts.TOC {
+		writeTOCMarkdown(w, sheets, opts)
+	}
+	for i, sheet := range sheets {
+		opts.sheetStart(i, sheet)
+		// 根节点默认使用 h1 显示，opts.HeadingStart 可以调整；带链接的根节点（如 split 拆分出的分支）输出为链接标题
+		root := markerPrefix(sheet.RootTopic, opts) + escapeMarkdown(sheet.RootTopic.Title, opts.Escape)
+		if sheet.RootTopic.Href != "" {
+			root = fmt.Sprintf("%s[%s](%s)", markerPrefix(sheet.RootTopic, opts), escapeMarkdown(strings.ReplaceAll(sheet.RootTopic.Title, "\n", ""), opts.Escape), linkTarget(sheet.RootTopic.Href, opts))
+		}
+		fmt.Fprintf(w, "%s %s\n\n", headingPrefix(0, opts), root)
+		writeNotesMarkdown(w, sheet.RootTopic, "", opts)
+		writeDepthNoteMarkdown(w, sheet.RootTopic)
+
+		// 输出 children.attached 节点，从递归层级0开始（对应比根节点低一级的标题）
+		if sheet.RootTopic.Children != nil {
+			writeTopicsMarkdown(w, sheet.RootTopic.Children.Attached, 0, opts)
+		}
+		// 输出 detached 节点（如果有），同样从层级0开始
+		writeTopicsMarkdown(w, sheet.RootTopic.Detached, 0, opts)
+		// 分隔每个 sheet
+		fmt.Fprint(w, "\n\n")
+	}
+}
+
+// writeTopicsMarkdown 依次输出同一层级的节点，连续的列表项之后补一个空行结束列表
+func writeTopicsMarkdown(w io.Writer, topics []xmind.Topic, indent int, opts WriteOptions) {
+	inList := false
+	for _, topic := range topics {
+		bullet := isLeaf(topic) && opts.LeafStyle == "bullet"
+		if inList && !bullet {
+			fmt.Fprint(w, "\n")
+		}
+		writeTopicMarkdown(w, topic, indent, opts)
+		inList = bullet
+	}
+	if inList {
+		fmt.Fprint(w, "\n")
+	}
+}
+
+// writeTopicMarkdown 根据节点类型和层级递归输出 Markdown 格式
+func writeTopicMarkdown(w io.Writer, topic xmind.Topic, indent int, opts WriteOptions) {
+	prefix := markerPrefix(topic, opts)
+	text := prefix + escapeMarkdown(topic.Title, opts.Escape)
+	if topic.Href != "" {
+		text = fmt.Sprintf("%s[%s](%s)", prefix, escapeMarkdown(strings.ReplaceAll(topic.Title, "\n", ""), opts.Escape), linkTarget(topic.Href, opts))
+	}
+	switch {
+	case isLeaf(topic) && opts.LeafStyle == "paragraph":
+		// 叶子节点输出为上级标题下的段落
+		fmt.Fprintf(w, "%s\n\n", text)
+		writeNotesMarkdown(w, topic, "", opts)
+		writeDepthNoteMarkdown(w, topic)
+	case isLeaf(topic) && opts.LeafStyle == "bullet":
+		// 叶子节点输出为上级标题下的列表项，多行标题的后续行缩进以留在列表项中，省略层数的说明写在同一行
+		if note := depthNote(topic); note != "" {
+			text += " " + note
+		}
+		fmt.Fprintf(w, "%s %s\n", bulletMarker(opts), strings.ReplaceAll(text, "\n", "\n"+listIndent(opts)))
+		writeNotesMarkdown(w, topic, listIndent(opts), opts)
+	case topic.Href != "":
+		// 超链接节点：依然普通文本输出
+		//indentStr := strings.Repeat("  ", indent)
+		//fmt.Fprintf(w, "%s- [%s](%s)\n", indentStr, topic.Title, topic.Href)
+		// 叶子节点不输出为标题时补一个空行，避免下面的段落与链接连成一段
+		end := "\n"
+		if opts.LeafStyle == "paragraph" || opts.LeafStyle == "bullet" {
+			end = "\n\n"
+		}
+		if note := depthNote(topic); note != "" {
+			text += " " + note
+		}
+		fmt.Fprint(w, text+end)
+		writeNotesMarkdown(w, topic, "", opts)
+	default:
+		// 非超链接节点：使用标题输出，层级为根节点的层级加 indent+1，最大为 h6
+		fmt.Fprintf(w, "%s %s\n\n", headingPrefix(indent+1, opts), text)
+		writeNotesMarkdown(w, topic, "", opts)
+		writeDepthNoteMarkdown(w, topic)
+	}
+
+	// 递归输出 attached 子节点（层级加1）
+	if topic.Children != nil {
+		writeTopicsMarkdown(w, topic.Children.Attached, indent+1, opts)
+	}
+	// 递归输出 detached 节点（层级加1）
+	writeTopicsMarkdown(w, topic.Detached, indent+1, opts)
+}
+
+// defaultTOCDepth 为 WriteOptions.TOCDepth 未指定时目录列出的层数
+const defaultTOCDepth = 3
+
+// writeTOCMarkdown 输出链接到各级标题的目录，目录本身为与根节点同级的“目录”标题
+// 锚点与 GitHub 生成的相同，没有输出为标题的节点（链接节点与不按标题输出的叶子节点）与空标题不列出，其子节点提升一级
+func writeTOCMarkdown(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) {
+	depth := opts.TOCDepth
+	if depth < 1 {
+		depth = defaultTOCDepth
+	}
+	headings := markdownHeadings(sheets, opts)
+	anchors := HeadingAnchors(headings)
+	fmt.Fprintf(w, "%s %s\n\n", headingPrefix(0, opts), headings[0].Text)
+	// indents 为每个标题列出时的缩进，即上级标题中列出的个数
+	indents := make([]int, len(headings))
+	listed := func(h Heading) bool { return h.Layer >= 1 && h.Layer <= depth && h.Text != "" }
+	for i, h := range headings {
+		if h.parent >= 0 {
+			indents[i] = indents[h.parent]
+			if listed(headings[h.parent]) {
+				indents[i]++
+			}
+		}
+		if !listed(h) {
+			continue
+		}
+		link := "#" + anchors[i]
+		if i < len(opts.TOCLinks) && opts.TOCLinks[i] != "" {
+			link = opts.TOCLinks[i]
+		}
+		fmt.Fprintf(w, "%s%s [%s](%s)\n", strings.Repeat(listIndent(opts), indents[i]), bulletMarker(opts), h.markdown, link)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// writeNotesMarkdown 在 opts.Notes 为 true 时将节点备注输出为引用块，每行前加上 indent
+// 列表项中的备注紧跟列表项，其余位置的备注后补一个空行，避免下面的内容并入引用块
+func writeNotesMarkdown(w io.Writer, topic xmind.Topic, indent string, opts WriteOptions) {
+	if !opts.Notes || topic.Notes == nil || topic.Notes.Plain == nil {
+		return
+	}
+	content := strings.TrimRight(strings.ReplaceAll(topic.Notes.Plain.Content, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if line == "" {
+			fmt.Fprintf(w, "%s>\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s> %s\n", indent, escapeMarkdown(line, opts.Escape))
+	}
+	if indent == "" {
+		fmt.Fprint(w, "\n")
+	}
+}
+
+// writeDepthNoteMarkdown 在被截断的节点下以段落注明省略的层数，见 depthNote
+func writeDepthNoteMarkdown(w io.Writer, topic xmind.Topic) {
+	if note := depthNote(topic); note != "" {
+		fmt.Fprintf(w, "%s\n\n", note)
+	}
+}
+
+// bulletMarker 返回列表项的标记，默认为 -
+func bulletMarker(opts WriteOptions) string {
+	if opts.Bullet == "" {
+		return "-"
+	}
+	return opts.Bullet
+}
+
+// listIndent 返回列表中一级缩进对应的文本，默认为两个空格
+func listIndent(opts WriteOptions) string {
+	switch opts.ListIndent {
+	case "4":
+		return "    "
+	case "tab":
+		return "\t"
+	}
+	return "  "
+}
+
+// isLeaf 判断节点是否没有任何子节点
+func isLeaf(t xmind.Topic) bool {
+	return (t.Children == nil || len(t.Children.Attached) == 0) && len(t.Detached) == 0
+}
+
+// headingPrefix 返回比根节点低 depth 级的标题标记，最大为 h6
+func headingPrefix(depth int, opts WriteOptions) string {
+	start := opts.HeadingStart
+	if start < 1 {
+		start = 1
+	}
+	level := start + depth
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
+
+// markerPrefix 返回节点图标按 opts.Markers 映射后的文本，每个图标后跟一个空格
+func markerPrefix(topic xmind.Topic, opts WriteOptions) string {
+	var b strings.Builder
+	for _, m := range topic.Markers {
+		if text, ok := opts.Markers[m.MarkerID]; ok && text != "" {
+			b.WriteString(text)
+			b.WriteString(" ")
+		}
+	}
+	return b.String()
+}
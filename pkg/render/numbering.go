@@ -0,0 +1,44 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// numberSheets 在根节点以外的每个节点标题前加上层级编号，如 1、1.1、1.1.2，与 XMind 中的编号设置无关
+// 同一节点下的 attached 与 detached 节点依次编号，编号与标题之间以空格分隔
+func numberSheets(sheets []xmind.Sheet) []xmind.Sheet {
+	out := make([]xmind.Sheet, len(sheets))
+	for i, s := range sheets {
+		s.RootTopic = numberChildren(s.RootTopic, "")
+		out[i] = s
+	}
+	return out
+}
+
+// numberChildren 为 t 的子节点编号，prefix 为 t 的编号（根节点为空）
+func numberChildren(t xmind.Topic, prefix string) xmind.Topic {
+	n := 0
+	number := func(topics []xmind.Topic) []xmind.Topic {
+		out := make([]xmind.Topic, len(topics))
+		for i, c := range topics {
+			n++
+			num := strconv.Itoa(n)
+			if prefix != "" {
+				num = prefix + "." + num
+			}
+			c.Title = strings.TrimRight(num+" "+c.Title, " ")
+			out[i] = numberChildren(c, num)
+		}
+		return out
+	}
+	if t.Children != nil {
+		t.Children = &xmind.Children{Attached: number(t.Children.Attached)}
+	}
+	if t.Detached != nil {
+		t.Detached = number(t.Detached)
+	}
+	return t
+}
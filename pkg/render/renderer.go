@@ -0,0 +1,77 @@
+package render
+
+import (
+	"io"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// Renderer 将整个 Sheet 列表写出为一种输出格式
+// 传入的 sheets 已经按 WriteOptions 完成了节点的筛选、排序与层数限制，Renderer 只需处理格式本身
+type Renderer interface {
+	Render(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error
+}
+
+// RendererFunc 使普通函数可以作为 Renderer 使用
+type RendererFunc func(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error
+
+// Render 调用 f(w, sheets, opts)
+func (f RendererFunc) Render(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+	return f(w, sheets, opts)
+}
+
+// Format 描述一种可写出的输出格式
+type Format struct {
+	// Name 为格式名称，如 md，用于 WriteAs 与命令行的 -to 参数
+	Name string
+	// Ext 为输出文件的扩展名，如 .md
+	Ext string
+	// Renderer 负责写出该格式
+	Renderer Renderer
+	// Drops 为写出时不会保留的内容，见 Warnings
+	Drops []Feature
+	// Binary 表示输出不是文本，不使用 WriteOptions 中的 EOL、BOM 与 Header
+	Binary bool
+
+	// sheetEvents 表示 Renderer 在写到每个画布时自己发出 EventSheetStart，见 emitStart
+	sheetEvents bool
+}
+
+// outputFormats 为所有支持的输出格式，第一个为默认格式
+var outputFormats = []Format{
+	{Name: "md", Ext: ".md", Renderer: RendererFunc(func(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+		writeMarkdown(w, sheets, opts)
+		return nil
+	}), Drops: []Feature{FeatureNotes, FeatureLabels, FeatureImages, FeatureMarkers}, sheetEvents: true},
+	{Name: "xmind", Ext: ".xmind", Renderer: RendererFunc(func(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+		return WriteXMind(w, sheets)
+	}), Drops: []Feature{FeatureImages}, Binary: true},
+	{Name: "txt", Ext: ".txt", Renderer: RendererFunc(func(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+		return writeText(w, sheets, opts)
+	}), Drops: []Feature{FeatureNotes, FeatureLabels, FeatureImages, FeatureMarkers, FeatureLinks}, sheetEvents: true},
+	// 中间格式为 JSON，开头的内容、BOM 与 CRLF 换行都会使其无法读回，因此按二进制格式处理
+	{Name: "ir", Ext: ".x2m", Renderer: RendererFunc(func(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+		return xmind.WriteIR(w, sheets)
+	}), Binary: true},
+	// 模板可以输出任意内容，是否丢失备注等由模板决定，因此不列出 Drops
+	{Name: "template", Ext: ".txt", Renderer: RendererFunc(func(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+		if opts.Template == nil {
+			return i18n.Errorf("template 格式需要指定模板")
+		}
+		return WriteTemplate(w, sheets, opts.Template)
+	})},
+}
+
+// Register 注册新的输出格式，注册后即可用于 WriteAs、OutputFormats 与 Warnings
+// 格式名称为空、已被注册或没有 Renderer 时返回错误，应在 init 中调用
+func Register(f Format) error {
+	if f.Name == "" || f.Renderer == nil {
+		return i18n.Errorf("输出格式缺少名称或 Renderer")
+	}
+	if _, err := lookupOutput(f.Name); err == nil {
+		return i18n.Errorf("输出格式 %s 已被注册", f.Name)
+	}
+	outputFormats = append(outputFormats, f)
+	return nil
+}
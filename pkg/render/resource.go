@@ -0,0 +1,58 @@
+package render
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// ExtractResources 将 .xmind 文件中的所有资源（见 xmind.Resources）保存到目录 dir 中，保持压缩包中的目录结构，
+// 文件名见 Resource.FileName，返回保存的路径；每保存一个资源发出一次 EventResource
+// create 用于创建每个文件，可以在其中检查文件是否已存在，为 nil 时直接创建并覆盖已存在的文件
+func ExtractResources(ctx context.Context, ra io.ReaderAt, size int64, dir string, create func(path string) (io.WriteCloser, error), opts WriteOptions) ([]string, error) {
+	res, err := xmind.Resources(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	if create == nil {
+		create = func(p string) (io.WriteCloser, error) { return os.Create(p) }
+	}
+	var paths []string
+	for _, r := range res {
+		if err := ctx.Err(); err != nil {
+			return paths, err
+		}
+		p, err := xmind.ResourcePath(dir, r.FileName())
+		if err != nil {
+			return paths, err
+		}
+		data, err := xmind.ReadResource(ra, size, r.Name)
+		if err != nil {
+			return paths, err
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return paths, i18n.Errorf("创建目录失败: %w", err)
+		}
+		w, err := create(p)
+		if err != nil {
+			return paths, err
+		}
+		_, err = w.Write(data)
+		// 写入失败时放弃支持放弃的输出（如先写入临时文件再重命名的文件），不留下不完整的文件
+		if a, ok := w.(interface{ Abort() }); ok && err != nil {
+			a.Abort()
+		} else if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return paths, i18n.Errorf("写入 %s 失败: %w", p, err)
+		}
+		paths = append(paths, p)
+		opts.emit(Event{Kind: EventResource, Resource: r.Name, Path: p, Size: int64(len(data))})
+	}
+	return paths, nil
+}
@@ -0,0 +1,82 @@
+package render
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// sorts 为 WriteOptions.Sort 支持的取值：none 保持原来的顺序，alpha 按标题排序，marker 按图标排序
+var sorts = []string{"none", "alpha", "marker"}
+
+// Sorts 返回 WriteOptions.Sort 支持的取值
+func Sorts() []string {
+	return append([]string(nil), sorts...)
+}
+
+// sortSheets 按 mode 重新排列每个节点的子节点，attached 与 detached 节点分别排序，顺序相同的节点保持原来的顺序
+func sortSheets(sheets []xmind.Sheet, mode string) []xmind.Sheet {
+	var less func(a, b xmind.Topic) bool
+	switch mode {
+	case "alpha":
+		less = func(a, b xmind.Topic) bool {
+			return strings.ToLower(strings.TrimSpace(a.Title)) < strings.ToLower(strings.TrimSpace(b.Title))
+		}
+	case "marker":
+		less = markerLess
+	default:
+		return sheets
+	}
+	out := make([]xmind.Sheet, len(sheets))
+	for i, s := range sheets {
+		s.RootTopic = sortTopic(s.RootTopic, less)
+		out[i] = s
+	}
+	return out
+}
+
+func sortTopic(t xmind.Topic, less func(a, b xmind.Topic) bool) xmind.Topic {
+	if t.Children != nil {
+		t.Children = &xmind.Children{Attached: sortTopics(t.Children.Attached, less)}
+	}
+	if t.Detached != nil {
+		t.Detached = sortTopics(t.Detached, less)
+	}
+	return t
+}
+
+func sortTopics(topics []xmind.Topic, less func(a, b xmind.Topic) bool) []xmind.Topic {
+	out := make([]xmind.Topic, len(topics))
+	for i, t := range topics {
+		out[i] = sortTopic(t, less)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out
+}
+
+// markerLess 按第一个图标排序：同一组的图标按编号排列（如 priority-1 在 priority-2 与 priority-10 之前），
+// 不同组按组名排列，没有图标的节点排在最后
+func markerLess(a, b xmind.Topic) bool {
+	if len(a.Markers) == 0 || len(b.Markers) == 0 {
+		return len(a.Markers) > 0 && len(b.Markers) == 0
+	}
+	ga, na := splitMarker(a.Markers[0].MarkerID)
+	gb, nb := splitMarker(b.Markers[0].MarkerID)
+	if ga != gb {
+		return ga < gb
+	}
+	return na < nb
+}
+
+// splitMarker 将图标 ID 拆分为组名与编号，如 priority-2 拆分为 priority 与 2，没有编号时编号为 0，
+// 如 flag-red 的编号为 0，组名仍为 flag-red
+func splitMarker(id string) (string, int) {
+	if i := strings.LastIndex(id, "-"); i >= 0 {
+		if n, err := strconv.Atoi(id[i+1:]); err == nil {
+			return id[:i], n
+		}
+	}
+	return id, 0
+}
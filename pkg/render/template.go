@@ -0,0 +1,117 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// TemplateData 为 template 格式中模板执行时的数据，即模板中的 .
+type TemplateData struct {
+	Sheets []TemplateSheet
+}
+
+// TemplateSheet 为模板中的一个画布
+type TemplateSheet struct {
+	Title string
+	Root  *TemplateNode
+}
+
+// TemplateNode 为模板中的一个节点
+type TemplateNode struct {
+	Title string
+	// Depth 为节点所在的层数，根节点为 1
+	Depth int
+	// Note 为节点备注的纯文本内容
+	Note   string
+	Labels []string
+	Href   string
+	// Markers 为节点上图标的 ID，如 priority-1
+	Markers []string
+	// Children 为子节点，分离的节点排在后面
+	Children []*TemplateNode
+	// Topic 为原始节点，用于访问其他字段
+	Topic *xmind.Topic
+}
+
+// templateFuncs 为模板中除内置函数外可以使用的函数
+var templateFuncs = template.FuncMap{
+	"add":     func(a, b int) int { return a + b },
+	"sub":     func(a, b int) int { return a - b },
+	"repeat":  repeat,
+	"join":    func(sep string, s []string) string { return strings.Join(s, sep) },
+	"lines":   func(s string) []string { return strings.Split(s, "\n") },
+	"trim":    strings.TrimSpace,
+	"flatten": flattenNodes,
+}
+
+// ParseTemplate 解析 template 格式使用的模板，模板中除内置函数外还可以使用:
+//
+//	add、sub    整数加减，如 {{sub .Depth 1}}
+//	repeat      重复字符串，如 {{repeat "  " .Depth}}
+//	join        以分隔符连接字符串列表，如 {{join ", " .Labels}}
+//	lines       将字符串按行拆分
+//	trim        去掉首尾空白
+//	flatten     按先序返回节点及其全部下级节点，不需要递归即可遍历整棵树
+func ParseTemplate(name, text string) (*template.Template, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, i18n.Errorf("无效的模板: %v", err)
+	}
+	return t, nil
+}
+
+// WriteTemplate 以 sheets 组成的 TemplateData 执行模板 t，模板应由 ParseTemplate 解析
+func WriteTemplate(w io.Writer, sheets []xmind.Sheet, t *template.Template) error {
+	data := TemplateData{Sheets: make([]TemplateSheet, 0, len(sheets))}
+	for i := range sheets {
+		data.Sheets = append(data.Sheets, TemplateSheet{Title: sheets[i].Title, Root: templateNode(&sheets[i].RootTopic, 1)})
+	}
+	if err := t.Execute(w, data); err != nil {
+		return i18n.Errorf("执行模板失败: %v", err)
+	}
+	return nil
+}
+
+// templateNode 将 t 及其下级节点转换为模板中的节点
+func templateNode(t *xmind.Topic, depth int) *TemplateNode {
+	n := &TemplateNode{Title: t.Title, Depth: depth, Labels: t.Labels, Href: t.Href, Topic: t}
+	if t.Notes != nil && t.Notes.Plain != nil {
+		n.Note = t.Notes.Plain.Content
+	}
+	for _, m := range t.Markers {
+		n.Markers = append(n.Markers, m.MarkerID)
+	}
+	if t.Children != nil {
+		for i := range t.Children.Attached {
+			n.Children = append(n.Children, templateNode(&t.Children.Attached[i], depth+1))
+		}
+	}
+	for i := range t.Detached {
+		n.Children = append(n.Children, templateNode(&t.Detached[i], depth+1))
+	}
+	return n
+}
+
+// repeat 将 s 重复 n 次，n 为负数时返回空字符串而不是像 strings.Repeat 一样 panic
+func repeat(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(s, n)
+}
+
+// flattenNodes 按先序返回 n 及其全部下级节点
+func flattenNodes(n *TemplateNode) []*TemplateNode {
+	if n == nil {
+		return nil
+	}
+	nodes := []*TemplateNode{n}
+	for _, c := range n.Children {
+		nodes = append(nodes, flattenNodes(c)...)
+	}
+	return nodes
+}
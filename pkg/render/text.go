@@ -0,0 +1,41 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// WriteText 将 Sheet 列表写出为缩进文本，每级缩进一个 Tab，可被 parseText 重新读取
+func WriteText(w io.Writer, sheets []xmind.Sheet) error {
+	return writeText(w, sheets, WriteOptions{})
+}
+
+func writeText(w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+	bw := bufio.NewWriter(w)
+	for i, sheet := range sheets {
+		opts.sheetStart(i, sheet)
+		writeTextTopic(bw, sheet.RootTopic, 0)
+	}
+	return bw.Flush()
+}
+
+// writeTextTopic 递归写出节点，attached 与 detached 节点都作为下一级，省略层数的说明写在被截断的节点的同一行
+func writeTextTopic(w *bufio.Writer, t xmind.Topic, level int) {
+	title := strings.Join(strings.Fields(t.Title), " ")
+	if note := depthNote(t); note != "" {
+		title += " " + note
+	}
+	fmt.Fprintf(w, "%s%s\n", strings.Repeat("\t", level), title)
+	if t.Children != nil {
+		for _, c := range t.Children.Attached {
+			writeTextTopic(w, c, level+1)
+		}
+	}
+	for _, c := range t.Detached {
+		writeTextTopic(w, c, level+1)
+	}
+}
@@ -0,0 +1,77 @@
+package render
+
+import (
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// Feature 为节点中可能在转换时丢失的一类内容，用于 Format.Drops
+type Feature int
+
+const (
+	// FeatureNotes 为节点备注
+	FeatureNotes Feature = iota
+	// FeatureLabels 为节点标签
+	FeatureLabels
+	// FeatureImages 为节点图片
+	FeatureImages
+	// FeatureMarkers 为节点图标
+	FeatureMarkers
+	// FeatureLinks 为节点链接
+	FeatureLinks
+)
+
+// Warnings 返回按 format 写出 sheets 时会丢失的内容，如备注、标签与图片，每类内容一条说明
+func Warnings(format string, sheets []xmind.Sheet, opts WriteOptions) []string {
+	f, err := lookupOutput(format)
+	if err != nil {
+		return nil
+	}
+	var counts [FeatureLinks + 1]int
+	// 被筛选掉或超过最大层数的节点本来就不输出，不计入丢失的内容
+	for _, s := range prepareSheets(sheets, opts) {
+		s.Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+			// Markdown 中 opts.Notes 为 true 时备注会输出
+			if t.Notes != nil && t.Notes.Plain != nil && t.Notes.Plain.Content != "" && !(opts.Notes && format == "md") {
+				counts[FeatureNotes]++
+			}
+			if len(t.Labels) > 0 {
+				counts[FeatureLabels]++
+			}
+			if t.Image != nil {
+				counts[FeatureImages]++
+			}
+			for _, m := range t.Markers {
+				// Markdown 中映射为文本的图标会输出
+				if _, ok := opts.Markers[m.MarkerID]; !ok || format != "md" {
+					counts[FeatureMarkers]++
+				}
+			}
+			if t.Href != "" {
+				counts[FeatureLinks]++
+			}
+			return nil
+		})
+	}
+
+	var warnings []string
+	for _, d := range f.Drops {
+		n := counts[d]
+		if n == 0 {
+			continue
+		}
+		switch d {
+		case FeatureNotes:
+			warnings = append(warnings, i18n.Sprintf("%d 个节点的备注未输出", n))
+		case FeatureLabels:
+			warnings = append(warnings, i18n.Sprintf("%d 个节点的标签未输出", n))
+		case FeatureImages:
+			warnings = append(warnings, i18n.Sprintf("%d 张图片未输出", n))
+		case FeatureMarkers:
+			warnings = append(warnings, i18n.Sprintf("%d 个图标没有映射为文本，未输出", n))
+		case FeatureLinks:
+			warnings = append(warnings, i18n.Sprintf("%d 个节点的链接未输出", n))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,305 @@
+// Package render 将 xmind 包解析得到的 Sheet 列表写出为 Markdown、缩进文本与 .xmind 等格式。
+//
+// WriteAs 按格式名称写出，WriteOptions 控制标题级别、叶子节点样式、节点筛选等输出内容：
+//
+//	err := render.WriteAsOptions("md", w, sheets, render.WriteOptions{HeadingStart: 2})
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"text/template"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// WriteOptions 控制输出的内容，零值为默认行为
+type WriteOptions struct {
+	// Notes 为 true 时在 Markdown 中将节点备注输出为节点下的引用块
+	Notes bool
+	// Markers 将图标 ID（如 priority-1、task-done）映射为输出在节点标题前的文本，未映射的图标不输出
+	Markers map[string]string
+	// PruneEmpty 为 true 时去掉标题为空的占位节点，见 pruneEmpty
+	PruneEmpty bool
+	// MergeDuplicates 为 true 时合并同一节点下标题相同（忽略大小写与多余的空白）的子节点，见 mergeDuplicates
+	MergeDuplicates bool
+	// Include 不为空时只输出标题与其中任意一个正则表达式匹配的节点（连同子节点与上级节点）
+	Include []*regexp.Regexp
+	// Exclude 中的正则表达式与标题匹配的节点连同子树都不输出，先于 Include 生效
+	Exclude []*regexp.Regexp
+	// FilterMarkers 不为空时只输出带有其中任意一个图标的节点（连同子节点与上级节点），
+	// 不带编号的图标组名（如 flag、priority）匹配组内所有图标
+	FilterMarkers []string
+	// FilterLabels 不为空时只输出带有其中任意一个标签的节点（连同子节点与上级节点）
+	FilterLabels []string
+	// LeavesOnly 为 true 时只输出叶子节点，每个画布的根节点下为所有叶子节点的平铺列表，GroupLeaves 为 true 时
+	// 按第一层分支分组，见 leavesOnly；Markdown 中通常与 LeafStyle bullet 一起使用，输出为清单
+	LeavesOnly  bool
+	GroupLeaves bool
+	// Sort 为同一节点下子节点的排列方式，取值见 Sorts，为空时与 none 相同，保持原来的顺序
+	Sort string
+	// MaxDepth 为最多输出的层数，根节点为第 1 层，不大于 0 时不限制
+	MaxDepth int
+	// Numbering 为 true 时在根节点以外的每个节点标题前加上层级编号，如 1、1.1、1.1.2
+	Numbering bool
+	// DepthNote 为 true 时在因 MaxDepth 被截断的节点下输出“…（还有 n 层）”
+	DepthNote bool
+	// HeadingStart 为 Markdown 中根节点的标题级别，子节点依次递增，不大于 0 时为 1
+	HeadingStart int
+	// LeafStyle 为 Markdown 中叶子节点的输出方式，取值见 LeafStyles，为空时与 heading 相同
+	LeafStyle string
+	// Bullet 为 Markdown 列表项的标记，取值见 Bullets，为空时为 -
+	Bullet string
+	// ListIndent 为 Markdown 列表的缩进，取值见 ListIndents，为空时为两个空格
+	ListIndent string
+	// Escape 为 Markdown 中节点标题特殊字符的转义方式，取值见 Escapes，为空时与 off 相同
+	Escape string
+	// Template 为 template 格式使用的模板，应由 ParseTemplate 解析，其他格式忽略
+	Template *template.Template
+	// TOC 为 true 时在 Markdown 输出开头（Header 之后）生成链接到各级标题的目录，列出前 TOCDepth 层，
+	// 根节点为第 1 层，TOCDepth 不大于 0 时为 3
+	TOC      bool
+	TOCDepth int
+	// Links 为 Markdown 中 xmind:#ID 形式的内部链接的目标，键为节点或画布的 ID，没有对应的值时保持原样；
+	// 把输出拆分为多个文件时用于指向其他文件中的锚点，见 MarkdownHeadings
+	Links map[string]string
+	// TOCLinks 依次为目录中每个标题（按 MarkdownHeadings 的顺序）的链接，为空时链接到同一文件中的锚点
+	TOCLinks []string
+	// Header 为写在文本格式输出开头的内容，如目录或 front matter
+	Header string
+	// Footer 为写在文本格式输出末尾的内容，如文档的统计信息
+	Footer string
+	// EOL 为文本格式输出使用的换行符，取值为 lf 或 crlf，为空时为 lf
+	EOL string
+	// BOM 为 true 时在文本格式的输出开头写入 UTF-8 BOM
+	BOM bool
+	// Normalize 为 true 时规范化文本格式输出中的空白：去掉行尾的空白与开头的空行，连续的空行合并为一个，末尾只保留一个换行符
+	Normalize bool
+	// OnEvent 不为 nil 时在写出过程中接收事件，如开始写出每个画布与会丢失内容的警告，用于显示进度，见 Event
+	// OnEvent 在调用 WriteAs 的 goroutine 中同步调用，应尽快返回
+	OnEvent func(Event)
+}
+
+// leafStyles 为叶子节点支持的输出方式：标题、段落与列表项
+var leafStyles = []string{"heading", "paragraph", "bullet"}
+
+// bullets 为列表项支持的标记，listIndents 为列表支持的缩进：两个空格、四个空格与 Tab
+var (
+	bullets     = []string{"-", "*", "+"}
+	listIndents = []string{"2", "4", "tab"}
+)
+
+// Bullets 返回 WriteOptions.Bullet 支持的取值
+func Bullets() []string {
+	return append([]string(nil), bullets...)
+}
+
+// ListIndents 返回 WriteOptions.ListIndent 支持的取值
+func ListIndents() []string {
+	return append([]string(nil), listIndents...)
+}
+
+// EOLs 返回 WriteOptions.EOL 支持的取值
+func EOLs() []string {
+	return []string{"lf", "crlf"}
+}
+
+// LeafStyles 返回 WriteOptions.LeafStyle 支持的取值
+func LeafStyles() []string {
+	return append([]string(nil), leafStyles...)
+}
+
+// OutputFormats 返回所有支持的输出格式名称
+func OutputFormats() []string {
+	names := make([]string, 0, len(outputFormats))
+	for _, f := range outputFormats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// OutputExt 返回输出格式对应的文件扩展名
+func OutputExt(format string) (string, error) {
+	f, err := lookupOutput(format)
+	if err != nil {
+		return "", err
+	}
+	return f.Ext, nil
+}
+
+// WriteAs 按指定的输出格式写出 Sheet 列表
+func WriteAs(format string, w io.Writer, sheets []xmind.Sheet) error {
+	return WriteAsOptions(format, w, sheets, WriteOptions{})
+}
+
+// WriteAsOptions 按指定的输出格式与选项写出 Sheet 列表
+// 节点的筛选、排序与层数限制在调用 Renderer 之前完成，对所有格式都有效
+func WriteAsOptions(format string, w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+	return WriteAsContext(context.Background(), format, w, sheets, opts)
+}
+
+// WriteAsContext 与 WriteAsOptions 相同，ctx 被取消或超时后停止写出并返回 ctx.Err()
+// 已经写出的内容不会撤回，写入文件时调用方应丢弃不完整的输出
+//
+// 输出经过缓冲后再写入 w，w 可以是文件、HTTP 响应或管道等任意 io.Writer，不需要调用方另外缓冲；
+// 写入 w 失败时返回该错误，返回前已经写出全部缓冲的内容
+func WriteAsContext(ctx context.Context, format string, w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+	f, err := lookupOutput(format)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// 写出时递归处理节点，直接构造的 Sheet 没有经过解析时的层数检查
+	if err := xmind.CheckDepth(sheets); err != nil {
+		return err
+	}
+	prepared := prepareSheets(sheets, opts)
+	emitStart(f, sheets, prepared, opts)
+	sheets = prepared
+	if ctx.Done() != nil {
+		w = ctxWriter{ctx: ctx, w: w}
+	}
+	// Renderer 中的写入错误会保留在 bw 中，由 Flush 返回
+	bw := bufio.NewWriter(w)
+	if err := writeAs(f, bw, sheets, opts); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return cerr
+		}
+		return err
+	}
+	return ctx.Err()
+}
+
+// writeAs 按格式 f 写出已经处理过节点的 sheets，文本格式在输出前后处理 BOM、换行符与开头、末尾的内容
+func writeAs(f Format, w io.Writer, sheets []xmind.Sheet, opts WriteOptions) error {
+	if f.Binary || (opts.EOL != "crlf" && !opts.BOM && opts.Header == "" && opts.Footer == "" && !opts.Normalize) {
+		return f.Renderer.Render(w, sheets, opts)
+	}
+	if opts.BOM {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return err
+		}
+	}
+	if opts.EOL == "crlf" {
+		w = &crlfWriter{w: w}
+	}
+	// 先规范化空白，再替换换行符
+	var nw *normWriter
+	if opts.Normalize {
+		nw = &normWriter{w: w}
+		w = nw
+	}
+	if _, err := io.WriteString(w, opts.Header); err != nil {
+		return err
+	}
+	if err := f.Renderer.Render(w, sheets, opts); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, opts.Footer); err != nil {
+		return err
+	}
+	if nw != nil {
+		return nw.close()
+	}
+	return nil
+}
+
+// ctxWriter 在 ctx 被取消后拒绝写入，使不检查写入错误的 Renderer 也不会继续输出
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c ctxWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// normWriter 按 WriteOptions.Normalize 规范化写入内容中的空白，写完后需要调用 close 写出最后一行
+type normWriter struct {
+	w io.Writer
+	// line 为还没有结束的一行，blank 表示之前有还没有写出的空行，started 表示已经写出过非空的行
+	line    []byte
+	blank   bool
+	started bool
+}
+
+func (n *normWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b != '\n' {
+			n.line = append(n.line, b)
+			continue
+		}
+		if err := n.endLine(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// endLine 写出去掉行尾空白的当前行，空行推迟到下一个非空的行之前写出，因此开头与末尾的空行都不会写出
+func (n *normWriter) endLine() error {
+	line := bytes.TrimRight(n.line, " \t\r")
+	n.line = n.line[:0]
+	if len(line) == 0 {
+		n.blank = n.started
+		return nil
+	}
+	buf := make([]byte, 0, len(line)+2)
+	if n.blank {
+		buf = append(buf, '\n')
+	}
+	buf = append(append(buf, line...), '\n')
+	n.blank, n.started = false, true
+	_, err := n.w.Write(buf)
+	return err
+}
+
+// close 写出最后一行没有换行符的内容
+func (n *normWriter) close() error {
+	if len(n.line) == 0 {
+		return nil
+	}
+	return n.endLine()
+}
+
+// crlfWriter 将写入内容中的 \n 替换为 \r\n，已经是 \r\n 的不重复替换
+type crlfWriter struct {
+	w    io.Writer
+	last byte
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(p)+len(p)/8)
+	for _, b := range p {
+		if b == '\n' && c.last != '\r' {
+			buf = append(buf, '\r')
+		}
+		buf = append(buf, b)
+		c.last = b
+	}
+	if _, err := c.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func lookupOutput(format string) (Format, error) {
+	for _, f := range outputFormats {
+		if f.Name == format {
+			return f, nil
+		}
+	}
+	return Format{}, i18n.Errorf("不支持的输出格式: %s", format)
+}
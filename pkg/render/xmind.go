@@ -0,0 +1,155 @@
+package render
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// xmindSheet 与 xmindTopic 为写出 content.json 时使用的结构，与 XMind 的文件格式保持一致
+type xmindSheet struct {
+	ID        string     `json:"id"`
+	Class     string     `json:"class"`
+	Title     string     `json:"title"`
+	RootTopic xmindTopic `json:"rootTopic"`
+}
+
+type xmindTopic struct {
+	ID             string         `json:"id"`
+	Class          string         `json:"class"`
+	Title          string         `json:"title"`
+	StructureClass string         `json:"structureClass,omitempty"`
+	Href           string         `json:"href,omitempty"`
+	Markers        []xmind.Marker `json:"markers,omitempty"`
+	Notes          *xmind.Notes   `json:"notes,omitempty"`
+	Labels         []string       `json:"labels,omitempty"`
+	Image          *xmind.Image   `json:"image,omitempty"`
+	Children       *xmindChildren `json:"children,omitempty"`
+}
+
+type xmindChildren struct {
+	Attached []xmindTopic `json:"attached,omitempty"`
+	Detached []xmindTopic `json:"detached,omitempty"`
+}
+
+// WriteXMind 将 Sheet 列表写出为 .xmind 文件（ZIP 包），缺失或重复的 ID 会重新生成，见 uniqueID
+func WriteXMind(w io.Writer, sheets []xmind.Sheet) error {
+	return WriteXMindResources(w, sheets, nil)
+}
+
+// WriteXMindResources 与 WriteXMind 相同，同时将图片等资源写入压缩包，resources 的键为压缩包中的文件名（如 resources/a.png）
+// 节点图片只有在对应的资源包含在 resources 中时才会保留
+func WriteXMindResources(w io.Writer, sheets []xmind.Sheet, resources map[string][]byte) error {
+	ids := make(map[string]bool)
+	out := make([]xmindSheet, 0, len(sheets))
+	for i, sheet := range sheets {
+		title := sheet.Title
+		if title == "" {
+			title = fmt.Sprintf("画布 %d", i+1)
+		}
+		root := toXMindTopic(sheet.RootTopic, ids, resources)
+		if root.StructureClass == "" {
+			root.StructureClass = "org.xmind.ui.map.unbalanced"
+		}
+		out = append(out, xmindSheet{
+			ID:        uniqueID(sheet.ID, ids),
+			Class:     "sheet",
+			Title:     title,
+			RootTopic: root,
+		})
+	}
+
+	content, err := json.Marshal(out)
+	if err != nil {
+		return i18n.Errorf("生成 content.json 失败: %v", err)
+	}
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	entries := map[string]struct{}{"content.json": {}, "metadata.json": {}}
+	for _, name := range names {
+		entries[name] = struct{}{}
+	}
+	manifest, err := json.Marshal(map[string]interface{}{"file-entries": entries})
+	if err != nil {
+		return i18n.Errorf("生成 manifest.json 失败: %v", err)
+	}
+	type entry struct {
+		name string
+		data []byte
+	}
+	files := []entry{
+		{"content.json", content},
+		{"metadata.json", []byte(`{"creator":{"name":"xmindtomarkdown"}}`)},
+		{"manifest.json", manifest},
+	}
+	for _, name := range names {
+		files = append(files, entry{name, resources[name]})
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return i18n.Errorf("写入 %s 失败: %v", f.name, err)
+		}
+		if _, err := fw.Write(f.data); err != nil {
+			return i18n.Errorf("写入 %s 失败: %v", f.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// toXMindTopic 递归转换节点，detached 节点写入 children.detached，图片的资源不在 resources 中时不写出图片
+func toXMindTopic(t xmind.Topic, ids map[string]bool, resources map[string][]byte) xmindTopic {
+	out := xmindTopic{
+		ID:             uniqueID(t.ID, ids),
+		Class:          "topic",
+		Title:          t.Title,
+		StructureClass: t.StructureClass,
+		Href:           t.Href,
+		Markers:        t.Markers,
+		Notes:          t.Notes,
+		Labels:         t.Labels,
+	}
+	if t.Image != nil {
+		if name, err := xmind.ResourceName(t.Image.Src); err == nil && resources[name] != nil {
+			out.Image = &xmind.Image{Src: "xap:" + name}
+		}
+	}
+	var attached []xmind.Topic
+	if t.Children != nil {
+		attached = t.Children.Attached
+	}
+	if len(attached) == 0 && len(t.Detached) == 0 {
+		return out
+	}
+	out.Children = &xmindChildren{}
+	for _, c := range attached {
+		out.Children.Attached = append(out.Children.Attached, toXMindTopic(c, ids, resources))
+	}
+	for _, c := range t.Detached {
+		out.Children.Detached = append(out.Children.Detached, toXMindTopic(c, ids, resources))
+	}
+	return out
+}
+
+// uniqueID 返回未被使用过的 ID，id 为空或已被使用时由 id 与已经使用的 ID 个数生成，
+// 因此相同的输入总是得到相同的 ID，输出的文件逐字节相同
+func uniqueID(id string, ids map[string]bool) string {
+	for n := len(ids); id == "" || ids[id]; n++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d", id, n)))
+		id = hex.EncodeToString(sum[:13])
+	}
+	ids[id] = true
+	return id
+}
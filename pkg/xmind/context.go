@@ -0,0 +1,32 @@
+package xmind
+
+import (
+	"context"
+	"io"
+)
+
+// ParseAsContext 与 ParseAs 相同，ctx 被取消或超时后停止读取 r 并返回 ctx.Err()
+func ParseAsContext(ctx context.Context, format string, ra io.ReaderAt, size int64) ([]Sheet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sheets, err := ParseAs(format, ctxReaderAt{ctx: ctx, ra: ra}, size)
+	if cerr := ctx.Err(); err != nil && cerr != nil {
+		// 解析器可能把读取错误归为格式错误，取消时统一返回 ctx.Err()
+		return nil, cerr
+	}
+	return sheets, err
+}
+
+// ctxReaderAt 在每次读取前检查 ctx，使各个解析器无需了解 context 也能在读取大文件时及时停止
+type ctxReaderAt struct {
+	ctx context.Context
+	ra  io.ReaderAt
+}
+
+func (r ctxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ra.ReadAt(p, off)
+}
@@ -0,0 +1,37 @@
+package xmind
+
+// MaxDepth 为允许的最大节点层数，根节点为第 1 层，超过时解析返回 ErrTooDeep
+// 解析与写出都会递归处理节点，不限制层数时构造出的极深的思维导图会耗尽栈空间使进程崩溃，正常的思维导图远达不到该层数
+const MaxDepth = 1000
+
+// CheckDepth 检查 sheets 中是否有节点超过 MaxDepth 层，超过时返回的错误属于 ErrTooDeep
+// 使用显式的栈遍历，本身不会因为层级过深而耗尽栈空间
+func CheckDepth(sheets []Sheet) error {
+	type item struct {
+		topic *Topic
+		depth int
+	}
+	for i := range sheets {
+		stack := []item{{&sheets[i].RootTopic, 1}}
+		for len(stack) > 0 {
+			it := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if it.depth > MaxDepth {
+				return &Error{Sheet: i + 1, Err: errorf(ErrTooDeep, "节点超过 %d 层", MaxDepth)}
+			}
+			t := it.topic
+			if t.Children != nil {
+				for j := range t.Children.Attached {
+					stack = append(stack, item{&t.Children.Attached[j], it.depth + 1})
+				}
+				for j := range t.Children.Detached {
+					stack = append(stack, item{&t.Children.Detached[j], it.depth + 1})
+				}
+			}
+			for j := range t.Detached {
+				stack = append(stack, item{&t.Detached[j], it.depth + 1})
+			}
+		}
+	}
+	return nil
+}
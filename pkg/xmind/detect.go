@@ -0,0 +1,154 @@
+package xmind
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// DetectFormat 根据文件内容识别输入格式，不依赖扩展名
+//
+// ZIP 包按其中的入口文件区分 xmind（content.json 或 content.xml）、smmx 与 mindnode，
+// XML 文档按根元素区分 mm 与 opml，其余的 UTF-8 文本视为缩进文本；
+// 确实是 XML 或 JSON 却无法识别的内容（如其他 XML、HTML 或单独的 content.json）返回 ErrUnsupported，
+// 而不是当作缩进文本解析；以 <、{ 或 [ 开头但不是 XML 或 JSON 的文本（如 "[Plan] Q3"）仍是缩进文本。
+func DetectFormat(ra io.ReaderAt, size int64) (string, error) {
+	if zr, err := zip.NewReader(ra, size); err == nil {
+		if err := checkEntries(zr); err != nil {
+			return "", err
+		}
+		for _, f := range zr.File {
+			switch {
+			case strings.HasSuffix(f.Name, "content.json"), strings.HasSuffix(f.Name, "content.xml"):
+				return "xmind", nil
+			case strings.HasSuffix(f.Name, "mindmap.xml"):
+				return "smmx", nil
+			case strings.HasSuffix(f.Name, "contents.xml"):
+				return "mindnode", nil
+			}
+		}
+		return "", errorf(ErrUnsupported, "无法识别的压缩包格式")
+	}
+
+	head := make([]byte, 4096)
+	n, err := ra.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return "", i18n.Errorf("读取文件失败: %v", err)
+	}
+	var skip int64
+	if bytes.HasPrefix(head[:n], []byte("\xef\xbb\xbf")) {
+		skip = 3
+	}
+	head = head[skip:n]
+	trimmed := bytes.TrimSpace(head)
+
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		d := xml.NewDecoder(bytes.NewReader(head))
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				break
+			}
+			if se, ok := tok.(xml.StartElement); ok {
+				switch se.Name.Local {
+				case "map":
+					return "mm", nil
+				case "opml":
+					return "opml", nil
+				}
+				break
+			}
+		}
+	}
+
+	// WriteIR 写出的 JSON 以 format 字段开头
+	if bytes.HasPrefix(trimmed, []byte("{")) && bytes.Contains(head, []byte(`"`+IRFormat+`"`)) {
+		return "ir", nil
+	}
+	// 只在确实是 XML 或 JSON 时才拒绝，需要读取全部内容
+	body := func() io.Reader { return io.NewSectionReader(ra, skip, size-skip) }
+	if looksLikeXML(trimmed, body()) || looksLikeJSON(trimmed, body()) {
+		return "", errorf(ErrUnsupported, "无法识别的文件格式")
+	}
+
+	// 只读取了开头部分，末尾可能截断了一个多字节字符
+	for i := 0; i < utf8.UTFMax-1 && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	if utf8.Valid(head) && bytes.IndexByte(head, 0) < 0 {
+		return "txt", nil
+	}
+	return "", errorf(ErrUnsupported, "无法识别的文件格式")
+}
+
+// looksLikeXML 报告 r 的内容是否为语法正确的 XML（或 HTML）文档
+// 有 XML 声明或 DOCTYPE 时即视为 XML；否则读取全部内容，要求只有一个根元素且没有语法错误，
+// 以尖括号开头的提纲（如 "<Draft> ideas"）因此不会被当作 XML
+func looksLikeXML(trimmed []byte, r io.Reader) bool {
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		return false
+	}
+	if bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(bytes.ToLower(trimmed), []byte("<!doctype")) {
+		return true
+	}
+	d := xml.NewDecoder(r)
+	var depth int
+	var closed bool
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return closed
+		}
+		if err != nil {
+			return false
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if closed {
+				return false
+			}
+			depth++
+		case xml.EndElement:
+			if depth--; depth == 0 {
+				closed = true
+			}
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(tok)) > 0 {
+				return false
+			}
+		}
+	}
+}
+
+// looksLikeJSON 报告 r 的全部内容是否为语法正确的 JSON 对象或数组
+func looksLikeJSON(trimmed []byte, r io.Reader) bool {
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return false
+	}
+	d := json.NewDecoder(r)
+	for {
+		if _, err := d.Token(); err != nil {
+			return err == io.EOF
+		}
+	}
+}
+
+var (
+	htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>\s*<p[^>]*>`)
+	htmlTag   = regexp.MustCompile(`<[^>]+>`)
+)
+
+// htmlText 去除标题中的 HTML 标记，只保留纯文本
+func htmlText(s string) string {
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
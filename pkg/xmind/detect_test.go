@@ -0,0 +1,122 @@
+package xmind
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"缩进文本", "Root\n  A\n  B\n", "txt"},
+		{"FreeMind", `<map version="1.0.1"><node TEXT="Root"/></map>`, "mm"},
+		{"OPML", "\ufeff\n  <?xml version=\"1.0\"?>\n<opml version=\"2.0\"><body/></opml>", "opml"},
+		{"中间格式", `{"format": "` + IRFormat + `", "sheets": []}`, "ir"},
+	}
+	for _, tt := range tests {
+		r := strings.NewReader(tt.input)
+		got, err := DetectFormat(r, r.Size())
+		if err != nil || got != tt.want {
+			t.Errorf("%s: DetectFormat() = %q, %v，应为 %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+// 看起来像 XML 或 JSON 却无法识别的内容不应当作缩进文本解析
+func TestDetectFormatUnsupported(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"其他 XML", `<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`},
+		{"HTML", "<!DOCTYPE html>\n<html><body><p>Root</p></body></html>"},
+		{"content.json", `[{"id": "s1", "title": "Sheet 1", "rootTopic": {"id": "r", "title": "Root"}}]`},
+		{"JSON 对象", `{"rootTopic": {"title": "Root"}}`},
+		{"BOM 与空白之后的 XML", "\ufeff \n\t<foo/>"},
+	}
+	for _, tt := range tests {
+		r := strings.NewReader(tt.input)
+		got, err := DetectFormat(r, r.Size())
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("%s: DetectFormat() = %q, %v，应返回 ErrUnsupported", tt.name, got, err)
+		}
+	}
+}
+
+// 以 <、{ 或 [ 开头但不是 XML 或 JSON 的提纲仍是缩进文本
+func TestDetectFormatBracketText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"方括号", "[Plan] Q3\n  [ ] 招聘\n  预算\n"},
+		{"尖括号", "<Draft> ideas\n  first\n  second\n"},
+		{"花括号", "{WIP} roadmap\n  alpha\n"},
+		{"一行 HTML 之后还有文本", "<b>重点</b>\n  细节\n"},
+	}
+	for _, tt := range tests {
+		r := strings.NewReader(tt.input)
+		got, err := DetectFormat(r, r.Size())
+		if err != nil || got != "txt" {
+			t.Errorf("%s: DetectFormat() = %q, %v，应为 \"txt\"", tt.name, got, err)
+		}
+	}
+}
+
+// 扩展名为 .txt 时，无法识别的内容也按缩进文本解析
+func TestParseFileTxtExtension(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"方括号", "[Plan] Q3\n  招聘\n", "[Plan] Q3\n.招聘\n"},
+		{"尖括号", "<Draft> ideas\n  first\n", "<Draft> ideas\n.first\n"},
+		{"JSON 数组", "[1, 2]\n", "[1, 2]\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "outline.txt")
+		if err := os.WriteFile(path, []byte(tt.input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sheets, err := ParseFileAs(path, "auto")
+		if err != nil {
+			t.Errorf("%s: ParseFileAs() 出错: %v", tt.name, err)
+			continue
+		}
+		if got := outline(sheets); got != tt.want {
+			t.Errorf("%s: ParseFileAs() 得到\n%s应为\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// 超过开头读取部分的内容按全部内容判断是否为 XML 或 JSON
+func TestDetectFormatTruncated(t *testing.T) {
+	long := strings.Repeat("x", 8192)
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{"XML", "<root><item>" + long + "</item></root>", ErrUnsupported},
+		{"JSON", `[{"title": "` + long + `"}]`, ErrUnsupported},
+		{"方括号文本", "[Plan] " + long + "\n", nil},
+		{"尖括号文本", "<Draft> " + long + "\n", nil},
+	}
+	for _, tt := range tests {
+		r := strings.NewReader(tt.input)
+		got, err := DetectFormat(r, r.Size())
+		if tt.want == nil && (err != nil || got != "txt") {
+			t.Errorf("%s: DetectFormat() = %q, %v，应为 \"txt\"", tt.name, got, err)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: DetectFormat() = %q, %v，应返回 %v", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package xmind
+
+import (
+	"errors"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// 解析失败的原因，可以用 errors.Is 判断，如 errors.Is(err, xmind.ErrEncrypted)
+var (
+	// ErrNotZip 表示文件不是有效的 ZIP 压缩包
+	ErrNotZip = errors.New("不是有效的压缩包")
+	// ErrNoContent 表示文件中没有找到思维导图内容，如缺少 content.json 或没有任何节点
+	ErrNoContent = errors.New("没有找到思维导图内容")
+	// ErrBadJSON 表示 content.json 不是有效的 JSON，或结构与 XMind 的格式不符
+	ErrBadJSON = errors.New("JSON 格式错误")
+	// ErrBadXML 表示 XML 格式的内容（如 content.xml、.mm、.opml）无法解析
+	ErrBadXML = errors.New("XML 格式错误")
+	// ErrEncrypted 表示文件设置了密码，内容已加密
+	ErrEncrypted = errors.New("文件已加密")
+	// ErrUnsupported 表示无法识别或暂不支持的输入格式
+	ErrUnsupported = errors.New("不支持的格式")
+	// ErrResourceMissing 表示节点引用的图片等资源不在文件中
+	ErrResourceMissing = errors.New("缺少引用的资源")
+	// ErrTooDeep 表示节点的层数超过 MaxDepth
+	ErrTooDeep = errors.New("节点层数过多")
+	// ErrUnsafePath 表示资源的文件名包含 ..、绝对路径或指向符号链接，提取时可能写到目标目录之外
+	ErrUnsafePath = errors.New("不安全的路径")
+	// ErrTooLarge 表示文件超过 DefaultLimits 中的某项限制，如解压后的大小或节点总数
+	ErrTooLarge = errors.New("文件超过解析限制")
+)
+
+// Error 为解析失败时附带位置信息的错误，可以用 errors.As 取得出错的文件、画布与字段，
+// 用 errors.Is 判断失败原因
+type Error struct {
+	// File 为输入文件的路径，从内存中解析时为空
+	File string
+	// Sheet 为出错画布的序号，从 1 开始，与具体画布无关时为 0
+	Sheet int
+	// Path 为出错的字段在画布中的位置，如 rootTopic.children.attached.title，未知时为空
+	// Path 不出现在错误信息中，Err 的信息中已经包含出错的字段
+	Path string
+	// Err 为具体的错误
+	Err error
+}
+
+func (e *Error) Error() string {
+	msg := e.Err.Error()
+	if e.Sheet > 0 {
+		msg = i18n.Sprintf("第 %d 个画布: %s", e.Sheet, msg)
+	}
+	if e.File != "" {
+		msg = e.File + ": " + msg
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// withFile 为解析内容时的错误记录输入文件，err 已经是 *Error 时只补充文件路径
+func withFile(err error, file string) error {
+	if e, ok := err.(*Error); ok {
+		c := *e
+		c.File = file
+		return &c
+	}
+	return &Error{File: file, Err: err}
+}
+
+// kindError 保留原有的错误信息，同时可以用 errors.Is 判断属于哪一类错误
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// errorf 按 format 生成错误信息，并将错误归入 kind 一类
+func errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: i18n.Errorf(format, args...)}
+}
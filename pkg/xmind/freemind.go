@@ -0,0 +1,51 @@
+package xmind
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// freeMindNode 对应 FreeMind / Freeplane .mm 文件中的 node 元素
+type freeMindNode struct {
+	ID          string `xml:"ID,attr"`
+	Text        string `xml:"TEXT,attr"`
+	Link        string `xml:"LINK,attr"`
+	RichContent []struct {
+		Type string `xml:"TYPE,attr"`
+		HTML string `xml:",innerxml"`
+	} `xml:"richcontent"`
+	Nodes []freeMindNode `xml:"node"`
+}
+
+// parseFreeMind 解析 FreeMind 的 .mm 文件
+func parseFreeMind(r io.Reader) ([]Sheet, error) {
+	var doc struct {
+		Node *freeMindNode `xml:"node"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errorf(ErrBadXML, "解析 XML 失败: %v", err)
+	}
+	if doc.Node == nil {
+		return nil, errorf(ErrNoContent, "mm 文件中没有任何节点")
+	}
+	return []Sheet{{ID: doc.Node.ID, RootTopic: doc.Node.topic()}}, nil
+}
+
+// topic 递归转换 FreeMind 节点，没有 TEXT 属性时使用富文本内容
+func (n freeMindNode) topic() Topic {
+	t := Topic{ID: n.ID, Title: n.Text, Href: n.Link}
+	if t.Title == "" {
+		for _, rc := range n.RichContent {
+			if rc.Type == "NODE" {
+				t.Title = htmlText(rc.HTML)
+			}
+		}
+	}
+	for _, c := range n.Nodes {
+		if t.Children == nil {
+			t.Children = &Children{}
+		}
+		t.Children.Attached = append(t.Children.Attached, c.topic())
+	}
+	return t
+}
@@ -0,0 +1,150 @@
+package xmind
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// IRFormat 为中间格式文件中 format 字段的取值，用于识别文件内容
+const IRFormat = "xmindtomarkdown-ir"
+
+// IRVersion 为当前的中间格式版本，字段的含义发生不兼容的变化时递增
+// 读取时接受不高于 IRVersion 的版本，新增可选字段不改变版本
+const IRVersion = 1
+
+// IR 为与具体思维导图格式无关的中间表示，可以序列化为 JSON 后再读回，
+// 解析一次后即可多次写出为不同的格式，也适合作为缓存的内容
+type IR struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Sheets  []IRSheet `json:"sheets"`
+}
+
+// IRSheet 为中间表示中的一个画布
+type IRSheet struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	Root  IRNode `json:"root"`
+}
+
+// IRNode 为中间表示中的一个节点
+type IRNode struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+	// Link 为节点链接
+	Link string `json:"link,omitempty"`
+	// Markers 为节点上图标的 ID，如 priority-1
+	Markers []string `json:"markers,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	// Note 为节点备注的纯文本内容
+	Note string `json:"note,omitempty"`
+	// Image 为节点图片的地址，与 Image.Src 相同
+	Image    string   `json:"image,omitempty"`
+	Children []IRNode `json:"children,omitempty"`
+	// Detached 为分离的节点
+	Detached []IRNode `json:"detached,omitempty"`
+}
+
+// ToIR 将 Sheet 列表转换为当前版本的中间表示
+func ToIR(sheets []Sheet) IR {
+	ir := IR{Format: IRFormat, Version: IRVersion, Sheets: make([]IRSheet, 0, len(sheets))}
+	for _, s := range sheets {
+		ir.Sheets = append(ir.Sheets, IRSheet{ID: s.ID, Title: s.Title, Root: toIRNode(s.RootTopic)})
+	}
+	return ir
+}
+
+// toIRNode 将 t 及其子节点转换为中间表示
+func toIRNode(t Topic) IRNode {
+	n := IRNode{ID: t.ID, Title: t.Title, Link: t.Href, Labels: t.Labels}
+	for _, m := range t.Markers {
+		n.Markers = append(n.Markers, m.MarkerID)
+	}
+	if t.Notes != nil && t.Notes.Plain != nil {
+		n.Note = t.Notes.Plain.Content
+	}
+	if t.Image != nil {
+		n.Image = t.Image.Src
+	}
+	if t.Children != nil {
+		for _, c := range t.Children.Attached {
+			n.Children = append(n.Children, toIRNode(c))
+		}
+	}
+	for _, c := range t.Detached {
+		n.Detached = append(n.Detached, toIRNode(c))
+	}
+	return n
+}
+
+// ToSheets 将中间表示转换回 Sheet 列表
+func (ir IR) ToSheets() []Sheet {
+	sheets := make([]Sheet, 0, len(ir.Sheets))
+	for _, s := range ir.Sheets {
+		sheets = append(sheets, Sheet{ID: s.ID, Class: "sheet", Title: s.Title, RootTopic: s.Root.topic()})
+	}
+	return sheets
+}
+
+// topic 将中间表示中的节点转换为 Topic
+func (n IRNode) topic() Topic {
+	t := Topic{ID: n.ID, Class: "topic", Title: n.Title, Href: n.Link, Labels: n.Labels}
+	for _, m := range n.Markers {
+		t.Markers = append(t.Markers, Marker{MarkerID: m})
+	}
+	if n.Note != "" {
+		t.Notes = &Notes{Plain: &NotesContent{Content: n.Note}}
+	}
+	if n.Image != "" {
+		t.Image = &Image{Src: n.Image}
+	}
+	if len(n.Children) > 0 {
+		t.Children = &Children{}
+		for _, c := range n.Children {
+			t.Children.Attached = append(t.Children.Attached, c.topic())
+		}
+	}
+	for _, c := range n.Detached {
+		t.Detached = append(t.Detached, c.topic())
+	}
+	return t
+}
+
+// WriteIR 将 Sheet 列表写出为 JSON 格式的中间表示，可以用 ReadIR 或 Parse 系列函数读回
+func WriteIR(w io.Writer, sheets []Sheet) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ToIR(sheets))
+}
+
+// ReadIR 读取 WriteIR 写出的中间表示，版本高于 IRVersion 时返回的错误属于 ErrUnsupported
+// JSON 的嵌套层数受 DefaultLimits.MaxJSONDepth 限制
+func ReadIR(r io.Reader) (IR, error) {
+	var ir IR
+	if err := json.NewDecoder(limitJSONDepth(r)).Decode(&ir); errors.Is(err, ErrTooLarge) {
+		return IR{}, err
+	} else if err != nil {
+		return IR{}, errorf(ErrBadJSON, "解析 JSON 失败: %v", err)
+	}
+	if ir.Format != IRFormat {
+		return IR{}, errorf(ErrUnsupported, "不是中间格式文件")
+	}
+	if ir.Version < 1 || ir.Version > IRVersion {
+		return IR{}, errorf(ErrUnsupported, "不支持的中间格式版本: %d（支持 1 至 %d）", ir.Version, IRVersion)
+	}
+	if len(ir.Sheets) == 0 {
+		return IR{}, errorf(ErrNoContent, "中间格式文件中没有任何画布")
+	}
+	return ir, nil
+}
+
+// parseIR 解析中间格式文件
+func parseIR(r io.Reader) ([]Sheet, error) {
+	ir, err := ReadIR(r)
+	if err != nil {
+		return nil, err
+	}
+	return ir.ToSheets(), nil
+}
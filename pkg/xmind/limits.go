@@ -0,0 +1,190 @@
+package xmind
+
+import (
+	"archive/zip"
+	"io"
+)
+
+// Limits 限制解析时使用的资源，防止恶意构造的文件（如解压后体积巨大的压缩包）耗尽内存
+// 超过限制时解析返回的错误属于 ErrTooLarge，各项为 0 时表示不限制
+type Limits struct {
+	// MaxEntries 为压缩包中允许的最大文件数
+	MaxEntries int
+	// MaxDecompressedSize 为从压缩包中读取的单个文件解压后的最大字节数
+	MaxDecompressedSize int64
+	// MaxJSONDepth 为 content.json 与中间格式中 JSON 允许的最大嵌套层数
+	// XMind 中每层节点对应三层嵌套（节点、children 与 attached），需要大于 3 倍的 MaxDepth 才不会先于层数限制生效
+	MaxJSONDepth int
+	// MaxTopics 为一个文件中所有画布的节点总数
+	MaxTopics int
+	// MaxTopicText 为单个节点的标题或备注的最大字节数
+	MaxTopicText int
+}
+
+// DefaultLimits 为解析时使用的限制，正常的思维导图远达不到这些数值
+// 可以在程序启动时修改，解析过程中修改的结果不确定
+var DefaultLimits = Limits{
+	MaxEntries:          10000,
+	MaxDecompressedSize: 512 << 20,
+	MaxJSONDepth:        3*MaxDepth + 16,
+	MaxTopics:           1000000,
+	MaxTopicText:        4 << 20,
+}
+
+// openZip 打开压缩包并检查其中的文件数
+func openZip(ra io.ReaderAt, size int64) (*zip.Reader, error) {
+	r, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, errorf(ErrNotZip, "打开文件失败: %w", err)
+	}
+	if err := checkEntries(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func checkEntries(r *zip.Reader) error {
+	if max := DefaultLimits.MaxEntries; max > 0 && len(r.File) > max {
+		return errorf(ErrTooLarge, "压缩包中有 %d 个文件，超过限制 %d", len(r.File), max)
+	}
+	return nil
+}
+
+// openEntry 打开压缩包中的文件，读取的内容超过 MaxDecompressedSize 时返回错误
+// 文件头中记录的大小可以伪造，因此除了预先检查外还需要在读取时计数
+func openEntry(f *zip.File) (io.ReadCloser, error) {
+	max := DefaultLimits.MaxDecompressedSize
+	if max > 0 && f.UncompressedSize64 > uint64(max) {
+		return nil, entryTooLarge(f.Name, max)
+	}
+	rc, err := f.Open()
+	if err != nil || max <= 0 {
+		return rc, err
+	}
+	return &limitedEntry{rc: rc, name: f.Name, left: max}, nil
+}
+
+func entryTooLarge(name string, max int64) error {
+	return errorf(ErrTooLarge, "%s 解压后超过 %d 字节", name, max)
+}
+
+// limitedEntry 在读取超过 left 个字节时返回属于 ErrTooLarge 的错误，而不是像 io.LimitReader 一样截断内容
+type limitedEntry struct {
+	rc   io.ReadCloser
+	name string
+	left int64
+}
+
+func (l *limitedEntry) Read(p []byte) (int, error) {
+	if l.left < 0 {
+		return 0, entryTooLarge(l.name, DefaultLimits.MaxDecompressedSize)
+	}
+	// 多读一个字节，以区分内容恰好为上限与超过上限
+	if int64(len(p)) > l.left+1 {
+		p = p[:l.left+1]
+	}
+	n, err := l.rc.Read(p)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n, entryTooLarge(l.name, DefaultLimits.MaxDecompressedSize)
+	}
+	return n, err
+}
+
+func (l *limitedEntry) Close() error {
+	return l.rc.Close()
+}
+
+// jsonDepthReader 在 JSON 的嵌套层数超过 max 时返回错误
+// encoding/json 只在解析完整个值后才报告结构问题，逐字节扫描可以在分配大量内存之前停止
+type jsonDepthReader struct {
+	r     io.Reader
+	max   int
+	depth int
+	// inString 与 escaped 记录当前是否位于字符串中，字符串中的括号不计入层数
+	inString, escaped bool
+	// err 为超过层数时的错误，之后的读取都返回该错误
+	// json.Decoder 在缓冲区中还有数据时会丢弃读取错误，不保留错误会跳过一段内容继续解析
+	err error
+}
+
+// limitJSONDepth 按 DefaultLimits.MaxJSONDepth 限制从 r 读取的 JSON 的嵌套层数
+func limitJSONDepth(r io.Reader) io.Reader {
+	if DefaultLimits.MaxJSONDepth <= 0 {
+		return r
+	}
+	return &jsonDepthReader{r: r, max: DefaultLimits.MaxJSONDepth}
+}
+
+func (j *jsonDepthReader) Read(p []byte) (int, error) {
+	if j.err != nil {
+		return 0, j.err
+	}
+	n, err := j.r.Read(p)
+	for i, c := range p[:n] {
+		switch {
+		case j.escaped:
+			j.escaped = false
+		case j.inString:
+			switch c {
+			case '\\':
+				j.escaped = true
+			case '"':
+				j.inString = false
+			}
+		case c == '"':
+			j.inString = true
+		case c == '{' || c == '[':
+			if j.depth++; j.depth > j.max {
+				j.err = errorf(ErrTooLarge, "JSON 嵌套超过 %d 层", j.max)
+				return i, j.err
+			}
+		case c == '}' || c == ']':
+			j.depth--
+		}
+	}
+	return n, err
+}
+
+// CheckLimits 检查 sheets 的层数（见 CheckDepth）、节点总数以及每个节点的标题与备注是否超过 DefaultLimits
+func CheckLimits(sheets []Sheet) error {
+	if err := CheckDepth(sheets); err != nil {
+		return err
+	}
+	l := DefaultLimits
+	if l.MaxTopics <= 0 && l.MaxTopicText <= 0 {
+		return nil
+	}
+	total := 0
+	for i := range sheets {
+		// CheckDepth 已经保证层数有限，可以递归遍历
+		err := sheets[i].Walk(func(_ []*Topic, t *Topic) error {
+			if total++; l.MaxTopics > 0 && total > l.MaxTopics {
+				return errorf(ErrTooLarge, "节点总数超过 %d", l.MaxTopics)
+			}
+			if l.MaxTopicText > 0 {
+				size := len(t.Title)
+				if t.Notes != nil && t.Notes.Plain != nil && len(t.Notes.Plain.Content) > size {
+					size = len(t.Notes.Plain.Content)
+				}
+				if size > l.MaxTopicText {
+					return errorf(ErrTooLarge, "节点 %q 的标题或备注超过 %d 字节", abbrev(t.Title), l.MaxTopicText)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return &Error{Sheet: i + 1, Err: err}
+		}
+	}
+	return nil
+}
+
+// abbrev 截取标题的开头部分用于错误信息
+func abbrev(s string) string {
+	r := []rune(s)
+	if len(r) > 20 {
+		return string(r[:20]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+package xmind
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// xmindFile 返回只包含 content.json 的 .xmind 文件内容
+func xmindFile(t *testing.T, content string) []byte {
+	t.Helper()
+	return zipFile(t, "content.json", content)
+}
+
+// zipFile 返回依次包含 files 中各文件的压缩包，files 为文件名与内容交替的列表
+func zipFile(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i+1 < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// setLimits 在测试期间修改 DefaultLimits，测试结束后恢复
+func setLimits(t *testing.T, l Limits) {
+	t.Helper()
+	saved := DefaultLimits
+	DefaultLimits = l
+	t.Cleanup(func() { DefaultLimits = saved })
+}
+
+// Parse 与 ParseBytes 同样检查节点数与节点内容的大小
+func TestParseLimits(t *testing.T) {
+	content := `[{"id": "s1", "title": "Sheet 1", "rootTopic": {"id": "r", "title": "Root", "children": {"attached": [` +
+		`{"id": "a", "title": "A"}, {"id": "b", "title": "` + strings.Repeat("b", 64) + `"}]}}}]`
+	data := xmindFile(t, content)
+	tests := []struct {
+		name   string
+		limits Limits
+		want   error
+	}{
+		{"没有超过限制", Limits{MaxTopics: 3, MaxTopicText: 64}, nil},
+		{"节点数", Limits{MaxTopics: 2}, ErrTooLarge},
+		{"节点内容", Limits{MaxTopicText: 63}, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		setLimits(t, tt.limits)
+		_, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+			t.Errorf("%s: Parse() 返回 %v，应为 %v", tt.name, err, tt.want)
+		}
+		_, err = ParseBytes(data)
+		if !errors.Is(err, tt.want) || (tt.want == nil) != (err == nil) {
+			t.Errorf("%s: ParseBytes() 返回 %v，应为 %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestJSONDepthReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		max   int
+		ok    bool
+	}{
+		{"恰好为上限", `{"a": [{"b": 1}]}`, 3, true},
+		{"超过上限", `{"a": [{"b": [1]}]}`, 3, false},
+		{"并列的值不累加", `[[1], [2], {"a": [3]}]`, 3, true},
+		{"字符串中的括号", `{"a": "[[[[{{{{"}`, 1, true},
+		{"字符串中转义的引号", `{"a": "\"[[[[\""}`, 1, true},
+		{"转义的反斜杠之后字符串结束", `{"a": "\\", "b": [[1]]}`, 2, false},
+		{"转义的反斜杠之后字符串结束（未超过）", `{"a": "\\", "b": [1]}`, 2, true},
+		{"键中的括号与转义", `{"[\"{": {"}]": 1}}`, 2, true},
+		{"字符串中的 unicode 转义", `{"a": "\u0022[[\u005c"}`, 1, true},
+	}
+	for _, tt := range tests {
+		// 逐字节读取，确认字符串与转义的状态在多次读取之间保持
+		for _, r := range []io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+			var v interface{}
+			err := json.NewDecoder(&jsonDepthReader{r: r, max: tt.max}).Decode(&v)
+			if tt.ok && err != nil {
+				t.Errorf("%s: 解析出错: %v", tt.name, err)
+			}
+			if !tt.ok && !errors.Is(err, ErrTooLarge) {
+				t.Errorf("%s: 返回 %v，应返回 ErrTooLarge", tt.name, err)
+			}
+		}
+	}
+}
+
+// 超过层数之后的读取都返回同一个错误，不会跳过一段内容继续解析
+func TestJSONDepthReaderStickyError(t *testing.T) {
+	j := &jsonDepthReader{r: strings.NewReader(`[[[1]]] [2]`), max: 2}
+	buf := make([]byte, 64)
+	n, err := j.Read(buf)
+	if n != 2 || !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Read() = %d, %v，应在第三个 [ 之前停止并返回 ErrTooLarge", n, err)
+	}
+	if n, err2 := j.Read(buf); n != 0 || err2 != err {
+		t.Errorf("再次 Read() = %d, %v，应返回同一个错误", n, err2)
+	}
+}
+
+func TestLimitedEntry(t *testing.T) {
+	const max = 16
+	setLimits(t, Limits{MaxDecompressedSize: max})
+	tests := []struct {
+		name string
+		size int
+		ok   bool
+	}{
+		{"空文件", 0, true},
+		{"少一个字节", max - 1, true},
+		{"恰好为上限", max, true},
+		{"多一个字节", max + 1, false},
+		{"远超上限", 10 * max, false},
+	}
+	for _, tt := range tests {
+		content := strings.Repeat("x", tt.size)
+		// 按文件头中的大小预先检查
+		data := zipFile(t, "content.json", content)
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := openEntry(zr.File[0])
+		if err == nil {
+			_, err = io.ReadAll(rc)
+			rc.Close()
+		}
+		if tt.ok != (err == nil) || (!tt.ok && !errors.Is(err, ErrTooLarge)) {
+			t.Errorf("%s: openEntry() 读取 %d 字节返回 %v", tt.name, tt.size, err)
+		}
+
+		// 文件头中的大小可以伪造，读取时同样需要计数
+		for _, r := range []io.Reader{strings.NewReader(content), iotest.OneByteReader(strings.NewReader(content))} {
+			l := &limitedEntry{rc: io.NopCloser(r), name: "content.json", left: max}
+			got, err := io.ReadAll(l)
+			if tt.ok && (err != nil || string(got) != content) {
+				t.Errorf("%s: 读取得到 %d 字节, %v，应为 %d 字节", tt.name, len(got), err, tt.size)
+			}
+			if !tt.ok && !errors.Is(err, ErrTooLarge) {
+				t.Errorf("%s: 读取返回 %v，应返回 ErrTooLarge", tt.name, err)
+			}
+		}
+	}
+}
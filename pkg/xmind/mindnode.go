@@ -0,0 +1,168 @@
+package xmind
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// readMindNodeBundle 解析目录形式的 .mindnode bundle
+func readMindNodeBundle(dir string) ([]Sheet, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "contents.xml"))
+	if err != nil {
+		return nil, errorf(ErrNoContent, "读取 contents.xml 失败: %w", err)
+	}
+	return parseMindNodeContents(data)
+}
+
+// parseMindNode 解析压缩为 ZIP 包的 .mindnode 文件
+func parseMindNode(ra io.ReaderAt, size int64) ([]Sheet, error) {
+	data, err := readZipEntry(ra, size, "contents.xml")
+	if err != nil {
+		return nil, err
+	}
+	return parseMindNodeContents(data)
+}
+
+// parseMindNodeContents 解析 MindNode 的 contents.xml（XML 格式的 plist）
+func parseMindNodeContents(data []byte) ([]Sheet, error) {
+	if bytes.HasPrefix(data, []byte("bplist")) {
+		return nil, errorf(ErrUnsupported, "暂不支持二进制格式的 contents.xml")
+	}
+	plist, err := parsePlist(data)
+	if errors.Is(err, ErrTooDeep) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errorf(ErrBadXML, "解析 contents.xml 失败: %v", err)
+	}
+
+	doc, _ := plist.(map[string]interface{})
+	mindMap, _ := doc["mindMap"].(map[string]interface{})
+	mainNodes, _ := mindMap["mainNodes"].([]interface{})
+	if len(mainNodes) == 0 {
+		return nil, errorf(ErrNoContent, "MindNode 文件中没有任何节点")
+	}
+
+	// 第一个主节点作为根节点，其余主节点视为分离的节点
+	var root Topic
+	for i, n := range mainNodes {
+		node, _ := n.(map[string]interface{})
+		topic := mindNodeTopic(node)
+		if i == 0 {
+			root = topic
+			continue
+		}
+		root.Detached = append(root.Detached, topic)
+	}
+	return []Sheet{{ID: root.ID, RootTopic: root}}, nil
+}
+
+// mindNodeTopic 将 MindNode 节点递归转换为 Topic
+func mindNodeTopic(node map[string]interface{}) Topic {
+	topic := Topic{}
+	topic.ID, _ = node["nodeID"].(string)
+	if title, ok := node["title"].(map[string]interface{}); ok {
+		text, _ := title["text"].(string)
+		topic.Title = htmlText(text)
+	}
+	topic.Href, _ = node["link"].(string)
+
+	subnodes, _ := node["subnodes"].([]interface{})
+	for _, s := range subnodes {
+		sub, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if topic.Children == nil {
+			topic.Children = &Children{}
+		}
+		topic.Children.Attached = append(topic.Children.Attached, mindNodeTopic(sub))
+	}
+	return topic
+}
+
+// parsePlist 解析 XML 格式的 plist，dict 转为 map，array 转为切片，其余值统一为字符串或布尔值
+func parsePlist(data []byte) (interface{}, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local != "plist" {
+			return parsePlistValue(d, se, 1)
+		}
+	}
+}
+
+// maxPlistDepth 为 plist 允许的最大嵌套层数，MindNode 中每层节点对应 dict 与 subnodes 数组两层嵌套
+const maxPlistDepth = 2*MaxDepth + 8
+
+// parsePlistValue 解析以 start 开始的单个 plist 值，depth 为该值的嵌套层数
+func parsePlistValue(d *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxPlistDepth {
+		return nil, errorf(ErrTooDeep, "节点超过 %d 层", MaxDepth)
+	}
+	switch start.Name.Local {
+	case "dict":
+		m := make(map[string]interface{})
+		var key string
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					var k string
+					if err := d.DecodeElement(&k, &t); err != nil {
+						return nil, err
+					}
+					key = k
+					continue
+				}
+				v, err := parsePlistValue(d, t, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				m[key] = v
+			case xml.EndElement:
+				return m, nil
+			}
+		}
+	case "array":
+		var a []interface{}
+		for {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				v, err := parsePlistValue(d, t, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, v)
+			case xml.EndElement:
+				return a, nil
+			}
+		}
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	default:
+		var s string
+		if err := d.DecodeElement(&s, &start); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
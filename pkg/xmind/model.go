@@ -0,0 +1,71 @@
+package xmind
+
+// Sheet 表示 content.json 数组中的每个思维导图页
+type Sheet struct {
+	ID        string `json:"id"`
+	Class     string `json:"class"`
+	Title     string `json:"title,omitempty"`
+	RootTopic Topic  `json:"rootTopic"`
+}
+
+// Topic 表示每个节点
+type Topic struct {
+	ID             string `json:"id"`
+	Class          string `json:"class"`
+	Title          string `json:"title"`
+	StructureClass string `json:"structureClass"`
+	Branch         string `json:"branch,omitempty"`
+	// 子节点 attached
+	Children *Children `json:"children,omitempty"`
+	// 分离的节点 detached
+	Detached []Topic `json:"detached,omitempty"`
+	// 节点链接，若存在则输出为超链接形式
+	Href string `json:"href,omitempty"`
+	// 节点上的图标，如优先级、任务进度
+	Markers []Marker `json:"markers,omitempty"`
+	// 节点备注
+	Notes *Notes `json:"notes,omitempty"`
+	// 节点标签
+	Labels []string `json:"labels,omitempty"`
+	// 节点图片，Src 形如 xap:resources/xxx.png
+	Image *Image `json:"image,omitempty"`
+	// OmittedLevels 为写出时因层数限制被省略的子节点层数，只由 render 在需要注明时设置，不来自输入文件
+	OmittedLevels int `json:"-"`
+}
+
+// Marker 表示节点上的一个图标
+type Marker struct {
+	MarkerID string `json:"markerId"`
+}
+
+// Notes 表示节点备注，只保留纯文本内容
+type Notes struct {
+	Plain *NotesContent `json:"plain,omitempty"`
+}
+
+// NotesContent 为备注的内容
+type NotesContent struct {
+	Content string `json:"content"`
+}
+
+// Image 表示节点中插入的图片
+type Image struct {
+	Src string `json:"src"`
+}
+
+// TopicCount 返回 sheet 中的节点总数，包括根节点与分离的节点
+func (s Sheet) TopicCount() int {
+	n := 0
+	s.Walk(func([]*Topic, *Topic) error {
+		n++
+		return nil
+	})
+	return n
+}
+
+// Children 用于解析 children.attached 数组
+type Children struct {
+	Attached []Topic `json:"attached,omitempty"`
+	// XMind 将分离的节点保存在 children.detached 中，解析后统一移动到 Topic.Detached
+	Detached []Topic `json:"detached,omitempty"`
+}
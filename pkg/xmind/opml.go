@@ -0,0 +1,55 @@
+package xmind
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// opmlOutline 对应 OPML 文件中的 outline 元素
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	URL      string        `xml:"url,attr"`
+	HTMLURL  string        `xml:"htmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// parseOPML 解析 OPML 大纲文件
+// body 中只有一个顶层 outline 时以它为根节点，否则以文档标题为根节点
+func parseOPML(r io.Reader) ([]Sheet, error) {
+	var doc struct {
+		Title    string        `xml:"head>title"`
+		Outlines []opmlOutline `xml:"body>outline"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, errorf(ErrBadXML, "解析 XML 失败: %v", err)
+	}
+	if len(doc.Outlines) == 0 {
+		return nil, errorf(ErrNoContent, "opml 文件中没有任何节点")
+	}
+
+	var root Topic
+	if len(doc.Outlines) == 1 {
+		root = doc.Outlines[0].topic()
+	} else {
+		root = Topic{Title: doc.Title, Children: &Children{}}
+		for _, o := range doc.Outlines {
+			root.Children.Attached = append(root.Children.Attached, o.topic())
+		}
+	}
+	return []Sheet{{Title: doc.Title, RootTopic: root}}, nil
+}
+
+// topic 递归转换 OPML 中的 outline
+func (o opmlOutline) topic() Topic {
+	t := Topic{Title: o.Text, Href: o.URL}
+	if t.Href == "" {
+		t.Href = o.HTMLURL
+	}
+	for _, c := range o.Outlines {
+		if t.Children == nil {
+			t.Children = &Children{}
+		}
+		t.Children.Attached = append(t.Children.Attached, c.topic())
+	}
+	return t
+}
@@ -0,0 +1,210 @@
+package xmind
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// inputFormat 描述一种可解析的输入格式
+type inputFormat struct {
+	name  string
+	exts  []string
+	parse func(ra io.ReaderAt, size int64) ([]Sheet, error)
+}
+
+// inputFormats 为所有支持的输入格式，第一个为无法识别扩展名时的默认格式
+var inputFormats = []inputFormat{
+	{name: "xmind", exts: []string{".xmind"}, parse: parseXMind},
+	{name: "smmx", exts: []string{".smmx"}, parse: parseSimpleMind},
+	{name: "mindnode", exts: []string{".mindnode"}, parse: parseMindNode},
+	{name: "txt", exts: []string{".txt"}, parse: func(ra io.ReaderAt, size int64) ([]Sheet, error) {
+		return parseText(io.NewSectionReader(ra, 0, size))
+	}},
+	{name: "mm", exts: []string{".mm"}, parse: func(ra io.ReaderAt, size int64) ([]Sheet, error) {
+		return parseFreeMind(io.NewSectionReader(ra, 0, size))
+	}},
+	{name: "opml", exts: []string{".opml"}, parse: func(ra io.ReaderAt, size int64) ([]Sheet, error) {
+		return parseOPML(io.NewSectionReader(ra, 0, size))
+	}},
+	{name: "ir", exts: []string{".x2m"}, parse: func(ra io.ReaderAt, size int64) ([]Sheet, error) {
+		return parseIR(io.NewSectionReader(ra, 0, size))
+	}},
+}
+
+// InputFormats 返回所有支持的输入格式名称
+func InputFormats() []string {
+	names := make([]string, 0, len(inputFormats))
+	for _, f := range inputFormats {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// IsInputFile 判断文件扩展名是否属于支持的输入格式
+func IsInputFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, f := range inputFormats {
+		for _, e := range f.exts {
+			if e == ext {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FormatOf 根据文件扩展名推断输入格式，无法识别时按 xmind 处理
+func FormatOf(name string) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, f := range inputFormats {
+		for _, e := range f.exts {
+			if e == ext {
+				return f.name
+			}
+		}
+	}
+	return inputFormats[0].name
+}
+
+// ParseFile 读取本地文件并根据内容识别格式后解析为 Sheet 列表，支持目录形式的 MindNode bundle
+func ParseFile(filePath string) ([]Sheet, error) {
+	return ParseFileAs(filePath, "auto")
+}
+
+// ParseFileAs 按指定的输入格式解析本地文件，format 为 "auto" 或空时根据文件内容识别
+func ParseFileAs(filePath, format string) ([]Sheet, error) {
+	return ParseFileAsContext(context.Background(), filePath, format)
+}
+
+// ParseFileAsContext 与 ParseFileAs 相同，ctx 被取消或超时后停止读取文件并返回 ctx.Err()
+func ParseFileAsContext(ctx context.Context, filePath, format string) ([]Sheet, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, i18n.Errorf("打开文件失败: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, i18n.Errorf("打开文件失败: %w", err)
+	}
+	// 目录形式的 MindNode bundle
+	if info.IsDir() {
+		if format == "mindnode" || (isAuto(format) && FormatOf(filePath) == "mindnode") {
+			sheets, err := readMindNodeBundle(filePath)
+			if err == nil {
+				err = CheckLimits(sheets)
+			}
+			if err != nil {
+				return nil, withFile(err, filePath)
+			}
+			return sheets, nil
+		}
+		return nil, i18n.Errorf("%s 是一个目录", filePath)
+	}
+	// 扩展名属于其他已知格式时，内容识别为纯文本或无法识别通常意味着文件已损坏，
+	// 按扩展名解析以得到更准确的错误信息
+	if isAuto(format) && IsInputFile(filePath) && FormatOf(filePath) != "txt" {
+		detected, err := DetectFormat(ctxReaderAt{ctx: ctx, ra: f}, info.Size())
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err != nil || detected == "txt" {
+			detected = FormatOf(filePath)
+		}
+		format = detected
+	}
+	// .txt 文件的内容无法识别时（如以 <、{ 或 [ 开头的提纲）仍按缩进文本解析
+	if isAuto(format) && FormatOf(filePath) == "txt" {
+		detected, err := DetectFormat(ctxReaderAt{ctx: ctx, ra: f}, info.Size())
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			detected = "txt"
+		}
+		format = detected
+	}
+	sheets, err := ParseAsContext(ctx, format, f, info.Size())
+	if err != nil && ctx.Err() == nil {
+		return nil, withFile(err, filePath)
+	}
+	return sheets, err
+}
+
+// ParseNamed 根据文件名的扩展名选择对应的解析器，从 r 中解析出 Sheet 列表
+func ParseNamed(name string, ra io.ReaderAt, size int64) ([]Sheet, error) {
+	return ParseAs(FormatOf(name), ra, size)
+}
+
+// ParseAs 按指定的输入格式从 r 中解析出 Sheet 列表，format 为 "auto" 或空时根据内容识别
+func ParseAs(format string, ra io.ReaderAt, size int64) ([]Sheet, error) {
+	if isAuto(format) {
+		detected, err := DetectFormat(ra, size)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+	for _, f := range inputFormats {
+		if f.name == format {
+			sheets, err := f.parse(ra, size)
+			if err != nil {
+				return nil, err
+			}
+			// 各解析器只保证自身不会因为层级过深而崩溃，层数与节点数的限制统一在这里检查
+			if err := CheckLimits(sheets); err != nil {
+				return nil, err
+			}
+			return sheets, nil
+		}
+	}
+	return nil, errorf(ErrUnsupported, "不支持的输入格式: %s", format)
+}
+
+func isAuto(format string) bool {
+	return format == "" || format == "auto"
+}
+
+// readZipEntry 读取 ZIP 包中以 name 结尾的第一个文件
+func readZipEntry(ra io.ReaderAt, size int64, name string) ([]byte, error) {
+	r, err := openZip(ra, size)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range r.File {
+		if !strings.HasSuffix(f.Name, name) {
+			continue
+		}
+		if f.Flags&zipEncrypted != 0 {
+			return nil, errorf(ErrEncrypted, "%s 已加密，请先取消文件的密码", name)
+		}
+		rc, err := openEntry(f)
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, i18n.Errorf("打开 %s 失败: %v", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, i18n.Errorf("读取 %s 失败: %v", name, err)
+		}
+		return data, nil
+	}
+	return nil, errorf(ErrNoContent, "在压缩包中未找到 %s", name)
+}
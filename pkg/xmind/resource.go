@@ -0,0 +1,213 @@
+package xmind
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ResourceName 将 Image.Src 等资源地址转换为压缩包中的文件名，如 xap:resources/a.png 转换为 resources/a.png
+// 文件名来自不可信的文件，包含 ..、绝对路径、Windows 盘符或为空时返回的错误属于 ErrUnsafePath，
+// 因此返回的文件名可以直接作为相对路径使用，不会指向目录之外
+func ResourceName(src string) (string, error) {
+	name := strings.TrimPrefix(src, "xap:")
+	// Windows 路径分隔符与盘符在其他系统上只是普通字符，但同一个文件可能在 Windows 上解压，统一拒绝
+	if name == "" || strings.ContainsAny(name, `\:`) || strings.ContainsRune(name, 0) || path.IsAbs(name) {
+		return "", errorf(ErrUnsafePath, "不安全的资源路径: %q", src)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", errorf(ErrUnsafePath, "不安全的资源路径: %q", src)
+		}
+	}
+	name = path.Clean(name)
+	if name == "." {
+		return "", errorf(ErrUnsafePath, "不安全的资源路径: %q", src)
+	}
+	return name, nil
+}
+
+// ResourcePath 返回将资源 src 保存到 dir 中时的本地路径，用于提取图片等资源
+// 除了 ResourceName 的检查外，dir 中已经存在的同名上级目录为符号链接时同样返回 ErrUnsafePath，
+// 避免预先放置的符号链接使写入落到 dir 之外；dir 本身可以是符号链接
+func ResourcePath(dir, src string) (string, error) {
+	name, err := ResourceName(src)
+	if err != nil {
+		return "", err
+	}
+	p := dir
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		p = filepath.Join(p, part)
+		info, err := os.Lstat(p)
+		if err != nil {
+			// 不存在的路径之后的部分都会由写入方新建
+			break
+		}
+		if info.Mode()&os.ModeSymlink != 0 || (i < len(parts)-1 && !info.IsDir()) {
+			return "", errorf(ErrUnsafePath, "不安全的资源路径: %q（%s 是符号链接或不是目录）", src, p)
+		}
+	}
+	return filepath.Join(dir, filepath.FromSlash(name)), nil
+}
+
+// Resource 为 .xmind 文件中的一个资源文件，如节点图片、附件或录音
+type Resource struct {
+	// Name 为资源在压缩包中的文件名，如 resources/xxx.png，可以直接传给 ReadResource
+	Name string
+	// Size 为资源解压后的字节数
+	Size int64
+	// MediaType 为 manifest 中记录的资源类型，如 image/png，没有记录时为空
+	MediaType string
+}
+
+// FileName 返回提取资源时使用的文件名：Name 没有扩展名而 manifest 中记录了 MediaType 时加上对应的扩展名
+func (r Resource) FileName() string {
+	if path.Ext(r.Name) != "" || r.MediaType == "" {
+		return r.Name
+	}
+	if ext, ok := mediaExts[r.MediaType]; ok {
+		return r.Name + ext
+	}
+	if exts, _ := mime.ExtensionsByType(r.MediaType); len(exts) > 0 {
+		return r.Name + exts[0]
+	}
+	return r.Name
+}
+
+// mediaExts 为常见资源类型的扩展名，mime.ExtensionsByType 按字母顺序返回，如 image/jpeg 的第一个是 .jfif
+var mediaExts = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/svg+xml":   ".svg",
+	"image/webp":      ".webp",
+	"audio/mpeg":      ".mp3",
+	"audio/mp4":       ".m4a",
+	"audio/wav":       ".wav",
+	"audio/x-wav":     ".wav",
+	"audio/ogg":       ".ogg",
+	"application/pdf": ".pdf",
+}
+
+// resourceDirs 为 XMind 保存资源的目录：resources 为图片等，attachments 为 XMind 8 的附件
+var resourceDirs = []string{"resources/", "attachments/"}
+
+// manifestTypes 读取压缩包中 manifest.json 与 XMind 8 的 META-INF/manifest.xml 记录的资源类型，无法读取时忽略
+func manifestTypes(r *zip.Reader) map[string]string {
+	types := map[string]string{}
+	for _, f := range r.File {
+		if f.Name != "manifest.json" && f.Name != "META-INF/manifest.xml" {
+			continue
+		}
+		rc, err := openEntry(f)
+		if err != nil {
+			continue
+		}
+		if f.Name == "manifest.json" {
+			var m struct {
+				FileEntries map[string]struct {
+					MediaType string `json:"media-type"`
+				} `json:"file-entries"`
+			}
+			if json.NewDecoder(limitJSONDepth(rc)).Decode(&m) == nil {
+				for name, e := range m.FileEntries {
+					if e.MediaType != "" {
+						types[path.Clean(name)] = e.MediaType
+					}
+				}
+			}
+		} else {
+			var m struct {
+				Entries []struct {
+					Path      string `xml:"full-path,attr"`
+					MediaType string `xml:"media-type,attr"`
+				} `xml:"file-entry"`
+			}
+			if xml.NewDecoder(rc).Decode(&m) == nil {
+				for _, e := range m.Entries {
+					if e.MediaType != "" {
+						types[path.Clean(e.Path)] = e.MediaType
+					}
+				}
+			}
+		}
+		rc.Close()
+	}
+	return types
+}
+
+// Resources 按文件名的顺序列出 .xmind 文件中的所有资源，包括没有被节点引用的资源，MediaType 取自 manifest
+// 不安全的文件名（见 ResourceName）与符号链接不会列出
+func Resources(ra io.ReaderAt, size int64) ([]Resource, error) {
+	r, err := openZip(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	types := manifestTypes(r)
+	var res []Resource
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || f.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		name, err := ResourceName(f.Name)
+		if err != nil {
+			continue
+		}
+		for _, dir := range resourceDirs {
+			if strings.HasPrefix(name, dir) {
+				res = append(res, Resource{Name: name, Size: int64(f.UncompressedSize64), MediaType: types[name]})
+				break
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res, nil
+}
+
+// Metadata 为 .xmind 文件中 metadata.json 记录的信息
+type Metadata struct {
+	// Creator 为保存文件的程序及其版本
+	Creator struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"creator"`
+	// ActiveSheetID 为上次保存时显示的画布的 ID
+	ActiveSheetID string `json:"activeSheetId,omitempty"`
+}
+
+// ReadMetadata 读取 .xmind 文件中的 metadata.json，文件中没有 metadata.json（如 XMind 8 的文件）时返回零值
+func ReadMetadata(ra io.ReaderAt, size int64) (Metadata, error) {
+	var m Metadata
+	r, err := openZip(ra, size)
+	if err != nil {
+		return m, err
+	}
+	for _, f := range r.File {
+		if f.Name != "metadata.json" {
+			continue
+		}
+		rc, err := openEntry(f)
+		if err != nil {
+			return m, err
+		}
+		defer rc.Close()
+		err = json.NewDecoder(limitJSONDepth(rc)).Decode(&m)
+		if errors.Is(err, ErrTooLarge) {
+			return m, err
+		}
+		if err != nil && err != io.EOF {
+			return m, errorf(ErrBadJSON, "解析 metadata.json 失败: %v", err)
+		}
+		return m, nil
+	}
+	return m, nil
+}
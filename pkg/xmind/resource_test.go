@@ -0,0 +1,119 @@
+package xmind
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"xap:resources/a.png", "resources/a.png"},
+		{"resources/a.png", "resources/a.png"},
+		{"xap:resources/./sub//b.png", "resources/sub/b.png"},
+		{"xap:attachments/报告.pdf", "attachments/报告.pdf"},
+		{"xap:resources/..a.png", "resources/..a.png"},
+	}
+	for _, tt := range tests {
+		got, err := ResourceName(tt.src)
+		if err != nil || got != tt.want {
+			t.Errorf("ResourceName(%q) = %q, %v，应为 %q", tt.src, got, err, tt.want)
+		}
+	}
+}
+
+// 可能指向目录之外的资源地址都应返回 ErrUnsafePath
+func TestResourceNameUnsafe(t *testing.T) {
+	tests := []string{
+		"",
+		"xap:",
+		"xap:.",
+		"xap:resources/..",
+		"xap:../a.png",
+		"xap:resources/../../a.png",
+		"xap:resources/../a.png",
+		"../../etc/passwd",
+		"/etc/passwd",
+		"xap:/etc/passwd",
+		`xap:resources\..\..\a.png`,
+		`xap:..\a.png`,
+		`xap:\\server\share\a.png`,
+		"xap:C:/Windows/a.png",
+		`xap:C:\Windows\a.png`,
+		"xap:resources/a.png\x00.txt",
+	}
+	for _, src := range tests {
+		got, err := ResourceName(src)
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("ResourceName(%q) = %q, %v，应返回 ErrUnsafePath", src, got, err)
+		}
+	}
+}
+
+func TestResourcePath(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "resources"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	symlinks := true
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		// Windows 上没有权限时无法创建符号链接
+		symlinks = false
+	}
+
+	got, err := ResourcePath(dir, "xap:resources/a.png")
+	if want := filepath.Join(dir, "resources", "a.png"); err != nil || got != want {
+		t.Errorf("ResourcePath() = %q, %v，应为 %q", got, err, want)
+	}
+	got, err = ResourcePath(dir, "xap:new/sub/a.png")
+	if want := filepath.Join(dir, "new", "sub", "a.png"); err != nil || got != want {
+		t.Errorf("ResourcePath() = %q, %v，应为 %q", got, err, want)
+	}
+
+	unsafe := []string{"xap:../a.png", "/a.png", `xap:resources\a.png`, "", "xap:file/a.png"}
+	if symlinks {
+		unsafe = append(unsafe, "xap:link/a.png", "xap:link")
+	}
+	for _, src := range unsafe {
+		got, err := ResourcePath(dir, src)
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("ResourcePath(%q) = %q, %v，应返回 ErrUnsafePath", src, got, err)
+		}
+	}
+}
+
+// 压缩包中不安全的文件名不会被列出，也不能被读取
+func TestResourcesUnsafeEntries(t *testing.T) {
+	data := zipFile(t,
+		"content.json", `[]`,
+		"resources/a.png", "a",
+		"resources/../../evil.png", "evil",
+		"/resources/abs.png", "abs",
+		`resources\..\..\win.png`, "win",
+	)
+	ra := bytes.NewReader(data)
+	res, err := Resources(ra, ra.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Name != "resources/a.png" {
+		t.Errorf("Resources() = %v，应只有 resources/a.png", res)
+	}
+	if got, err := ReadResource(ra, ra.Size(), "xap:resources/a.png"); err != nil || string(got) != "a" {
+		t.Errorf("ReadResource() = %q, %v，应为 \"a\"", got, err)
+	}
+	for _, src := range []string{"xap:resources/../../evil.png", "xap:/resources/abs.png", `xap:resources\..\..\win.png`} {
+		if _, err := ReadResource(ra, ra.Size(), src); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("ReadResource(%q) 返回 %v，应返回 ErrUnsafePath", src, err)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package xmind
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// smmxDocument 对应 SimpleMind 文件中的 document/mindmap.xml
+type smmxDocument struct {
+	Mindmap struct {
+		Topics []smmxTopic `xml:"topics>topic"`
+	} `xml:"mindmap"`
+}
+
+// smmxTopic 表示 SimpleMind 中的一个节点，层级关系通过 parent 属性描述
+type smmxTopic struct {
+	ID     string `xml:"id,attr"`
+	Parent string `xml:"parent,attr"`
+	Text   string `xml:"text,attr"`
+	Link   struct {
+		URL string `xml:"urllink,attr"`
+	} `xml:"link"`
+}
+
+// parseSimpleMind 解析 SimpleMind 的 .smmx 文件（ZIP 包）
+func parseSimpleMind(ra io.ReaderAt, size int64) ([]Sheet, error) {
+	data, err := readZipEntry(ra, size, "mindmap.xml")
+	if err != nil {
+		return nil, err
+	}
+	var doc smmxDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, errorf(ErrBadXML, "解析 XML 失败: %v", err)
+	}
+
+	topics := doc.Mindmap.Topics
+	if len(topics) == 0 {
+		return nil, errorf(ErrNoContent, "smmx 文件中没有任何节点")
+	}
+
+	// 按 parent 属性建立父子关系，保持节点在文件中的先后顺序
+	children := make(map[string][]int)
+	var roots []int
+	ids := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		ids[t.ID] = true
+	}
+	for i, t := range topics {
+		if t.Parent == "" || t.Parent == "-1" || !ids[t.Parent] {
+			roots = append(roots, i)
+			continue
+		}
+		children[t.Parent] = append(children[t.Parent], i)
+	}
+	if len(roots) == 0 {
+		roots = append(roots, 0)
+	}
+
+	// 节点通过 parent 属性形成的链可以任意长，超过 MaxDepth 层时停止递归并报错
+	tooDeep := false
+	var build func(i, depth int, seen map[string]bool) Topic
+	build = func(i, depth int, seen map[string]bool) Topic {
+		t := topics[i]
+		seen[t.ID] = true
+		if depth > MaxDepth {
+			tooDeep = true
+			return Topic{}
+		}
+		topic := Topic{
+			ID:    t.ID,
+			Title: smmxText(t.Text),
+			Href:  t.Link.URL,
+		}
+		for _, c := range children[t.ID] {
+			// 防止异常文件中的循环引用
+			if seen[topics[c].ID] {
+				continue
+			}
+			if topic.Children == nil {
+				topic.Children = &Children{}
+			}
+			topic.Children.Attached = append(topic.Children.Attached, build(c, depth+1, seen))
+		}
+		return topic
+	}
+
+	// 第一个中心主题作为根节点，其余中心主题视为分离的节点
+	seen := make(map[string]bool)
+	root := build(roots[0], 1, seen)
+	for _, i := range roots[1:] {
+		root.Detached = append(root.Detached, build(i, 2, seen))
+	}
+	if tooDeep {
+		return nil, errorf(ErrTooDeep, "节点超过 %d 层", MaxDepth)
+	}
+	return []Sheet{{ID: root.ID, RootTopic: root}}, nil
+}
+
+// smmxText 将 SimpleMind 文本中的 \N 换行标记还原为换行符
+func smmxText(s string) string {
+	return strings.ReplaceAll(s, `\N`, "\n")
+}
@@ -0,0 +1,96 @@
+package xmind
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// textNode 是解析缩进文本时使用的临时节点
+type textNode struct {
+	title    string
+	children []*textNode
+}
+
+// parseText 从 r 中读取缩进文本，没有缩进的行各自作为一个 sheet 的根节点
+// 层级按已经出现过的缩进宽度确定（与 Python 处理缩进的方式相同），因此每级缩进两个、四个空格或 Tab 都可以，
+// 回退缩进时挂到缩进比它小的最近一个节点下
+func parseText(r io.Reader) ([]Sheet, error) {
+	var roots []*textNode
+	// stack[i] 为当前第 i 级的最后一个节点，widths[i] 为其缩进宽度
+	var stack []*textNode
+	var widths []int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if len(roots) == 0 {
+			// 去除 UTF-8 BOM
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		title := strings.TrimLeft(line, " \t")
+		if title == "" {
+			continue
+		}
+
+		width := textIndentWidth(line[:len(line)-len(title)])
+		// 缩进不大于的上级节点都已结束，缩进跳级时同样只深一级
+		level := len(stack)
+		for level > 0 && widths[level-1] >= width {
+			level--
+		}
+		// 转换为 Topic 时递归处理节点，先限制层数
+		if level >= MaxDepth {
+			return nil, errorf(ErrTooDeep, "节点超过 %d 层", MaxDepth)
+		}
+		node := &textNode{title: title}
+		if level == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[level-1]
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack[:level], node)
+		widths = append(widths[:level], width)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, i18n.Errorf("读取文本失败: %v", err)
+	}
+	if len(roots) == 0 {
+		return nil, errorf(ErrNoContent, "文本中没有任何节点")
+	}
+
+	sheets := make([]Sheet, 0, len(roots))
+	for _, root := range roots {
+		sheets = append(sheets, Sheet{RootTopic: root.topic()})
+	}
+	return sheets, nil
+}
+
+// textIndentWidth 计算缩进的宽度，空格计 1，Tab 补齐到下一个 8 的倍数
+func textIndentWidth(indent string) int {
+	width := 0
+	for _, c := range indent {
+		if c == '\t' {
+			width += 8 - width%8
+			continue
+		}
+		width++
+	}
+	return width
+}
+
+// topic 将临时节点转换为 Topic
+func (n *textNode) topic() Topic {
+	t := Topic{Title: n.title}
+	if len(n.children) > 0 {
+		t.Children = &Children{}
+		for _, c := range n.children {
+			t.Children.Attached = append(t.Children.Attached, c.topic())
+		}
+	}
+	return t
+}
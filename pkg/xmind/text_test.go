@@ -0,0 +1,70 @@
+package xmind
+
+import (
+	"strings"
+	"testing"
+)
+
+// outline 按层级输出节点标题，每级前加一个 "."，用于比较解析得到的结构
+func outline(sheets []Sheet) string {
+	var b strings.Builder
+	var walk func(t Topic, level int)
+	walk = func(t Topic, level int) {
+		b.WriteString(strings.Repeat(".", level) + t.Title + "\n")
+		if t.Children != nil {
+			for _, c := range t.Children.Attached {
+				walk(c, level+1)
+			}
+		}
+	}
+	for _, s := range sheets {
+		walk(s.RootTopic, 0)
+	}
+	return b.String()
+}
+
+func TestParseTextIndent(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			"两个空格",
+			"Root\n  A\n    A1\n  B\n",
+			"Root\n.A\n..A1\n.B\n",
+		},
+		{
+			// 从第 4 层一次回退两级到第 2 层
+			"四个空格回退多级",
+			"Root\n    A\n        A1\n            A1a\n    B\n        B1\n",
+			"Root\n.A\n..A1\n...A1a\n.B\n..B1\n",
+		},
+		{
+			"Tab",
+			"Root\n\tA\n\t\tA1\n\tB\n",
+			"Root\n.A\n..A1\n.B\n",
+		},
+		{
+			// 缩进跳级时只深一级，回退到没有出现过的宽度时挂到缩进更小的节点下
+			"跳级与不对齐的回退",
+			"Root\n      A\n          A1\n    B\n",
+			"Root\n.A\n..A1\n.B\n",
+		},
+		{
+			"多个根节点",
+			"One\n    A\nTwo\n    B\n",
+			"One\n.A\nTwo\n.B\n",
+		},
+	}
+	for _, tt := range tests {
+		sheets, err := parseText(strings.NewReader(tt.input))
+		if err != nil {
+			t.Errorf("%s: parseText() 出错: %v", tt.name, err)
+			continue
+		}
+		if got := outline(sheets); got != tt.want {
+			t.Errorf("%s: parseText() 得到\n%s应为\n%s", tt.name, got, tt.want)
+		}
+	}
+}
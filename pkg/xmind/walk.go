@@ -0,0 +1,37 @@
+package xmind
+
+import "errors"
+
+// SkipChildren 可以由 Walk 的回调函数返回，表示不再遍历当前节点的子节点，遍历继续进行
+var SkipChildren = errors.New("跳过子节点")
+
+// Walk 按先序遍历画布中的所有节点，依次为根节点、子节点与分离的节点，对每个节点调用 fn
+// path 为从根节点到 t 的所有上级节点（不含 t），根节点的 path 为空；path 在 fn 返回后会被复用，需要保留时应复制
+// fn 可以直接修改 t；返回 SkipChildren 时跳过 t 的子节点，返回其他错误时停止遍历，Walk 返回该错误
+func (s *Sheet) Walk(fn func(path []*Topic, t *Topic) error) error {
+	return walkTopic(nil, &s.RootTopic, fn)
+}
+
+// walkTopic 遍历 t 及其子节点，path 为 t 的上级节点
+func walkTopic(path []*Topic, t *Topic, fn func(path []*Topic, t *Topic) error) error {
+	if err := fn(path, t); err != nil {
+		if err == SkipChildren {
+			return nil
+		}
+		return err
+	}
+	path = append(path, t)
+	if t.Children != nil {
+		for i := range t.Children.Attached {
+			if err := walkTopic(path, &t.Children.Attached[i], fn); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range t.Detached {
+		if err := walkTopic(path, &t.Detached[i], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
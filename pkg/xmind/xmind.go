@@ -0,0 +1,236 @@
+// Package xmind 解析 XMind 及其他常见思维导图格式。
+//
+// 解析结果统一为 Sheet / Topic 结构，数据既可以来自文件，也可以来自内存，
+// 写出 Markdown 等格式见 github.com/Will-Liang/xmindtomarkdown/pkg/render：
+//
+//	sheets, err := xmind.ParseBytes(data)
+//	if err != nil {
+//		return err
+//	}
+//	render.WriteMarkdown(w, sheets)
+package xmind
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// Parse 从任意 io.ReaderAt 中解析 .xmind 文件（ZIP 包）的内容，与 ParseAs 相同按 DefaultLimits 检查层数、节点数与节点内容的大小
+func Parse(ra io.ReaderAt, size int64) ([]Sheet, error) {
+	sheets, err := parseXMind(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckLimits(sheets); err != nil {
+		return nil, err
+	}
+	return sheets, nil
+}
+
+// parseXMind 解析 .xmind 文件而不检查 CheckLimits 的各项限制，由 ParseAs 统一检查
+func parseXMind(ra io.ReaderAt, size int64) ([]Sheet, error) {
+	var sheets []Sheet
+	err := EachSheet(ra, size, func(s Sheet) error {
+		sheets = append(sheets, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sheets, nil
+}
+
+// EachSheet 逐个解析 .xmind 文件中的画布并依次传给 fn，fn 返回错误时停止解析并返回该错误
+// content.json 边读取边解析，不会整个读入内存，只需要逐个处理画布时内存占用只与最大的一个画布有关
+// 压缩包的文件数与解压后的大小按 DefaultLimits 检查，节点数等需要所有画布才能判断的限制不检查，需要时对 fn 收到的画布调用 CheckLimits
+func EachSheet(ra io.ReaderAt, size int64, fn func(Sheet) error) error {
+	r, err := openZip(ra, size)
+	if err != nil {
+		return err
+	}
+
+	var contentJSON io.ReadCloser
+	var contentXML *zip.File
+	// 遍历压缩包，查找 content.json 文件
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "content.json") {
+			if encrypted(r, f) {
+				return errorf(ErrEncrypted, "文件已设置密码，请在 XMind 中取消密码后再转换")
+			}
+			contentJSON, err = openEntry(f)
+			if errors.Is(err, ErrTooLarge) {
+				return err
+			}
+			if err != nil {
+				return i18n.Errorf("打开 content.json 失败: %v", err)
+			}
+			break
+		}
+		if strings.HasSuffix(f.Name, "content.xml") {
+			contentXML = f
+		}
+	}
+	// XMind 8 及更早版本只有 content.xml
+	if contentJSON == nil && contentXML != nil {
+		sheets, err := parseXMindXML(contentXML)
+		if err != nil {
+			return err
+		}
+		for _, s := range sheets {
+			if err := fn(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if contentJSON == nil {
+		return errorf(ErrNoContent, "在 xmind 文件中未找到 content.json")
+	}
+	defer contentJSON.Close()
+
+	// 最外层为画布数组，逐个解析画布以便在错误中指出出错的画布
+	dec := json.NewDecoder(limitJSONDepth(contentJSON))
+	tok, err := dec.Token()
+	if err != nil {
+		return contentJSONError(err)
+	}
+	if tok == nil {
+		// content.json 为 null 时没有任何画布
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return errorf(ErrBadJSON, "解析 JSON 失败: 最外层不是数组")
+	}
+	for i := 1; dec.More(); i++ {
+		var s Sheet
+		if err := dec.Decode(&s); err != nil {
+			e := &Error{Sheet: i, Err: contentJSONError(err)}
+			var te *json.UnmarshalTypeError
+			if errors.As(err, &te) {
+				e.Path = te.Field
+			}
+			return e
+		}
+		normalizeDetached(&s.RootTopic)
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return contentJSONError(err)
+	}
+	return nil
+}
+
+// contentJSONError 区分解析 content.json 时的格式错误与读取压缩包时的错误
+func contentJSONError(err error) error {
+	if errors.Is(err, ErrTooLarge) {
+		return err
+	}
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &se) || errors.As(err, &te) || err == io.EOF || err == io.ErrUnexpectedEOF {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return errorf(ErrBadJSON, "解析 JSON 失败: %v", err)
+	}
+	return i18n.Errorf("读取 content.json 失败: %v", err)
+}
+
+// zipEncrypted 为 ZIP 文件头中表示内容已加密的标记位
+const zipEncrypted = 0x1
+
+// encrypted 判断压缩包中的 f 是否已加密：ZIP 自身的加密标记，或 XMind 设置密码后在 manifest.json 中记录的加密信息
+func encrypted(r *zip.Reader, f *zip.File) bool {
+	if f.Flags&zipEncrypted != 0 {
+		return true
+	}
+	for _, m := range r.File {
+		if m.Name != "manifest.json" {
+			continue
+		}
+		rc, err := openEntry(m)
+		if err != nil {
+			return false
+		}
+		defer rc.Close()
+		var manifest struct {
+			FileEntries map[string]struct {
+				EncryptionData json.RawMessage `json:"encryption-data"`
+			} `json:"file-entries"`
+		}
+		if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+			return false
+		}
+		return len(manifest.FileEntries[f.Name].EncryptionData) > 0
+	}
+	return false
+}
+
+// normalizeDetached 将 children.detached 中的节点移动到 Topic.Detached
+func normalizeDetached(t *Topic) {
+	if t.Children != nil && len(t.Children.Detached) > 0 {
+		t.Detached = append(t.Detached, t.Children.Detached...)
+		t.Children.Detached = nil
+	}
+	if t.Children != nil {
+		for i := range t.Children.Attached {
+			normalizeDetached(&t.Children.Attached[i])
+		}
+	}
+	for i := range t.Detached {
+		normalizeDetached(&t.Detached[i])
+	}
+}
+
+// ParseBytes 解析保存在内存中的 .xmind 文件内容，限制与 Parse 相同
+func ParseBytes(data []byte) ([]Sheet, error) {
+	return Parse(bytes.NewReader(data), int64(len(data)))
+}
+
+// ReadResource 读取 .xmind 文件中节点图片等资源的内容，src 为 Image.Src，形如 xap:resources/xxx.png
+// 文件中没有该资源时返回的错误属于 ErrResourceMissing，src 不安全（见 ResourceName）或该资源为符号链接时属于 ErrUnsafePath
+func ReadResource(ra io.ReaderAt, size int64, src string) ([]byte, error) {
+	name, err := ResourceName(src)
+	if err != nil {
+		return nil, err
+	}
+	r, err := openZip(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		if path.Clean(f.Name) != name {
+			continue
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			return nil, errorf(ErrUnsafePath, "资源 %s 是符号链接", name)
+		}
+		rc, err := openEntry(f)
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, i18n.Errorf("打开 %s 失败: %v", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if errors.Is(err, ErrTooLarge) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, i18n.Errorf("读取 %s 失败: %v", name, err)
+		}
+		return data, nil
+	}
+	return nil, errorf(ErrResourceMissing, "文件中缺少资源 %s", name)
+}
@@ -0,0 +1,85 @@
+package xmind
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// xmapContent 对应 XMind 8 及更早版本的 content.xml
+type xmapContent struct {
+	Sheets []xmapSheet `xml:"sheet"`
+}
+
+type xmapSheet struct {
+	ID    string    `xml:"id,attr"`
+	Title string    `xml:"title"`
+	Topic xmapTopic `xml:"topic"`
+}
+
+type xmapTopic struct {
+	ID             string `xml:"id,attr"`
+	StructureClass string `xml:"structure-class,attr"`
+	Href           string `xml:"href,attr"`
+	Title          string `xml:"title"`
+	Children       []struct {
+		Type   string      `xml:"type,attr"`
+		Topics []xmapTopic `xml:"topic"`
+	} `xml:"children>topics"`
+}
+
+// parseXMindXML 解析旧版 XMind 的 content.xml
+func parseXMindXML(f *zip.File) ([]Sheet, error) {
+	rc, err := openEntry(f)
+	if errors.Is(err, ErrTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, i18n.Errorf("打开 content.xml 失败: %v", err)
+	}
+	defer rc.Close()
+
+	var doc xmapContent
+	if err := xml.NewDecoder(rc).Decode(&doc); errors.Is(err, ErrTooLarge) {
+		return nil, err
+	} else if err != nil {
+		return nil, errorf(ErrBadXML, "解析 XML 失败: %v", err)
+	}
+
+	sheets := make([]Sheet, 0, len(doc.Sheets))
+	for _, s := range doc.Sheets {
+		sheets = append(sheets, Sheet{
+			ID:        s.ID,
+			Class:     "sheet",
+			Title:     s.Title,
+			RootTopic: s.Topic.topic(),
+		})
+	}
+	return sheets, nil
+}
+
+// topic 递归转换 content.xml 中的节点
+func (x xmapTopic) topic() Topic {
+	t := Topic{
+		ID:             x.ID,
+		Class:          "topic",
+		Title:          x.Title,
+		StructureClass: x.StructureClass,
+		Href:           x.Href,
+	}
+	for _, group := range x.Children {
+		for _, c := range group.Topics {
+			if group.Type == "detached" {
+				t.Detached = append(t.Detached, c.topic())
+				continue
+			}
+			if t.Children == nil {
+				t.Children = &Children{}
+			}
+			t.Children.Attached = append(t.Children.Attached, c.topic())
+		}
+	}
+	return t
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// pluginPrefix 为输出格式插件可执行文件名的前缀，格式 x 对应 PATH 中的 xmind2md-render-x
+const pluginPrefix = "xmind2md-render-"
+
+// checkFormat 检查输出格式是否可用，不是内置格式时在 PATH 中查找对应的插件并注册为输出格式
+func checkFormat(format string) error {
+	_, err := render.OutputExt(format)
+	if err == nil || !validPluginName(format) {
+		return err
+	}
+	path, lerr := exec.LookPath(pluginPrefix + format)
+	if lerr != nil {
+		return err
+	}
+	logf(levelVerbose, "使用输出格式插件 %s", path, slog.String("plugin", path))
+	return render.Register(render.Format{
+		Name:     format,
+		Ext:      "." + format,
+		Renderer: pluginRenderer{format: format, path: path},
+		// 插件的输出原样写出，不添加 BOM 或转换换行符
+		Binary: true,
+	})
+}
+
+// validPluginName 判断 format 能否作为插件名，只允许小写字母、数字、- 与 _，避免拼出其他路径
+func validPluginName(format string) bool {
+	if format == "" {
+		return false
+	}
+	for _, r := range format {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// pluginRenderer 调用外部插件写出输出格式：向插件的标准输入写入 JSON 格式的中间表示（见 xmind.WriteIR），
+// 插件的标准输出即为转换结果，退出码不为 0 时转换失败
+type pluginRenderer struct {
+	format string
+	path   string
+}
+
+func (p pluginRenderer) Render(w io.Writer, sheets []xmind.Sheet, opts render.WriteOptions) error {
+	var in bytes.Buffer
+	if err := xmind.WriteIR(&in, sheets); err != nil {
+		return err
+	}
+	cmd := exec.Command(p.path)
+	cmd.Stdin = &in
+	cmd.Env = append(os.Environ(),
+		"XMIND2MD_FORMAT="+p.format,
+		"XMIND2MD_IR_VERSION="+strconv.Itoa(xmind.IRVersion),
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return i18n.Errorf("启动插件 %s 失败: %v", p.path, err)
+	}
+	// 写入 w 失败（如转换被中断）时结束插件，避免插件因管道写满一直等待
+	if _, err := io.Copy(w, out); err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return i18n.Errorf("插件 %s 执行失败: %v %s", p.path, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
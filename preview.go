@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// ANSI 样式
+const (
+	ansiReset     = "\x1b[0m"
+	ansiBold      = "\x1b[1m"
+	ansiDim       = "\x1b[2m"
+	ansiItalic    = "\x1b[3m"
+	ansiUnderline = "\x1b[4m"
+	ansiH1        = "\x1b[1;4;35m"
+	ansiH2        = "\x1b[1;36m"
+	ansiCode      = "\x1b[33m"
+)
+
+var (
+	previewHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	previewList    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	previewLink    = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]*)\)`)
+	previewBold    = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	previewItalic  = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	previewCode    = regexp.MustCompile("`([^`]+)`")
+	previewEscape  = regexp.MustCompile("\\\\([\\\\`*_{}\\[\\]<>()#+\\-.!|])")
+)
+
+// printPreview 将 sheets 按 Markdown 输出后在终端中渲染，标准输出为终端时使用 ANSI 样式
+// 设置了 NO_COLOR 或输出不是终端时去掉 Markdown 标记后输出纯文本
+func printPreview(w io.Writer, sheets []xmind.Sheet, opts convertOptions) {
+	var buf bytes.Buffer
+	render.WriteMarkdownOptions(&buf, sheets, opts.Write)
+	color := false
+	if f, ok := w.(*os.File); ok {
+		color = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && enableANSI(f)
+	}
+	renderMarkdown(w, &buf, color)
+}
+
+// renderMarkdown 逐行渲染标题、列表、链接与强调等常见的 Markdown 标记，连续的空行合并为一行
+func renderMarkdown(w io.Writer, r io.Reader, color bool) {
+	s := previewStyle{color: color}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	blank, fenced := true, false
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if fenced {
+			fmt.Fprintln(w, s.wrap(ansiCode, "    "+line))
+			blank = false
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if !blank {
+				fmt.Fprintln(w)
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		if m := previewHeading.FindStringSubmatch(line); m != nil {
+			fmt.Fprintln(w, s.heading(len(m[1]), s.inline(m[2])))
+			continue
+		}
+		if m := previewList.FindStringSubmatch(line); m != nil {
+			bullet := m[2]
+			if strings.ContainsAny(bullet, "-*+") {
+				bullet = "•"
+			}
+			fmt.Fprintf(w, "%s%s %s\n", m[1], s.wrap(ansiBold, bullet), s.inline(m[3]))
+			continue
+		}
+		fmt.Fprintln(w, s.inline(line))
+	}
+}
+
+// previewStyle 按是否使用 ANSI 样式渲染文本
+type previewStyle struct {
+	color bool
+}
+
+func (s previewStyle) wrap(style, text string) string {
+	if !s.color || text == "" {
+		return text
+	}
+	return style + text + ansiReset
+}
+
+// heading 渲染标题：h1 与 h2 使用不同颜色，纯文本时在下方画线标示
+func (s previewStyle) heading(level int, text string) string {
+	if s.color {
+		switch level {
+		case 1:
+			return s.wrap(ansiH1, text)
+		case 2:
+			return s.wrap(ansiH2, text)
+		}
+		return s.wrap(ansiBold, strings.Repeat("  ", level-3)+text)
+	}
+	switch level {
+	case 1:
+		return text + "\n" + strings.Repeat("=", displayWidth(text))
+	case 2:
+		return text + "\n" + strings.Repeat("-", displayWidth(text))
+	}
+	return strings.Repeat("  ", level-3) + text
+}
+
+// inline 渲染行内的链接、粗体、斜体与代码
+func (s previewStyle) inline(text string) string {
+	// 转义的字符先替换为占位符，渲染后再还原，避免被识别为强调等标记
+	var escaped []string
+	text = previewEscape.ReplaceAllStringFunc(text, func(m string) string {
+		escaped = append(escaped, m[1:])
+		return fmt.Sprintf("\x00%d\x00", len(escaped)-1)
+	})
+	text = previewCode.ReplaceAllStringFunc(text, func(m string) string {
+		return s.wrap(ansiCode, previewCode.FindStringSubmatch(m)[1])
+	})
+	text = previewLink.ReplaceAllStringFunc(text, func(m string) string {
+		sub := previewLink.FindStringSubmatch(m)
+		return s.wrap(ansiUnderline, sub[1]) + " " + s.wrap(ansiDim, "("+sub[2]+")")
+	})
+	text = previewBold.ReplaceAllStringFunc(text, func(m string) string {
+		sub := previewBold.FindStringSubmatch(m)
+		return s.wrap(ansiBold, sub[1]+sub[2])
+	})
+	text = previewItalic.ReplaceAllStringFunc(text, func(m string) string {
+		sub := previewItalic.FindStringSubmatch(m)
+		return s.wrap(ansiItalic, sub[1]+sub[2])
+	})
+	for i, e := range escaped {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), e, 1)
+	}
+	return text
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// progressDelay 为开始显示进度前等待的时间，很快就能完成的转换不显示进度
+const progressDelay = 300 * time.Millisecond
+
+// spinnerFrames 为正在转换的文件前显示的动画
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// progress 在标准错误的同一行中显示批量转换的进度，包括完成数、当前文件与已用时间
+type progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	total   int
+	done    int
+	failed  int
+	current string
+	start   time.Time
+	frame   int
+	// width 为上一次输出的显示宽度，用于清除该行
+	width int
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// activeProgress 为正在显示的进度，logf 输出前会先清除进度行
+var (
+	activeProgress   *progress
+	activeProgressMu sync.Mutex
+)
+
+// startProgress 开始显示 total 个文件的转换进度，并定时刷新
+func startProgress(w io.Writer, total int) *progress {
+	p := &progress{w: w, total: total, start: time.Now(), stop: make(chan struct{})}
+	activeProgressMu.Lock()
+	activeProgress = p
+	activeProgressMu.Unlock()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.frame++
+				p.render()
+				p.mu.Unlock()
+			}
+		}
+	}()
+	return p
+}
+
+// begin 记录开始转换的文件
+func (p *progress) begin(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.current = name
+	p.render()
+	p.mu.Unlock()
+}
+
+// finish 记录当前文件转换完成
+func (p *progress) finish(err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.done++
+	if err != nil {
+		p.failed++
+	}
+	p.current = ""
+	p.render()
+	p.mu.Unlock()
+}
+
+// close 停止刷新并清除进度行
+func (p *progress) close() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	activeProgressMu.Lock()
+	activeProgress = nil
+	activeProgressMu.Unlock()
+	p.mu.Lock()
+	p.clear()
+	p.mu.Unlock()
+}
+
+// render 重新输出进度行，调用时需持有 p.mu
+func (p *progress) render() {
+	elapsed := time.Since(p.start)
+	if elapsed < progressDelay {
+		return
+	}
+	const barWidth = 20
+	filled := barWidth * p.done / p.total
+	line := fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), p.done, p.total)
+	if p.failed > 0 {
+		line += fmt.Sprintf(i18n.T("，失败 %d"), p.failed)
+	}
+	line += fmt.Sprintf(" %.1fs", elapsed.Seconds())
+	if p.current != "" {
+		line += " " + spinnerFrames[p.frame%len(spinnerFrames)] + " " + truncateWidth(p.current, 40)
+	}
+	p.clear()
+	fmt.Fprint(p.w, line)
+	p.width = displayWidth(line)
+}
+
+// clear 清除进度行，调用时需持有 p.mu
+// 用空格覆盖而不是使用 ANSI 控制序列，使旧版 Windows 控制台也能正常显示
+func (p *progress) clear() {
+	if p.width > 0 {
+		fmt.Fprint(p.w, "\r"+strings.Repeat(" ", p.width)+"\r")
+		p.width = 0
+	}
+}
+
+// suspendProgress 清除正在显示的进度行并加锁，返回的函数恢复显示，用于在进度行之间输出其他信息
+func suspendProgress() func() {
+	activeProgressMu.Lock()
+	p := activeProgress
+	activeProgressMu.Unlock()
+	if p == nil {
+		return func() {}
+	}
+	p.mu.Lock()
+	p.clear()
+	return func() {
+		p.render()
+		p.mu.Unlock()
+	}
+}
+
+// truncateWidth 将 s 截断到不超过 max 的显示宽度，截断时保留末尾部分
+func truncateWidth(s string, max int) string {
+	if displayWidth(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && displayWidth(string(r))+3 > max {
+		r = r[1:]
+	}
+	return "..." + string(r)
+}
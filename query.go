@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var queryCommand = &command{
+	Name:  "query",
+	Args:  "[参数] <表达式> <文件>...",
+	Short: "用类似 XPath 的表达式（如 //topic[label='风险']/title）选出节点或节点的内容",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var asJSON bool
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出结果")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) < 2 {
+				return withCode(exitUsage, i18n.Errorf("query 需要指定表达式与至少一个文件"))
+			}
+			q, err := parseQuery(args[0])
+			if err != nil {
+				return withCode(exitUsage, err)
+			}
+			results := []queryResult{}
+			for _, in := range args[1:] {
+				sheets, _, err := readInputSheets(ctx, in, opts)
+				if err != nil {
+					return &fileError{path: in, err: err}
+				}
+				results = append(results, q.run(in, sheets)...)
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return withCode(exitWrite, err)
+				}
+				return nil
+			}
+			printQueryResults(os.Stdout, results, len(args) > 2)
+			return nil
+		}
+	},
+}
+
+// topicQuery 为解析后的查询表达式：依次匹配的若干步，以及最后要输出的字段，field 为空时输出节点的位置
+type topicQuery struct {
+	steps []queryStep
+	field string
+}
+
+// queryStep 为表达式中的一步，deep 为 true 时（//）匹配上一步结果的所有后代节点，否则（/）只匹配子节点
+type queryStep struct {
+	deep  bool
+	preds []queryPred
+}
+
+// queryPred 为一步中方括号内的条件，index 大于 0 时为位置条件 [n]，否则为 or 连接的若干组 and 连接的比较
+type queryPred struct {
+	index int
+	any   [][]queryCond
+}
+
+// queryCond 为一个比较，op 为空时判断字段是否有内容
+type queryCond struct {
+	field, op, value string
+	num              int
+	re               *regexp.Regexp
+}
+
+// queryResult 为查询的一个结果，Path 依次为画布（有标题时）与各级节点的标题，
+// 表达式以字段结尾时 Field 与 Value 为该字段的一个值
+type queryResult struct {
+	File    string   `json:"file"`
+	Path    []string `json:"path"`
+	TopicID string   `json:"topicId,omitempty"`
+	Field   string   `json:"field,omitempty"`
+	Value   string   `json:"value,omitempty"`
+}
+
+// queryFields 为可以在条件中比较或作为最后一步输出的字段，labels 与 markers 同 label 与 marker
+var queryFields = []string{"title", "notes", "id", "href", "label", "labels", "marker", "markers", "depth", "children", "path"}
+
+// queryNumeric 为按数字比较的字段
+var queryNumeric = []string{"depth", "children"}
+
+// parseQuery 解析查询表达式，语法为若干步 /topic 或 //topic（topic 也可以写作 *），
+// 每步之后可以有条件 [字段 运算符 值]，最后可以用 /字段 选择输出的内容
+func parseQuery(expr string) (*topicQuery, error) {
+	p := &queryParser{s: expr}
+	q := &topicQuery{}
+	p.space()
+	for p.pos < len(p.s) {
+		if !p.eat("/") {
+			return nil, p.fail("/")
+		}
+		step := queryStep{deep: p.eat("/")}
+		p.space()
+		start := p.pos
+		name := p.name()
+		if name != "topic" && name != "*" {
+			// 最后一步可以是字段
+			if !step.deep && len(q.steps) > 0 && oneOf(name, queryFields) {
+				q.field = queryField(name)
+				if p.space(); p.pos < len(p.s) {
+					return nil, i18n.Errorf("无效的查询: 字段 %s 只能是表达式的最后一步", name)
+				}
+				break
+			}
+			p.pos = start
+			return nil, p.fail("topic")
+		}
+		for p.space(); p.eat("["); p.space() {
+			pred, err := p.pred()
+			if err != nil {
+				return nil, err
+			}
+			step.preds = append(step.preds, pred)
+		}
+		q.steps = append(q.steps, step)
+	}
+	if len(q.steps) == 0 {
+		return nil, i18n.Errorf("无效的查询: 表达式为空")
+	}
+	return q, nil
+}
+
+// queryParser 按字符解析查询表达式，pos 为当前的位置
+type queryParser struct {
+	s   string
+	pos int
+}
+
+func (p *queryParser) space() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// eat 在当前位置为 tok 时跳过它并返回 true
+func (p *queryParser) eat(tok string) bool {
+	if strings.HasPrefix(p.s[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// name 读取一个字段名、topic、and 等名称或 *，没有时返回空字符串
+func (p *queryParser) name() string {
+	if p.eat("*") {
+		return "*"
+	}
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c != '_' && c != '-' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// fail 返回当前位置应为 want 的错误
+func (p *queryParser) fail(want string) error {
+	if p.pos >= len(p.s) {
+		return i18n.Errorf("无效的查询: 表达式在应为 %s 的地方结束", want)
+	}
+	return i18n.Errorf("无效的查询: 第 %d 个字符处应为 %s", len([]rune(p.s[:p.pos]))+1, want)
+}
+
+// pred 解析 [ 之后的条件，直到 ]
+func (p *queryParser) pred() (queryPred, error) {
+	var pred queryPred
+	p.space()
+	if start := p.pos; p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		pred.index, _ = strconv.Atoi(p.s[start:p.pos])
+		if p.space(); pred.index < 1 || !p.eat("]") {
+			return pred, p.fail("]")
+		}
+		return pred, nil
+	}
+	all := []queryCond{}
+	for {
+		c, err := p.cond()
+		if err != nil {
+			return pred, err
+		}
+		all = append(all, c)
+		p.space()
+		switch {
+		case p.eat("]"):
+			pred.any = append(pred.any, all)
+			return pred, nil
+		case p.eat("and"):
+		case p.eat("or"):
+			pred.any = append(pred.any, all)
+			all = []queryCond{}
+		default:
+			return pred, p.fail("]")
+		}
+		p.space()
+	}
+}
+
+// cond 解析一个比较：字段 运算符 值，或只有字段
+func (p *queryParser) cond() (queryCond, error) {
+	var c queryCond
+	start := p.pos
+	c.field = p.name()
+	if !oneOf(c.field, queryFields) {
+		p.pos = start
+		return c, p.fail(i18n.T("字段名"))
+	}
+	c.field = queryField(c.field)
+	p.space()
+	for _, op := range []string{"!=", "~=", "*=", "<=", ">=", "=", "<", ">"} {
+		if p.eat(op) {
+			c.op = op
+			break
+		}
+	}
+	if c.op == "" {
+		return c, nil
+	}
+	numeric := oneOf(c.field, queryNumeric)
+	switch {
+	case numeric && (c.op == "~=" || c.op == "*="):
+		return c, i18n.Errorf("无效的查询: %s 只能按数字比较", c.field)
+	case !numeric && strings.ContainsAny(c.op, "<>"):
+		return c, i18n.Errorf("无效的查询: %s 不能按数字比较", c.field)
+	}
+	p.space()
+	if numeric {
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		if start == p.pos {
+			return c, p.fail(i18n.T("数字"))
+		}
+		c.num, _ = strconv.Atoi(p.s[start:p.pos])
+		return c, nil
+	}
+	if p.pos >= len(p.s) || (p.s[p.pos] != '\'' && p.s[p.pos] != '"') {
+		return c, p.fail(i18n.T("引号括起的字符串"))
+	}
+	quote := p.s[p.pos : p.pos+1]
+	end := strings.Index(p.s[p.pos+1:], quote)
+	if end < 0 {
+		p.pos = len(p.s)
+		return c, p.fail(quote)
+	}
+	c.value = p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	if c.op == "~=" {
+		re, err := regexp.Compile(c.value)
+		if err != nil {
+			return c, i18n.Errorf("无效的查询: 无效的正则表达式 %q: %v", c.value, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+// queryField 返回字段的标准名称，labels 与 markers 同 label 与 marker
+func queryField(name string) string {
+	switch name {
+	case "labels":
+		return "label"
+	case "markers":
+		return "marker"
+	}
+	return name
+}
+
+// queryNode 为查询过程中的一个节点，topic 为 nil 时为包含所有画布根节点的文档，path 为上级节点
+type queryNode struct {
+	sheet int
+	path  []*xmind.Topic
+	topic *xmind.Topic
+}
+
+// run 在 sheets 中执行查询，结果按节点在文件中的先序排列，同一个节点只出现一次
+func (q *topicQuery) run(file string, sheets []xmind.Sheet) []queryResult {
+	// order 为每个节点的先序序号，用于排列结果
+	all := queryDescendants(sheets, queryNode{})
+	order := make(map[*xmind.Topic]int, len(all))
+	for i, n := range all {
+		order[n.topic] = i
+	}
+	nodes := []queryNode{{}}
+	for _, step := range q.steps {
+		seen := map[*xmind.Topic]bool{}
+		var next []queryNode
+		contexts := nodes
+		if step.deep {
+			// 与 XPath 相同，//topic 为上一步结果自身及其所有后代节点的子节点，位置条件按每个上级节点分别计算
+			contexts = nil
+			for _, ctx := range nodes {
+				contexts = append(append(contexts, ctx), queryDescendants(sheets, ctx)...)
+			}
+		}
+		for _, ctx := range contexts {
+			cands := queryChildren(sheets, ctx)
+			for _, pred := range step.preds {
+				cands = pred.filter(cands)
+			}
+			for _, n := range cands {
+				if !seen[n.topic] {
+					seen[n.topic] = true
+					next = append(next, n)
+				}
+			}
+		}
+		sort.SliceStable(next, func(i, j int) bool { return order[next[i].topic] < order[next[j].topic] })
+		nodes = next
+	}
+
+	var results []queryResult
+	for _, n := range nodes {
+		r := queryResult{File: file, Path: topicTrail(&sheets[n.sheet], n.path, n.topic), TopicID: n.topic.ID}
+		if q.field == "" {
+			results = append(results, r)
+			continue
+		}
+		r.Field = q.field
+		for _, v := range queryValues(n, q.field) {
+			if v != "" {
+				r.Value = v
+				results = append(results, r)
+			}
+		}
+	}
+	return results
+}
+
+// queryChildren 返回 n 的子节点，文档的子节点为各画布的根节点，分离的节点排在其他子节点之后
+func queryChildren(sheets []xmind.Sheet, n queryNode) []queryNode {
+	var children []queryNode
+	if n.topic == nil {
+		for i := range sheets {
+			children = append(children, queryNode{sheet: i, topic: &sheets[i].RootTopic})
+		}
+		return children
+	}
+	path := append(append([]*xmind.Topic{}, n.path...), n.topic)
+	for _, c := range topicChildren(n.topic) {
+		children = append(children, queryNode{sheet: n.sheet, path: path, topic: c})
+	}
+	return children
+}
+
+// queryDescendants 按先序返回 n 的所有后代节点（不含 n）
+func queryDescendants(sheets []xmind.Sheet, n queryNode) []queryNode {
+	var nodes []queryNode
+	for _, c := range queryChildren(sheets, n) {
+		nodes = append(nodes, c)
+		nodes = append(nodes, queryDescendants(sheets, c)...)
+	}
+	return nodes
+}
+
+// filter 返回 nodes 中满足条件的节点，位置条件选出其中的第 index 个
+func (pred queryPred) filter(nodes []queryNode) []queryNode {
+	if pred.index > 0 {
+		if pred.index > len(nodes) {
+			return nil
+		}
+		return nodes[pred.index-1 : pred.index]
+	}
+	var out []queryNode
+	for _, n := range nodes {
+		for _, all := range pred.any {
+			ok := true
+			for _, c := range all {
+				if !c.match(n) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				out = append(out, n)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// match 判断节点是否满足比较，label 与 marker 有多个值时任意一个满足即可，!= 要求所有值都不相等
+func (c queryCond) match(n queryNode) bool {
+	values := queryValues(n, c.field)
+	if oneOf(c.field, queryNumeric) {
+		v, _ := strconv.Atoi(values[0])
+		switch c.op {
+		case "":
+			return v > 0
+		case "=":
+			return v == c.num
+		case "!=":
+			return v != c.num
+		case "<":
+			return v < c.num
+		case "<=":
+			return v <= c.num
+		case ">":
+			return v > c.num
+		}
+		return v >= c.num
+	}
+	if c.op == "!=" {
+		for _, v := range values {
+			if c.equal(v) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		switch {
+		case c.op == "" && v != "",
+			c.op == "=" && c.equal(v),
+			c.op == "~=" && c.re.MatchString(v),
+			c.op == "*=" && strings.Contains(v, c.value):
+			return true
+		}
+	}
+	return false
+}
+
+// equal 比较字段的值，图标与 -filter-marker 相同，flag 匹配所有 flag- 开头的图标
+func (c queryCond) equal(v string) bool {
+	if c.field == "marker" {
+		return v == c.value || strings.HasPrefix(v, c.value+"-")
+	}
+	return v == c.value
+}
+
+// queryValues 返回节点字段的值，标题与标签中连续的空白合并为一个空格，label 与 marker 每个值一项
+func queryValues(n queryNode, field string) []string {
+	t := n.topic
+	switch field {
+	case "title":
+		return []string{strings.Join(strings.Fields(t.Title), " ")}
+	case "notes":
+		if t.Notes != nil && t.Notes.Plain != nil {
+			return []string{strings.TrimSpace(t.Notes.Plain.Content)}
+		}
+	case "id":
+		return []string{t.ID}
+	case "href":
+		return []string{t.Href}
+	case "label":
+		var labels []string
+		for _, l := range t.Labels {
+			labels = append(labels, strings.Join(strings.Fields(l), " "))
+		}
+		return labels
+	case "marker":
+		var markers []string
+		for _, m := range t.Markers {
+			markers = append(markers, m.MarkerID)
+		}
+		return markers
+	case "depth":
+		return []string{strconv.Itoa(len(n.path) + 1)}
+	case "children":
+		return []string{strconv.Itoa(len(topicChildren(t)))}
+	case "path":
+		var trail []string
+		for _, p := range n.path {
+			trail = append(trail, trailTitle(p.Title))
+		}
+		return []string{strings.Join(append(trail, trailTitle(t.Title)), " > ")}
+	}
+	return nil
+}
+
+// printQueryResults 表达式以字段结尾时每个值输出一行，否则每个节点输出一行位置；
+// withFile 为 true 时（查询多个文件）在每行前加上文件名
+func printQueryResults(w io.Writer, results []queryResult, withFile bool) {
+	for _, r := range results {
+		if withFile {
+			fmt.Fprintf(w, "%s: ", r.File)
+		}
+		if r.Field != "" {
+			fmt.Fprintln(w, r.Value)
+			continue
+		}
+		fmt.Fprintln(w, strings.Join(r.Path, " > "))
+	}
+}
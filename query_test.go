@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// querySheets 为查询测试使用的思维导图：
+//
+//	Project
+//	  Design [ui] (priority-1)
+//	    Wireframe -> https://example.com/wire
+//	    Review （备注 check）
+//	  Build
+//	    API (flag-red)
+//	    Review
+//	  Ideas（分离的节点）
+func querySheets() []xmind.Sheet {
+	children := func(ts ...xmind.Topic) *xmind.Children { return &xmind.Children{Attached: ts} }
+	return []xmind.Sheet{{ID: "s1", Title: "Plan", RootTopic: xmind.Topic{ID: "r", Title: "Project",
+		Children: children(
+			xmind.Topic{ID: "d", Title: "Design", Labels: []string{"ui"}, Markers: []xmind.Marker{{MarkerID: "priority-1"}},
+				Children: children(
+					xmind.Topic{ID: "w", Title: "Wireframe", Href: "https://example.com/wire"},
+					xmind.Topic{ID: "dr", Title: "Review", Notes: &xmind.Notes{Plain: &xmind.NotesContent{Content: " check\n"}}},
+				)},
+			xmind.Topic{ID: "b", Title: "Build", Children: children(
+				xmind.Topic{ID: "a", Title: "API", Markers: []xmind.Marker{{MarkerID: "flag-red"}}},
+				xmind.Topic{ID: "br", Title: "Review"},
+			)},
+		),
+		Detached: []xmind.Topic{{ID: "i", Title: "Ideas"}},
+	}}}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		expr string
+		// want 为每个结果的位置，表达式以字段结尾时为字段的值
+		want []string
+	}{
+		{"/topic", []string{"Plan > Project"}},
+		{"/*/*", []string{"Plan > Project > Design", "Plan > Project > Build", "Plan > Project > Ideas"}},
+		{`//topic[title="Review"]`, []string{"Plan > Project > Design > Review", "Plan > Project > Build > Review"}},
+		// 与 XPath 相同，位置条件按每个上级节点分别计算，结果按先序排列
+		{"//topic[2]", []string{"Plan > Project > Design > Review", "Plan > Project > Build", "Plan > Project > Build > Review"}},
+		{`//topic[depth=3 and title*='view']`, []string{"Plan > Project > Design > Review", "Plan > Project > Build > Review"}},
+		{`//topic[marker="flag"]`, []string{"Plan > Project > Build > API"}},
+		{"//*[children>1]", []string{"Plan > Project", "Plan > Project > Design", "Plan > Project > Build"}},
+		{"//topic[labels or href]", []string{"Plan > Project > Design", "Plan > Project > Design > Wireframe"}},
+		{`//topic[title!="Review"][children=0]`, []string{"Plan > Project > Design > Wireframe", "Plan > Project > Build > API", "Plan > Project > Ideas"}},
+		{`//topic[path="Project > Build"]/topic[1]`, []string{"Plan > Project > Build > API"}},
+		{` / topic [ title = 'Project' ] / topic [ 1 ] `, []string{"Plan > Project > Design"}},
+		{`//topic[title~="^W"]/href`, []string{"https://example.com/wire"}},
+		{"//topic[notes]/notes", []string{"check"}},
+		{"/topic/topic/markers", []string{"priority-1"}},
+		{`//topic[title="Missing"]`, nil},
+	}
+	for _, tt := range tests {
+		q, err := parseQuery(tt.expr)
+		if err != nil {
+			t.Errorf("parseQuery(%q) 出错: %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, r := range q.run("plan.xmind", querySheets()) {
+			if q.field != "" {
+				got = append(got, r.Value)
+			} else {
+				got = append(got, strings.Join(r.Path, " > "))
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("查询 %q 得到 %q，应为 %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	if err := i18n.SetLanguage("zh"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "无效的查询: 表达式为空"},
+		{"   ", "无效的查询: 表达式为空"},
+		{"topic", "无效的查询: 第 1 个字符处应为 /"},
+		{"//topic[", "无效的查询: 表达式在应为 字段名 的地方结束"},
+		{"//topic[0]", "无效的查询: 第 10 个字符处应为 ]"},
+		{`//topic[title="a"`, "无效的查询: 表达式在应为 ] 的地方结束"},
+		{`//topic[title="a]`, `无效的查询: 表达式在应为 " 的地方结束`},
+		{`//topic[title="a" xor]`, "无效的查询: 第 19 个字符处应为 ]"},
+		{"//topic[foo]", "无效的查询: 第 9 个字符处应为 字段名"},
+		{"//topic[标题]", "无效的查询: 第 9 个字符处应为 字段名"},
+		{"//topic[title=Review]", "无效的查询: 第 15 个字符处应为 引号括起的字符串"},
+		{"//topic[depth>x]", "无效的查询: 第 15 个字符处应为 数字"},
+		{`//topic[depth~="1"]`, "无效的查询: depth 只能按数字比较"},
+		{"//topic[title>1]", "无效的查询: title 不能按数字比较"},
+		{"//title", "无效的查询: 第 3 个字符处应为 topic"},
+		{"/title", "无效的查询: 第 2 个字符处应为 topic"},
+		{"/topic/title/topic", "无效的查询: 字段 title 只能是表达式的最后一步"},
+		{`//topic[title~="("]`, `无效的查询: 无效的正则表达式 "(": `},
+	}
+	for _, tt := range tests {
+		_, err := parseQuery(tt.expr)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("parseQuery(%q) 返回 %v，应为 %q", tt.expr, err, tt.want)
+		}
+	}
+}
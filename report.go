@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// fileReport 为一个输入的转换结果
+type fileReport struct {
+	Input  string `json:"input"`
+	Output string `json:"output,omitempty"`
+	// Outputs 为指定 -sheet-regex 时每个画布的输出文件，Output 为其中的第一个
+	Outputs []string `json:"outputs,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	// Title 为第一个画布根节点的标题，用于 -index 生成的目录
+	Title string `json:"title,omitempty"`
+	// Sheets 为画布数，Topics 为所有画布的节点总数
+	Sheets int `json:"sheets"`
+	Topics int `json:"topics"`
+	// Warnings 为转换时丢失的内容，如未输出的备注与图片
+	Warnings   []string `json:"warnings,omitempty"`
+	DurationMs int64    `json:"durationMs"`
+	// Action 为 -dry-run 时将要进行的写入操作，见 outputAction，输入没有变化时为 skip
+	Action string `json:"action,omitempty"`
+	// Skipped 表示输入没有变化，没有重新转换
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// outputs 返回生成的所有输出文件，没有写入文件时（如只预览）为空
+func (f fileReport) outputs() []string {
+	if len(f.Outputs) > 0 {
+		return f.Outputs
+	}
+	if f.Output == "" {
+		return nil
+	}
+	return []string{f.Output}
+}
+
+// actionNames 为写入操作在表格中显示的名称
+var actionNames = map[string]string{
+	"create":    "新建",
+	"overwrite": "覆盖",
+	"upload":    "上传",
+	"stdout":    "标准输出",
+	"skip":      "跳过",
+}
+
+// printDryRun 以表格形式列出 -dry-run 时将要写入的文件
+func printDryRun(w io.Writer, r *runReport) {
+	var rows [][]string
+	counts := map[string]int{}
+	for _, f := range r.Files {
+		if f.Error != "" {
+			rows = append(rows, []string{"✗", f.Input, f.Error, "", ""})
+			continue
+		}
+		counts[f.Action]++
+		rows = append(rows, []string{i18n.T(actionNames[f.Action]), f.Input, f.Output, strconv.Itoa(f.Sheets), strconv.Itoa(f.Topics)})
+	}
+	printTable(w, []string{"操作", "输入", "输出 / 错误", "画布", "节点"}, rows)
+	fmt.Fprintf(w, i18n.T("\n共 %d 个文件，将新建 %d 个，覆盖 %d 个，跳过 %d 个，失败 %d 个（未写入任何文件）\n"),
+		r.Total, counts["create"], counts["overwrite"], counts["skip"], r.Failed)
+}
+
+// runReport 为 -report=json 输出的转换报告
+type runReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Total      int       `json:"total"`
+	Succeeded  int       `json:"succeeded"`
+	Failed     int       `json:"failed"`
+	// Skipped 为输入没有变化而跳过的文件数，计入 Succeeded
+	Skipped int          `json:"skipped"`
+	Sheets  int          `json:"sheets"`
+	Topics  int          `json:"topics"`
+	Files   []fileReport `json:"files"`
+	// Error 为转换失败时的错误信息
+	Error string `json:"error,omitempty"`
+}
+
+// newFileReport 统计解析得到的画布与节点数以及转换时会丢失的内容
+func newFileReport(in string, sheets []xmind.Sheet, opts convertOptions) fileReport {
+	rep := fileReport{Input: in, Sheets: len(sheets)}
+	if len(sheets) > 0 {
+		rep.Title = strings.Join(strings.Fields(sheets[0].RootTopic.Title), " ")
+	}
+	for _, s := range sheets {
+		rep.Topics += s.TopicCount()
+	}
+	rep.Warnings = render.Warnings(opts.To, sheets, opts.Write)
+	return rep
+}
+
+// add 记录一个输入的转换结果
+func (r *runReport) add(f fileReport, err error) {
+	if err != nil {
+		f.Error = briefError(err)
+		r.Failed++
+	} else {
+		r.Succeeded++
+	}
+	if f.Skipped {
+		r.Skipped++
+	}
+	r.Total++
+	r.Sheets += f.Sheets
+	r.Topics += f.Topics
+	r.Files = append(r.Files, f)
+}
+
+// wroteStdout 判断是否有转换结果输出到了标准输出，如从管道读取时
+func (r *runReport) wroteStdout() bool {
+	for _, f := range r.Files {
+		if f.Output == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// reportToStdout 判断报告是否输出到标准输出，此时不再输出给人看的提示与汇总表格
+func (o convertOptions) reportToStdout() bool {
+	return o.Report == "json" && o.ReportFile == "" && o.Output != "-"
+}
+
+// writeReport 按 -report 与 -report-file 输出转换报告
+// 转换结果输出到标准输出时报告输出到标准错误
+func writeReport(r *runReport, opts convertOptions) error {
+	r.DurationMs = time.Since(r.StartedAt).Milliseconds()
+	if r.Files == nil {
+		r.Files = []fileReport{}
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	var w io.Writer = os.Stdout
+	switch {
+	case opts.ReportFile != "":
+		if err := os.WriteFile(opts.ReportFile, data, 0o644); err != nil {
+			return withCode(exitWrite, i18n.Errorf("写入报告失败: %v", err))
+		}
+		return nil
+	case opts.Output == "-" || r.wroteStdout():
+		w = os.Stderr
+	}
+	_, err = w.Write(data)
+	return withCode(exitWrite, err)
+}
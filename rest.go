@@ -0,0 +1,333 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// openAPISpec 为 HTTP 接口的 OpenAPI 文档，由 GET /openapi.json 返回
+//
+//go:embed api/openapi.json
+var openAPISpec []byte
+
+// contentTypes 为各输出格式扩展名对应的 Content-Type
+var contentTypes = map[string]string{
+	".md":    "text/markdown; charset=utf-8",
+	".txt":   "text/plain; charset=utf-8",
+	".xmind": "application/vnd.xmind.workbook",
+	".x2m":   "application/json",
+}
+
+// restHandler 实现 serve --http 提供的 HTTP 接口，接口说明见 api/openapi.json
+type restHandler struct {
+	*service
+}
+
+func (h restHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var err error
+	switch {
+	case r.URL.Path == "/convert" && r.Method == http.MethodPost:
+		c := h.metrics.begin("http", "convert")
+		err = h.convert(w, r, c)
+		c.done(err)
+	case r.URL.Path == "/inspect" && r.Method == http.MethodPost:
+		c := h.metrics.begin("http", "inspect")
+		err = h.inspect(w, r, c)
+		c.done(err)
+	case r.URL.Path == "/openapi.json" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPISpec)
+	case (r.URL.Path == "/metrics" || r.URL.Path == "/healthz") && r.Method == http.MethodGet:
+		metricsHandler{h.metrics}.ServeHTTP(w, r)
+	case r.URL.Path == "/convert" || r.URL.Path == "/inspect":
+		w.Header().Set("Allow", http.MethodPost)
+		writeRESTError(w, http.StatusMethodNotAllowed, withCode(exitUsage, i18n.Errorf("只支持 POST 请求")))
+	default:
+		writeRESTError(w, http.StatusNotFound, withCode(exitUsage, i18n.Errorf("未知的接口: %s", r.URL.Path)))
+	}
+	if err != nil {
+		logf(levelVerbose, "HTTP %s 失败: %v", r.URL.Path, err)
+		writeRESTError(w, restStatus(err), err)
+	}
+}
+
+// convert 实现 POST /convert，输出拆分为多个文件时返回包含所有文件的 zip
+func (h restHandler) convert(w http.ResponseWriter, r *http.Request, c *conversion) error {
+	ctx, cancel := h.requestContext(r.Context())
+	defer cancel()
+	req, name, err := h.request(w, r)
+	defer req.close()
+	if err != nil {
+		return err
+	}
+	c.inSize = req.Size
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	sheets, _, err := h.parse(ctx, &req)
+	if err != nil {
+		return withCode(exitParse, err)
+	}
+	ext, _ := render.OutputExt(req.Format)
+	opts := convertOptions{To: req.Format, Write: req.Write}
+	q := r.URL.Query()
+	if opts.ChunkLevel, err = queryInt(q.Get("chunkLevel")); err != nil {
+		return err
+	}
+	size, err := queryInt(q.Get("maxFileSize"))
+	if err != nil {
+		return err
+	}
+	opts.MaxFileSize = int64(size)
+	if opts.ChunkLevel < 0 || opts.MaxFileSize < 0 {
+		return withCode(exitUsage, i18n.Errorf("-chunk-level 与 -max-file-size 不能为负数"))
+	}
+	if opts.ChunkLevel == 1 {
+		return withCode(exitUsage, i18n.Errorf("-chunk-level 至少为 2，根节点所在的第 1 层不能拆分"))
+	}
+
+	if opts.chunked() {
+		paths, parts, err := chunkParts(ctx, name+ext, sheets, opts)
+		if err != nil {
+			return err
+		}
+		if len(parts) > 1 {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			now := time.Now()
+			for i, part := range parts {
+				f, err := zw.CreateHeader(&zip.FileHeader{Name: paths[i], Method: zip.Deflate, Modified: now})
+				if err != nil {
+					return err
+				}
+				if _, err := io.WriteString(f, part); err != nil {
+					return err
+				}
+			}
+			if err := zw.Close(); err != nil {
+				return err
+			}
+			c.outSize = int64(buf.Len())
+			writeDownload(w, "application/zip", name+".zip", buf.Bytes())
+			return nil
+		}
+	}
+	var buf bytes.Buffer
+	if err := render.WriteAsContext(ctx, req.Format, &buf, sheets, req.Write); err != nil {
+		return err
+	}
+	c.outSize = int64(buf.Len())
+	ct := contentTypes[ext]
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	writeDownload(w, ct, name+ext, buf.Bytes())
+	return nil
+}
+
+// inspectSheet 为 POST /inspect 返回的一个画布的概况
+type inspectSheet struct {
+	ID         string `json:"id,omitempty"`
+	Title      string `json:"title"`
+	RootTitle  string `json:"rootTitle"`
+	TopicCount int    `json:"topicCount"`
+	MaxDepth   int    `json:"maxDepth"`
+}
+
+// inspect 实现 POST /inspect
+func (h restHandler) inspect(w http.ResponseWriter, r *http.Request, c *conversion) error {
+	ctx, cancel := h.requestContext(r.Context())
+	defer cancel()
+	req, _, err := h.request(w, r)
+	defer req.close()
+	if err != nil {
+		return err
+	}
+	c.inSize = req.Size
+	release, err := h.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	sheets, format, err := h.parse(ctx, &req)
+	if err != nil {
+		return withCode(exitParse, err)
+	}
+	resp := struct {
+		Format string         `json:"format"`
+		Sheets []inspectSheet `json:"sheets"`
+	}{Format: format, Sheets: []inspectSheet{}}
+	for i := range sheets {
+		resp.Sheets = append(resp.Sheets, inspectSheet{
+			ID:         sheets[i].ID,
+			Title:      sheets[i].Title,
+			RootTitle:  sheets[i].RootTopic.Title,
+			TopicCount: sheets[i].TopicCount(),
+			MaxDepth:   sheetDepth(&sheets[i]),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// request 读取上传的文件与查询参数，返回请求与输出文件名（不含扩展名），出错时同样需要调用 req.close
+// 文件可以直接作为请求体上传，也可以作为 multipart/form-data 中名为 file 的字段上传
+// 表单按顺序流式读取，不使用 r.FormFile，使上传内容只写入请求独占的临时目录（见 service.spool）
+func (h restHandler) request(w http.ResponseWriter, r *http.Request) (serveRequest, string, error) {
+	q := r.URL.Query()
+	req := serveRequest{From: q.Get("from"), Format: q.Get("format")}
+	name := q.Get("name")
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxSize)
+	var src io.Reader = r.Body
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return req, "", restUploadError(err)
+		}
+		for src = nil; src == nil; {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return req, "", withCode(exitUsage, i18n.Errorf("请求中没有上传文件"))
+			}
+			if err != nil {
+				return req, "", restUploadError(err)
+			}
+			if part.FormName() != "file" {
+				continue
+			}
+			if fn := part.FileName(); name == "" && fn != "" {
+				name = strings.TrimSuffix(path.Base(fn), path.Ext(fn))
+			}
+			src = part
+		}
+	}
+	if err := h.spool(src, &req); err != nil {
+		return req, "", restUploadError(err)
+	}
+	if req.Size == 0 {
+		return req, "", withCode(exitUsage, i18n.Errorf("请求中没有上传文件"))
+	}
+	// 文件名只用于 Content-Disposition 与 zip 中的文件名，不能包含目录
+	if name = safeName(path.Base(strings.ReplaceAll(name, `\`, "/"))); name == "" || name == "." || name == ".." || name == "/" {
+		name = "output"
+	}
+
+	var err error
+	if req.Write.HeadingStart, err = queryInt(q.Get("headingStart")); err != nil {
+		return req, "", err
+	}
+	if req.Write.MaxDepth, err = queryInt(q.Get("maxDepth")); err != nil {
+		return req, "", err
+	}
+	if v := q.Get("notes"); v != "" {
+		if req.Write.Notes, err = strconv.ParseBool(v); err != nil {
+			return req, "", withCode(exitUsage, i18n.Errorf("无效的参数值: %s", v))
+		}
+	}
+	req.Write.LeafStyle = q.Get("leafStyle")
+	for _, m := range q["marker"] {
+		k, v, ok := strings.Cut(m, "=")
+		if !ok || k == "" {
+			return req, "", withCode(exitUsage, i18n.Errorf("无效的参数值: %s", m))
+		}
+		if req.Write.Markers == nil {
+			req.Write.Markers = map[string]string{}
+		}
+		req.Write.Markers[k] = v
+	}
+	if err := req.check(); err != nil {
+		return req, "", withCode(exitUsage, err)
+	}
+	return req, name, nil
+}
+
+// queryInt 解析整数类型的查询参数，为空时为 0
+func queryInt(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, withCode(exitUsage, i18n.Errorf("无效的参数值: %s", v))
+	}
+	return n, nil
+}
+
+// restUploadError 包装读取上传内容时的错误，超过大小限制时返回 413
+func restUploadError(err error) error {
+	var mbe *http.MaxBytesError
+	if errors.As(err, &mbe) {
+		return &restError{status: http.StatusRequestEntityTooLarge, err: withCode(exitUsage, i18n.Errorf("请求超过 %d 字节", mbe.Limit))}
+	}
+	return withCode(exitUsage, i18n.Errorf("读取请求失败: %v", err))
+}
+
+// restError 为指定了 HTTP 状态码的错误
+type restError struct {
+	status int
+	err    error
+}
+
+func (e *restError) Error() string { return e.err.Error() }
+
+func (e *restError) Unwrap() error { return e.err }
+
+// restStatus 返回错误对应的 HTTP 状态码：参数错误为 400，无法解析的文件为 422，超过解析限制为 413，
+// 服务繁忙或超过 -request-timeout 为 503，其他错误为 500
+func restStatus(err error) int {
+	var re *restError
+	if errors.As(err, &re) {
+		return re.status
+	}
+	if errors.Is(err, xmind.ErrTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, errBusy) || errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusServiceUnavailable
+	}
+	switch exitCode(err) {
+	case exitUsage:
+		return http.StatusBadRequest
+	case exitNotZip, exitNoContent, exitParse, exitEncrypted:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// writeRESTError 以 JSON 格式返回错误，内容与 -error-format=json 的错误对象相同
+func writeRESTError(w http.ResponseWriter, status int, err error) {
+	code := exitCode(err)
+	data, _ := json.Marshal(errorRecord{Code: code, Kind: exitKinds[code], Message: errorMessage(err)})
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if errors.Is(err, errBusy) {
+		w.Header().Set("Retry-After", "1")
+	}
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+// writeDownload 返回文件内容，filename 为建议的文件名
+func writeDownload(w http.ResponseWriter, contentType, filename string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
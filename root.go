@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// selectRoot 按 opts.Root 或 opts.RootID 选出一个节点，返回只有一个画布、以该节点为根节点的 sheets，
+// 都为空时原样返回；选中的节点所在画布的 ID 与标题保持不变
+func selectRoot(sheets []xmind.Sheet, opts convertOptions) ([]xmind.Sheet, error) {
+	var t *xmind.Topic
+	var i int
+	switch {
+	case opts.RootID != "":
+		if t, i = findTopicID(sheets, opts.RootID); t == nil {
+			return nil, withCode(exitUsage, i18n.Errorf("没有找到 ID 为 %s 的节点", opts.RootID))
+		}
+	case opts.Root != "":
+		if t, i = findTopic(sheets, opts.Root); t == nil {
+			return nil, withCode(exitUsage, i18n.Errorf("没有找到节点: %s", opts.Root))
+		}
+	default:
+		return sheets, nil
+	}
+	s := sheets[i]
+	return []xmind.Sheet{{ID: s.ID, Class: s.Class, Title: s.Title, RootTopic: *t}}, nil
+}
+
+// findTopic 查找标题为 sel 的第一个节点，sel 包含 / 时为从根节点的子节点开始的标题路径，
+// 返回节点与所在画布的序号，没有找到时返回 nil
+func findTopic(sheets []xmind.Sheet, sel string) (*xmind.Topic, int) {
+	parts := strings.Split(strings.Trim(sel, "/"), "/")
+	for i := range sheets {
+		var found *xmind.Topic
+		sheets[i].Walk(func(path []*xmind.Topic, t *xmind.Topic) error {
+			if found != nil {
+				return xmind.SkipChildren
+			}
+			if len(parts) == 1 {
+				if strings.TrimSpace(t.Title) == strings.TrimSpace(sel) {
+					found = t
+				}
+				return nil
+			}
+			// path 中第一个为根节点，标题路径从根节点的子节点开始
+			if len(path) == len(parts) && strings.TrimSpace(t.Title) == strings.TrimSpace(parts[len(parts)-1]) {
+				for j, p := range path[1:] {
+					if strings.TrimSpace(p.Title) != strings.TrimSpace(parts[j]) {
+						return nil
+					}
+				}
+				found = t
+			}
+			return nil
+		})
+		if found != nil {
+			return found, i
+		}
+	}
+	return nil, -1
+}
+
+// findTopicID 查找 ID 为 id 的节点，返回节点与所在画布的序号，没有找到时返回 nil
+func findTopicID(sheets []xmind.Sheet, id string) (*xmind.Topic, int) {
+	for i := range sheets {
+		var found *xmind.Topic
+		sheets[i].Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+			if found != nil {
+				return xmind.SkipChildren
+			}
+			if t.ID == id {
+				found = t
+			}
+			return nil
+		})
+		if found != nil {
+			return found, i
+		}
+	}
+	return nil, -1
+}
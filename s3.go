@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// s3Object 表示 s3://bucket/key 形式的对象地址
+type s3Object struct {
+	bucket string
+	key    string
+}
+
+// isS3 判断路径是否为 s3:// 地址
+func isS3(p string) bool {
+	return strings.HasPrefix(strings.ToLower(p), "s3://")
+}
+
+// parseS3 解析 s3://bucket/key 地址
+func parseS3(p string) (s3Object, error) {
+	rest := p[len("s3://"):]
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return s3Object{}, i18n.Errorf("无效的 S3 地址: %s，格式应为 s3://bucket/key", p)
+	}
+	return s3Object{bucket: bucket, key: key}, nil
+}
+
+func (o s3Object) String() string {
+	return "s3://" + o.bucket + "/" + o.key
+}
+
+// fetch 下载对象内容，返回的文件名为对象地址本身，使输出文件写回同一位置
+func (o s3Object) fetch(ctx context.Context, opts fetchOptions) (string, []byte, error) {
+	req, err := o.request(ctx, http.MethodGet, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := download(req, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return o.String(), data, nil
+}
+
+// put 上传对象内容，ctx 被取消时中止上传
+func (o s3Object) put(ctx context.Context, data []byte, timeout time.Duration) error {
+	req, err := o.request(ctx, http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return i18n.Errorf("上传到 S3 失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return i18n.Errorf("上传到 S3 失败: %s %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// request 构造并签名访问对象的请求
+// 凭证与区域读取 AWS 标准环境变量，设置 AWS_ENDPOINT_URL(_S3) 时使用兼容 S3 的服务并采用路径形式的地址
+func (o s3Object) request(ctx context.Context, method string, body []byte) (*http.Request, error) {
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, i18n.Errorf("访问 S3 需要设置 AWS_ACCESS_KEY_ID 与 AWS_SECRET_ACCESS_KEY 环境变量")
+	}
+	region := firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	var u *url.URL
+	if endpoint := firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"); endpoint != "" {
+		base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+		if err != nil {
+			return nil, i18n.Errorf("无效的 S3 服务地址: %v", err)
+		}
+		u = base
+		u.Path = base.Path + "/" + o.bucket + "/" + o.key
+	} else {
+		u = &url.URL{Scheme: "https", Host: o.bucket + ".s3." + region + ".amazonaws.com", Path: "/" + o.key}
+	}
+	// 请求中使用与签名相同的路径编码
+	u.RawPath = s3EscapePath(u.Path)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, i18n.Errorf("无效的 S3 地址: %v", err)
+	}
+	signV4(req, body, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), region, time.Now().UTC())
+	return req, nil
+}
+
+// signV4 按 AWS Signature Version 4 为请求签名
+func signV4(req *http.Request, body []byte, accessKey, secretKey, token, region string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lower := strings.ToLower(k)
+		if strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// s3EscapePath 按 SigV4 规则编码路径，除未保留字符与 / 外全部编码
+func s3EscapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// firstEnv 返回第一个非空的环境变量值
+func firstEnv(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var searchCommand = &command{
+	Name:  "search",
+	Args:  "[参数] <模式> <文件或目录>...",
+	Short: "在多个思维导图中查找标题与正则表达式匹配的节点，输出其所在的文件与位置",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var filter fileFilter
+		var ignoreCase, fixed, notes, asJSON bool
+		fs.Var(&filter.Include, "include-files", "查找目录时只查找与模式匹配的文件（如 \"**/*.xmind\"），可重复指定")
+		fs.Var(&filter.Exclude, "exclude-files", "查找目录时跳过与模式匹配的文件或目录（如 \"archive/**\"），可重复指定")
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.BoolVar(&ignoreCase, "i", false, "忽略大小写")
+		fs.BoolVar(&fixed, "fixed", false, "将模式作为普通文本而不是正则表达式")
+		fs.BoolVar(&notes, "notes", false, "同时查找节点备注")
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出匹配的节点")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) < 2 {
+				return withCode(exitUsage, i18n.Errorf("search 需要指定模式与至少一个文件或目录"))
+			}
+			pattern := args[0]
+			if fixed {
+				pattern = regexp.QuoteMeta(pattern)
+			}
+			if ignoreCase {
+				pattern = "(?i)" + pattern
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return withCode(exitUsage, i18n.Errorf("无效的正则表达式 %q: %v", args[0], err))
+			}
+			inputs, _, err := expandInputs(args[1:], filter)
+			if err != nil {
+				return err
+			}
+
+			matches := []searchMatch{}
+			failed := 0
+			for _, in := range inputs {
+				if err := ctx.Err(); err != nil {
+					return errInterrupted()
+				}
+				sheets, _, err := readInputSheets(ctx, in.Path, opts)
+				if err != nil {
+					// 无法解析的文件不影响在其他文件中查找
+					failed++
+					warnf(levelNormal, "%s: %v", in.Path, briefError(err), slog.String("input", in.Path))
+					continue
+				}
+				found := searchSheets(in.Path, sheets, re, notes)
+				if !asJSON {
+					printMatches(os.Stdout, found)
+				}
+				matches = append(matches, found...)
+			}
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(matches); err != nil {
+					return withCode(exitWrite, err)
+				}
+			}
+			logf(levelVerbose, "在 %d 个文件中找到 %d 个匹配的节点", len(inputs)-failed, len(matches))
+			if failed > 0 {
+				return withCode(exitPartial, i18n.Errorf("%d 个文件无法读取", failed))
+			}
+			return nil
+		}
+	},
+}
+
+// searchMatch 为 search 找到的一个节点，Field 为匹配的内容：title 或 notes，Text 为匹配的那一行
+type searchMatch struct {
+	File string `json:"file"`
+	// Path 为节点的位置，依次为画布（有标题时）与各级节点的标题
+	Path    []string `json:"path"`
+	TopicID string   `json:"topicId,omitempty"`
+	Field   string   `json:"field"`
+	Text    string   `json:"text"`
+}
+
+// searchSheets 返回 sheets 中标题（notes 为 true 时还有备注）与 re 匹配的节点，同一个节点只返回一次，标题优先
+func searchSheets(file string, sheets []xmind.Sheet, re *regexp.Regexp, notes bool) []searchMatch {
+	var matches []searchMatch
+	for i := range sheets {
+		s := &sheets[i]
+		s.Walk(func(path []*xmind.Topic, t *xmind.Topic) error {
+			field, text := "title", matchLine(re, t.Title)
+			if text == "" && notes && t.Notes != nil && t.Notes.Plain != nil {
+				field, text = "notes", matchLine(re, t.Notes.Plain.Content)
+			}
+			if text != "" {
+				matches = append(matches, searchMatch{File: file, Path: topicTrail(s, path, t), TopicID: t.ID, Field: field, Text: text})
+			}
+			return nil
+		})
+	}
+	return matches
+}
+
+// matchLine 返回 s 中第一个与 re 匹配的行（去掉首尾空白），没有匹配时返回空字符串
+func matchLine(re *regexp.Regexp, s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && re.MatchString(line) {
+			return line
+		}
+	}
+	return ""
+}
+
+// printMatches 每个节点输出一行：文件: 位置，备注中的匹配在后面附上匹配的那一行
+func printMatches(w io.Writer, matches []searchMatch) {
+	for _, m := range matches {
+		if m.Field == "notes" {
+			fmt.Fprintf(w, i18n.T("%s: %s（备注: %s）\n"), m.File, strings.Join(m.Path, " > "), m.Text)
+			continue
+		}
+		fmt.Fprintf(w, "%s: %s\n", m.File, strings.Join(m.Path, " > "))
+	}
+}
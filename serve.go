@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/batch"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var serveCommand = &command{
+	Name:  "serve",
+	Args:  "[参数]",
+	Short: "以服务方式提供转换接口（HTTP、gRPC、MCP）",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var s server
+		fs.StringVar(&s.HTTPAddr, "http", "", "HTTP 接口的监听地址，如 :8080，接口说明见 GET /openapi.json")
+		fs.StringVar(&s.GRPCAddr, "grpc", "", "gRPC 服务的监听地址，如 :50051，接口定义见 proto/xmindtomarkdown/v1/converter.proto")
+		fs.StringVar(&s.MetricsAddr, "metrics", "", "单独提供 GET /metrics（Prometheus 格式）与 GET /healthz 的监听地址，如 :9090，HTTP 接口本身也提供这两个路径")
+		fs.BoolVar(&s.MCP, "mcp", false, "在标准输入输出上提供 MCP（Model Context Protocol）服务，供 AI 助手读取思维导图")
+		fs.Int64Var(&s.MaxRequestSize, "max-request-size", 32<<20, "每个请求允许上传的最大字节数")
+		fs.IntVar(&s.MaxConcurrent, "max-concurrent", runtime.NumCPU(), "同时处理的最大转换请求数，其余请求排队等待，等待超过 -request-timeout 时返回服务繁忙")
+		fs.DurationVar(&s.RequestTimeout, "request-timeout", 30*time.Second, "每个请求从开始读取到完成转换的最长时间（包括上传与排队），0 表示不限制")
+		var limits xmind.Limits
+		defineLimitFlags(fs, &limits)
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) > 0 {
+				return withCode(exitUsage, i18n.Errorf("serve 不接受位置参数: %s", strings.Join(args, " ")))
+			}
+			if s.HTTPAddr == "" && s.GRPCAddr == "" && !s.MCP {
+				return withCode(exitUsage, i18n.Errorf("必须指定 -http、-grpc 或 -mcp"))
+			}
+			if s.MaxRequestSize <= 0 || s.MaxConcurrent <= 0 || s.RequestTimeout < 0 {
+				return withCode(exitUsage, i18n.Errorf("-max-request-size 与 -max-concurrent 必须大于 0，-request-timeout 不能为负数"))
+			}
+			if err := applyLimits(limits); err != nil {
+				return err
+			}
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+			// 容器与服务管理器用 SIGTERM 停止服务，与 Ctrl+C 一样等待进行中的请求完成并删除临时文件
+			ctx, stopTerm := signal.NotifyContext(ctx, syscall.SIGTERM)
+			defer stopTerm()
+			return s.run(ctx)
+		}
+	},
+}
+
+// shutdownTimeout 为收到中断信号后等待进行中的请求完成的时间
+const shutdownTimeout = 10 * time.Second
+
+// server 为 serve 命令启动的服务
+type server struct {
+	// HTTPAddr 为 HTTP 接口的监听地址，GRPCAddr 为 gRPC 服务的监听地址，为空时不启动
+	HTTPAddr string
+	GRPCAddr string
+	// MetricsAddr 为单独提供监控指标与健康检查的监听地址，为空时不启动
+	MetricsAddr string
+	// MCP 表示在标准输入输出上提供 MCP 服务，标准输入结束时所有服务一起退出
+	MCP bool
+	// MaxRequestSize 为每个请求允许上传的最大字节数
+	MaxRequestSize int64
+	// MaxConcurrent 为同时处理的最大转换请求数
+	MaxConcurrent int
+	// RequestTimeout 为每个请求的最长处理时间，为 0 时不限制
+	RequestTimeout time.Duration
+}
+
+// run 启动所有服务，直到 ctx 取消（如收到中断信号）或某个服务出错
+func (s *server) run(ctx context.Context) error {
+	var srvs []*http.Server
+	errc := make(chan error, 4)
+	metrics := newMetricsRegistry()
+	// 较大的上传内容写入临时文件，所有请求的临时文件都位于本次运行独占的目录中，退出时一起删除
+	tempDir, err := os.MkdirTemp("", "xmind2md-serve-")
+	if err != nil {
+		return i18n.Errorf("创建临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	svc := &service{
+		maxSize: s.MaxRequestSize,
+		timeout: s.RequestTimeout,
+		engine:  batch.New(s.MaxConcurrent),
+		tempDir: tempDir,
+		metrics: metrics,
+	}
+	start := func(name, addr string, h http.Handler) error {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return i18n.Errorf("监听 %s 失败: %v", addr, err)
+		}
+		// gRPC 客户端不经过 TLS 协商直接使用 HTTP/2（h2c），HTTP 接口与监控指标同时接受 HTTP/1.1
+		var protocols http.Protocols
+		protocols.SetHTTP1(name != "gRPC")
+		protocols.SetUnencryptedHTTP2(true)
+		// ReadTimeout 限制读取整个请求（包括上传内容）的时间，避免缓慢的上传长期占用连接
+		srv := &http.Server{Handler: accessLog(name, h), Protocols: &protocols, ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout: s.RequestTimeout, IdleTimeout: 2 * time.Minute}
+		srvs = append(srvs, srv)
+		logf(levelNormal, "%s 服务已启动: %s", name, ln.Addr())
+		go func() { errc <- srv.Serve(ln) }()
+		return nil
+	}
+	if s.HTTPAddr != "" {
+		err = start("HTTP", s.HTTPAddr, restHandler{svc})
+	}
+	if err == nil && s.GRPCAddr != "" {
+		err = start("gRPC", s.GRPCAddr, grpcHandler{svc})
+	}
+	if err == nil && s.MetricsAddr != "" {
+		err = start("metrics", s.MetricsAddr, metricsHandler{metrics})
+	}
+	if err == nil && s.MCP {
+		logf(levelVerbose, "MCP 服务已启动")
+		go func() { errc <- serveMCP(ctx, os.Stdin, os.Stdout, metrics) }()
+	}
+	if err == nil {
+		select {
+		case err = <-errc:
+		case <-ctx.Done():
+			logf(levelNormal, "正在停止服务")
+		}
+	}
+
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	for _, srv := range srvs {
+		if serr := srv.Shutdown(sctx); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return err
+}
+
+// accessLog 在 -v 时为每个请求输出一条日志，包括方法、路径、状态码与耗时，gRPC 的状态码取自 grpc-status
+func accessLog(name string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+		attrs := []interface{}{slog.String("server", name), slog.String("method", r.Method), slog.String("path", r.URL.Path),
+			slog.Int("status", sw.status), slog.Int64("bytes", sw.bytes), slog.Duration("elapsed", time.Since(start)), slog.String("remote", r.RemoteAddr)}
+		if code := w.Header().Get(http.TrailerPrefix + "Grpc-Status"); code != "" {
+			attrs = append(attrs, slog.String("grpc_status", code))
+		}
+		logf(levelVerbose, "%s %s %s %d %s", append([]interface{}{name, r.Method, r.URL.Path, sw.status, time.Since(start).Round(time.Microsecond)}, attrs...)...)
+	})
+}
+
+// statusWriter 记录返回的状态码与字节数
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush 使 gRPC 的流式响应可以立即发送
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 使用
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// service 为 HTTP 与 gRPC 接口共用的限制与状态，使单个过大或恶意的请求不会影响其他请求
+type service struct {
+	// maxSize 为每个请求允许上传的最大字节数
+	maxSize int64
+	// timeout 为每个请求的最长处理时间，为 0 时不限制
+	timeout time.Duration
+	// engine 限制同时处理的请求数，并在读取上传内容遇到临时性错误时重试
+	engine *batch.Engine
+	// tempDir 为存放上传内容的临时目录，每个请求在其中使用单独的子目录
+	tempDir string
+	metrics *metricsRegistry
+}
+
+// errBusy 为排队等待处理时超时或请求被取消的错误
+// 包初始化时还没有应用 -lang，写入响应时由 errorMessage 翻译
+var errBusy = errors.New("服务繁忙，请稍后重试")
+
+// errorMessage 返回写入响应的错误信息，errBusy 在这里才按 -lang 翻译
+func errorMessage(err error) string {
+	if errors.Is(err, errBusy) {
+		return i18n.T(errBusy.Error())
+	}
+	return err.Error()
+}
+
+// requestContext 返回受 -request-timeout 限制的 ctx
+func (s *service) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// acquire 等待空闲的处理名额，返回释放名额的函数；ctx 结束前仍没有空闲名额时返回 errBusy
+func (s *service) acquire(ctx context.Context) (func(), error) {
+	release, err := s.engine.Acquire(ctx)
+	if err != nil {
+		return nil, errBusy
+	}
+	return release, nil
+}
+
+// parse 解析请求中的文件内容，读取临时文件遇到临时性错误时重试
+func (s *service) parse(ctx context.Context, req *serveRequest) (sheets []xmind.Sheet, format string, err error) {
+	_, err = s.engine.Retry(ctx, func(ctx context.Context) error {
+		sheets, format, err = req.parse(ctx)
+		return err
+	})
+	return sheets, format, err
+}
+
+// spoolSize 为上传内容保存在内存中的最大字节数，超过时写入临时文件
+const spoolSize = 1 << 20
+
+// spool 读取上传的内容，不超过 spoolSize 时保存在内存中，否则写入请求独占的临时子目录，由 req.close 删除
+// 临时子目录只有当前用户可以访问，文件名固定，不使用上传时的文件名
+func (s *service) spool(src io.Reader, req *serveRequest) error {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, src, spoolSize+1); err != nil && err != io.EOF {
+		return err
+	}
+	if buf.Len() <= spoolSize {
+		req.Input, req.Size = bytes.NewReader(buf.Bytes()), int64(buf.Len())
+		return nil
+	}
+	dir, err := os.MkdirTemp(s.tempDir, "req-")
+	if err != nil {
+		return err
+	}
+	req.cleanup = func() { os.RemoveAll(dir) }
+	f, err := os.OpenFile(filepath.Join(dir, "upload"), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	req.cleanup = func() {
+		f.Close()
+		os.RemoveAll(dir)
+	}
+	n, err := io.Copy(f, io.MultiReader(&buf, src))
+	if err != nil {
+		return err
+	}
+	req.Input, req.Size = f, n
+	return nil
+}
+
+// serveRequest 为一次转换请求的参数，各接口将请求转换为 serveRequest 后统一检查与转换
+type serveRequest struct {
+	// Input 与 Size 为上传的文件内容，位于内存或临时文件中
+	Input  io.ReaderAt
+	Size   int64
+	From   string
+	Format string
+	Write  render.WriteOptions
+	// cleanup 删除上传内容的临时文件，没有临时文件时为 nil
+	cleanup func()
+}
+
+// close 删除请求的临时文件
+func (r *serveRequest) close() {
+	if r.cleanup != nil {
+		r.cleanup()
+		r.cleanup = nil
+	}
+}
+
+// check 填入默认值并检查参数，只允许内置的输出格式，不会按请求执行外部插件
+func (r *serveRequest) check() error {
+	if r.From == "" {
+		r.From = "auto"
+	}
+	if r.Format == "" {
+		r.Format = "md"
+	}
+	if _, err := render.OutputExt(r.Format); err != nil {
+		return err
+	}
+	if r.Format == "template" {
+		return i18n.Errorf("不支持的输出格式: %s", r.Format)
+	}
+	if r.Write.HeadingStart < 0 || r.Write.HeadingStart > 6 {
+		return i18n.Errorf("-heading-start 应为 1 到 6 之间的整数")
+	}
+	if r.Write.MaxDepth < 0 {
+		return i18n.Errorf("-max-depth 不能为负数")
+	}
+	if r.Write.LeafStyle != "" && !oneOf(r.Write.LeafStyle, render.LeafStyles()) {
+		return i18n.Errorf("不支持的叶子节点输出方式: %s，可选: %s", r.Write.LeafStyle, strings.Join(render.LeafStyles(), ", "))
+	}
+	return nil
+}
+
+// parse 解析请求中的文件内容，同时返回识别出的输入格式
+func (r *serveRequest) parse(ctx context.Context) ([]xmind.Sheet, string, error) {
+	ra, size := r.Input, r.Size
+	format := r.From
+	if format == "auto" {
+		f, err := xmind.DetectFormat(ra, size)
+		if err != nil {
+			return nil, "", err
+		}
+		format = f
+	}
+	sheets, err := xmind.ParseAsContext(ctx, format, ra, size)
+	return sheets, format, err
+}
+
+// sheetDepth 返回画布中节点的最大层数，根节点为第 1 层
+func sheetDepth(s *xmind.Sheet) int {
+	depth := 0
+	s.Walk(func(path []*xmind.Topic, _ *xmind.Topic) error {
+		if len(path)+1 > depth {
+			depth = len(path) + 1
+		}
+		return nil
+	})
+	return depth
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var sheetsCommand = &command{
+	Name:  "sheets",
+	Args:  "[参数] <文件>",
+	Short: "列出思维导图中每个画布的序号、标题、根节点与节点数",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var asJSON bool
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("sheets 需要指定一个文件"))
+			}
+			sheets, _, err := readInputSheets(ctx, args[0], opts)
+			if err != nil {
+				return &fileError{path: args[0], err: err}
+			}
+			entries := listSheets(sheets)
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(entries); err != nil {
+					return withCode(exitWrite, err)
+				}
+				return nil
+			}
+			printSheets(os.Stdout, entries)
+			return nil
+		}
+	},
+}
+
+// sheetEntry 为 sheets 输出的一个画布，Index 从 1 开始
+type sheetEntry struct {
+	Index     int    `json:"index"`
+	ID        string `json:"id,omitempty"`
+	Title     string `json:"title"`
+	RootTopic string `json:"rootTopic"`
+	Topics    int    `json:"topics"`
+}
+
+// listSheets 按画布的顺序返回每个画布的概况，多行的标题合并为一行
+func listSheets(sheets []xmind.Sheet) []sheetEntry {
+	entries := []sheetEntry{}
+	for i, s := range sheets {
+		entries = append(entries, sheetEntry{
+			Index:     i + 1,
+			ID:        s.ID,
+			Title:     strings.Join(strings.Fields(s.Title), " "),
+			RootTopic: strings.Join(strings.Fields(s.RootTopic.Title), " "),
+			Topics:    s.TopicCount(),
+		})
+	}
+	return entries
+}
+
+// printSheets 以表格形式输出画布，空标题显示为（无标题）
+func printSheets(w io.Writer, entries []sheetEntry) {
+	var rows [][]string
+	for _, e := range entries {
+		rows = append(rows, []string{strconv.Itoa(e.Index), trailTitle(e.Title), trailTitle(e.RootTopic), strconv.Itoa(e.Topics)})
+	}
+	printTable(w, []string{"序号", "画布", "根节点", "节点"}, rows)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// source 表示一个远程输入来源
+type source interface {
+	// fetch 下载文件内容，同时返回用于命名输出文件的文件名
+	fetch(ctx context.Context, opts fetchOptions) (name string, data []byte, err error)
+}
+
+// parseSource 根据输入识别远程来源，本地路径返回 nil
+func parseSource(in string) (source, error) {
+	lower := strings.ToLower(in)
+	switch {
+	case strings.HasPrefix(lower, "gdrive://"):
+		return driveSource{fileID: in[len("gdrive://"):]}, nil
+	case isS3(in):
+		return parseS3(in)
+	case strings.HasPrefix(lower, "http://"), strings.HasPrefix(lower, "https://"):
+		u, err := url.Parse(in)
+		if err != nil {
+			return nil, i18n.Errorf("无效的地址: %v", err)
+		}
+		if id := driveFileID(u); id != "" {
+			return driveSource{fileID: id}, nil
+		}
+		return urlSource{url: u}, nil
+	}
+	return nil, nil
+}
+
+// isRemote 判断输入是否为远程来源
+func isRemote(in string) bool {
+	lower := strings.ToLower(in)
+	return strings.HasPrefix(lower, "gdrive://") || isS3(in) ||
+		strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// readRemote 下载远程文件并解析，同时返回来源提供的文件名
+// format 为 auto 时根据下载内容识别输入格式
+func readRemote(ctx context.Context, in, format string, opts fetchOptions) ([]xmind.Sheet, string, error) {
+	src, err := parseSource(in)
+	if err != nil {
+		return nil, "", err
+	}
+	if src == nil {
+		return nil, "", i18n.Errorf("不是远程地址: %s", in)
+	}
+	name, data, err := src.fetch(ctx, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	sheets, err := xmind.ParseAsContext(ctx, format, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", withCode(exitParse, err)
+	}
+	return sheets, name, nil
+}
+
+// urlSource 为普通的 http(s) 地址，文件名取自 URL 路径
+type urlSource struct {
+	url *url.URL
+}
+
+func (s urlSource) fetch(ctx context.Context, opts fetchOptions) (string, []byte, error) {
+	name := path.Base(s.url.Path)
+	if name == "/" || name == "." {
+		name = s.url.Hostname()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url.String(), nil)
+	if err != nil {
+		return "", nil, i18n.Errorf("无效的地址: %v", err)
+	}
+	if opts.AuthHeader != "" {
+		key, value, ok := strings.Cut(opts.AuthHeader, ":")
+		if !ok {
+			return "", nil, i18n.Errorf("认证请求头格式应为 \"名称: 值\"")
+		}
+		req.Header.Set(strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	data, err := download(req, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, data, nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// splitIndex 为 split 生成的目录文件名
+const splitIndex = "README.md"
+
+var splitCommand = &command{
+	Name:  "split",
+	Args:  "[参数] <文件>",
+	Short: "将每个第一层分支写入单独的文件，并生成链接到各文件的 README.md",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var filter fileFilter
+		defineOutputFlags(fs, &opts, &filter)
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("split 需要指定一个文件"))
+			}
+			if err := prepareOptions(&opts); err != nil {
+				return err
+			}
+			in := args[0]
+			sheets, name, err := readInput(ctx, in, opts)
+			if err != nil {
+				return &fileError{path: in, err: err}
+			}
+			// 默认输出到输入文件旁边与其同名的目录
+			dir := opts.OutDir
+			if dir == "" {
+				dir = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			ext, err := outputExt(opts)
+			if err != nil {
+				return withCode(exitUsage, err)
+			}
+
+			opts.Cache = loadBuildCache()
+			defer opts.Cache.save()
+			parts := splitBranches(sheets, ext)
+			if opts.To == "md" {
+				opts.Write.Links = splitLinks(sheets, parts, opts.Write)
+			}
+			for _, p := range parts {
+				if opts.StatsFooter {
+					opts.Write.Footer = statsFooter(filepath.Base(name), p.sheets, opts)
+				}
+				out, err := outDirPath(dir, p.file, true)
+				if err != nil {
+					return err
+				}
+				if err := writeOutput(ctx, out, p.sheets, opts); err != nil {
+					return &fileError{path: in, err: err}
+				}
+				opts.Cache.storeOutput(out)
+				recordOutput(out)
+				logf(levelVerbose, "已写入 %s", out, slog.String("output", out))
+			}
+			index, err := outDirPath(dir, splitIndex, true)
+			if err != nil {
+				return err
+			}
+			if err := writeChunk(ctx, index, splitIndexContent(name, sheets, parts), opts); err != nil {
+				return err
+			}
+			opts.Cache.storeOutput(index)
+			recordOutput(index)
+			if verbosity > levelQuiet {
+				fmt.Printf(i18n.T("已将 %d 个分支写入: %s\n"), len(parts), dir)
+			}
+			return nil
+		}
+	},
+}
+
+// splitPart 为 split 输出的一个文件，sheets 只有一个画布，其根节点为拆分出的分支
+type splitPart struct {
+	sheet  int
+	title  string
+	file   string
+	sheets []xmind.Sheet
+}
+
+// splitBranches 将每个画布根节点下的分支（包括分离的节点）拆分为单独的画布，文件名为分支标题的 slug 加上 ext
+// 重复的文件名依次加上 -2、-3 等后缀，没有分支的画布整体成为一个文件
+func splitBranches(sheets []xmind.Sheet, ext string) []splitPart {
+	var parts []splitPart
+	// README 留给目录使用
+	seen := map[string]bool{strings.ToLower(strings.TrimSuffix(splitIndex, filepath.Ext(splitIndex))): true}
+	add := func(i int, s xmind.Sheet, t xmind.Topic) {
+		slug := slugify(t.Title)
+		if slug == "" {
+			slug = "branch-" + strconv.Itoa(len(parts)+1)
+		}
+		for base, n := slug, 2; seen[slug]; n++ {
+			slug = base + "-" + strconv.Itoa(n)
+		}
+		seen[slug] = true
+		parts = append(parts, splitPart{sheet: i, title: t.Title, file: slug + ext,
+			sheets: []xmind.Sheet{{ID: s.ID, Class: s.Class, Title: s.Title, RootTopic: t}}})
+	}
+	for i, s := range sheets {
+		root := s.RootTopic
+		var branches []xmind.Topic
+		if root.Children != nil {
+			branches = append(branches, root.Children.Attached...)
+		}
+		branches = append(branches, root.Detached...)
+		if len(branches) == 0 {
+			add(i, s, root)
+		}
+		for _, b := range branches {
+			add(i, s, b)
+		}
+	}
+	return parts
+}
+
+// splitLinks 返回每个节点拆分后所在的文件与锚点，使 xmind:#ID 形式的内部链接可以指向其他文件；
+// 没有拆分到任何文件中的根节点与画布指向目录
+func splitLinks(sheets []xmind.Sheet, parts []splitPart, write render.WriteOptions) map[string]string {
+	links := map[string]string{}
+	for _, s := range sheets {
+		for _, id := range []string{s.ID, s.RootTopic.ID} {
+			if id != "" {
+				links[id] = chunkLink(splitIndex)
+			}
+		}
+	}
+	for _, p := range parts {
+		headings := render.MarkdownHeadings(p.sheets, write)
+		for i, anchor := range render.HeadingAnchors(headings) {
+			for _, id := range headings[i].IDs {
+				links[id] = chunkLink(p.file) + "#" + anchor
+			}
+		}
+	}
+	return links
+}
+
+// splitIndexContent 生成链接到每个拆分出的文件的目录，有多个画布时按画布分组
+func splitIndexContent(name string, sheets []xmind.Sheet, parts []splitPart) string {
+	var b strings.Builder
+	title := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	if len(sheets) == 1 && sheets[0].RootTopic.Title != "" {
+		title = sheets[0].RootTopic.Title
+	}
+	fmt.Fprintf(&b, "# %s\n", title)
+	last := -1
+	for _, p := range parts {
+		if p.sheet != last {
+			if len(sheets) > 1 {
+				fmt.Fprintf(&b, "\n## %s\n", sheetTitle(sheets[p.sheet]))
+			}
+			b.WriteString("\n")
+			last = p.sheet
+		}
+		text := p.title
+		if strings.TrimSpace(text) == "" {
+			text = p.file
+		}
+		fmt.Fprintf(&b, "- [%s](%s)\n", text, chunkLink(p.file))
+	}
+	return b.String()
+}
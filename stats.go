@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+var statsCommand = &command{
+	Name:  "stats",
+	Args:  "[参数] <文件>",
+	Short: "按第一层分支统计节点数、字数与任务完成情况",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var from string
+		var asJSON bool
+		fs.StringVar(&from, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.BoolVar(&asJSON, "json", false, "以 JSON 格式输出")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("stats 需要指定一个文件"))
+			}
+			wb, err := loadWorkbook(ctx, args[0], from)
+			if err != nil {
+				return err
+			}
+			stats := workbookStats(wb.Sheets)
+			if asJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			}
+			printStats(os.Stdout, stats)
+			return nil
+		}
+	},
+}
+
+// branchStats 为一个分支（或整个画布）的统计
+type branchStats struct {
+	Title  string `json:"title"`
+	Topics int    `json:"topics"`
+	Leaves int    `json:"leaves"`
+	// Words 与 Chars 为标题与备注的字数与字符数，中文等每个字计为一个词，字符数不计空白
+	Words int `json:"words"`
+	Chars int `json:"chars"`
+	// Tasks 为带有任务进度图标的节点数，Done 为其中已完成（task-done）的节点数
+	Tasks int `json:"tasks"`
+	Done  int `json:"done"`
+}
+
+// sheetStats 为一个画布的统计，Branches 为根节点下的每个分支，分离的节点同样作为分支
+// Total 包括根节点本身
+type sheetStats struct {
+	Title    string        `json:"title"`
+	Branches []branchStats `json:"branches"`
+	Total    branchStats   `json:"total"`
+}
+
+// workbookStats 统计每个画布中每个第一层分支的内容
+func workbookStats(sheets []xmind.Sheet) []sheetStats {
+	out := []sheetStats{}
+	for _, s := range sheets {
+		root := s.RootTopic
+		ss := sheetStats{Title: sheetTitle(s), Branches: []branchStats{}}
+		var branches []xmind.Topic
+		if root.Children != nil {
+			branches = append(branches, root.Children.Attached...)
+		}
+		branches = append(branches, root.Detached...)
+		for _, b := range branches {
+			bs := branchStats{Title: b.Title}
+			countTopic(&bs, b)
+			ss.Branches = append(ss.Branches, bs)
+		}
+		ss.Total = branchStats{Title: root.Title}
+		countTopic(&ss.Total, root)
+		out = append(out, ss)
+	}
+	return out
+}
+
+// countTopic 将节点 t 及其所有子节点计入 bs
+func countTopic(bs *branchStats, t xmind.Topic) {
+	(&xmind.Sheet{RootTopic: t}).Walk(func(_ []*xmind.Topic, t *xmind.Topic) error {
+		bs.Topics++
+		if isLeafTopic(t) {
+			bs.Leaves++
+		}
+		text := t.Title
+		if t.Notes != nil && t.Notes.Plain != nil {
+			text += "\n" + t.Notes.Plain.Content
+		}
+		w, c := countWords(text)
+		bs.Words += w
+		bs.Chars += c
+		for _, m := range t.Markers {
+			if strings.HasPrefix(m.MarkerID, "task-") {
+				bs.Tasks++
+				if m.MarkerID == "task-done" {
+					bs.Done++
+				}
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// isLeafTopic 判断节点是否没有子节点，分离的节点同样算作子节点
+func isLeafTopic(t *xmind.Topic) bool {
+	return (t.Children == nil || len(t.Children.Attached) == 0) && len(t.Detached) == 0
+}
+
+// statsFooter 返回 -stats-footer 写在输出末尾的内容：节点数、字数、最大层数以及来源文件与转换时间
+func statsFooter(source string, sheets []xmind.Sheet, opts convertOptions) string {
+	topics, words, depth := 0, 0, 0
+	for _, s := range workbookStats(sheets) {
+		topics += s.Total.Topics
+		words += s.Total.Words
+	}
+	for i := range sheets {
+		if d := sheetDepth(&sheets[i]); d > depth {
+			depth = d
+		}
+	}
+	line := i18n.Sprintf("%d 个节点，%d 字，最深 %d 层；由 %s 转换", topics, words, depth, source)
+	if t, ok := conversionTime(opts); ok {
+		line = i18n.Sprintf("%d 个节点，%d 字，最深 %d 层；由 %s 于 %s 转换", topics, words, depth, source, t.Format("2006-01-02 15:04"))
+	}
+	if opts.To == "md" {
+		return "---\n\n*" + line + "*\n"
+	}
+	return "\n" + line + "\n"
+}
+
+// conversionTime 返回写入 -stats-footer 与 -bundle 的转换时间：设置了 SOURCE_DATE_EPOCH 时为其表示的时间（UTC），
+// 否则为当前时间，-deterministic 时不写入时间
+func conversionTime(opts convertOptions) (time.Time, bool) {
+	if s := os.Getenv("SOURCE_DATE_EPOCH"); s != "" {
+		if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return time.Unix(sec, 0).UTC(), true
+		}
+		warnf(levelNormal, "忽略无效的 SOURCE_DATE_EPOCH: %s", s)
+	}
+	if opts.Deterministic {
+		return time.Time{}, false
+	}
+	return time.Now(), true
+}
+
+// countWords 返回 s 的词数与不含空白的字符数，连续的字母与数字计为一个词，中日韩文字每个字计为一个词
+func countWords(s string) (words, chars int) {
+	inWord := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		chars++
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			words++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+	return words, chars
+}
+
+// sheetTitle 返回画布的标题，没有标题时为根节点的标题
+func sheetTitle(s xmind.Sheet) string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.RootTopic.Title
+}
+
+// printStats 每个画布输出一个表格，最后一行为整个画布的合计
+func printStats(w io.Writer, stats []sheetStats) {
+	for i, ss := range stats {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(stats) > 1 {
+			fmt.Fprintf(w, i18n.T("画布 %d: %s\n"), i+1, ss.Title)
+		}
+		var rows [][]string
+		for _, b := range ss.Branches {
+			rows = append(rows, statsRow(b.Title, b))
+		}
+		rows = append(rows, statsRow(i18n.T("合计"), ss.Total))
+		printTable(w, []string{"分支", "节点", "叶子", "词数", "字符", "任务"}, rows)
+	}
+}
+
+func statsRow(title string, b branchStats) []string {
+	task := "-"
+	if b.Tasks > 0 {
+		task = fmt.Sprintf("%d/%d (%d%%)", b.Done, b.Tasks, b.Done*100/b.Tasks)
+	}
+	return []string{title, strconv.Itoa(b.Topics), strconv.Itoa(b.Leaves), strconv.Itoa(b.Words), strconv.Itoa(b.Chars), task}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+// printTable 以对齐的列输出表格，按显示宽度对齐，中文等宽字符计两列
+// translate 返回 msgs 在当前语言中的译文
+func translate(msgs []string) []string {
+	out := make([]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = i18n.T(m)
+	}
+	return out
+}
+
+func printTable(w io.Writer, header []string, rows [][]string) {
+	header = translate(header)
+	widths := make([]int, len(header))
+	for i, h := range header {
+		widths[i] = displayWidth(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && displayWidth(cell) > widths[i] {
+				widths[i] = displayWidth(cell)
+			}
+		}
+	}
+
+	printRow := func(cells []string) {
+		var b strings.Builder
+		for i, cell := range cells {
+			b.WriteString(cell)
+			// 最后一列不补空格
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)+2))
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+	printRow(header)
+	for _, row := range rows {
+		printRow(row)
+	}
+}
+
+// displayWidth 计算字符串在终端中的显示宽度
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case r < 0x20 || unicode.Is(unicode.Mn, r):
+		case isWide(r):
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// isWide 判断字符是否为东亚宽字符
+func isWide(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f ||
+		r >= 0x2e80 && r <= 0xa4cf && r != 0x303f ||
+		r >= 0xac00 && r <= 0xd7a3 ||
+		r >= 0xf900 && r <= 0xfaff ||
+		r >= 0xfe30 && r <= 0xfe4f ||
+		r >= 0xff00 && r <= 0xff60 ||
+		r >= 0xffe0 && r <= 0xffe6 ||
+		r >= 0x1f300 && r <= 0x1f64f ||
+		r >= 0x20000 && r <= 0x3fffd)
+}
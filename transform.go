@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// scriptInterpreters 为按扩展名选用的脚本解释器，其他文件直接执行（依靠 #! 行或 Windows 的文件关联）
+var scriptInterpreters = map[string]string{
+	".lua": "lua",
+	".py":  "python3",
+	".js":  "node",
+	".rb":  "ruby",
+	".sh":  "sh",
+}
+
+// prepareTransforms 检查 -transform 指定的脚本并读取其内容，脚本内容变化时增量转换会重新生成输出
+func prepareTransforms(opts *convertOptions) error {
+	opts.TransformText = ""
+	for _, script := range opts.Transforms {
+		data, err := os.ReadFile(script)
+		if err != nil {
+			return withCode(exitUsage, i18n.Errorf("读取转换脚本失败: %v", err))
+		}
+		if _, _, err := transformCommand(script); err != nil {
+			return withCode(exitUsage, err)
+		}
+		opts.TransformText += script + "\x00" + string(data) + "\x00"
+	}
+	return nil
+}
+
+// transformCommand 返回执行脚本 script 的程序与参数
+func transformCommand(script string) (string, []string, error) {
+	ext := strings.ToLower(filepath.Ext(script))
+	name, ok := scriptInterpreters[ext]
+	if !ok {
+		abs, err := filepath.Abs(script)
+		if err != nil {
+			return "", nil, err
+		}
+		return abs, nil, nil
+	}
+	if name == "python3" && runtime.GOOS == "windows" {
+		name = "python"
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", nil, i18n.Errorf("执行转换脚本 %s 需要 %s，但没有在 PATH 中找到", script, name)
+	}
+	return path, []string{script}, nil
+}
+
+// transformSheets 依次用 opts.Transforms 中的脚本改写 sheets，在筛选节点与写出之前执行
+// 脚本从标准输入读取 JSON 格式的中间表示（见 xmind.WriteIR），在标准输出写出改写后的中间表示，
+// 因此可以重命名、删除、添加标签或移动任意节点；之后按 -root 或 -root-id 只保留选中的子树
+func transformSheets(ctx context.Context, sheets []xmind.Sheet, opts convertOptions) ([]xmind.Sheet, error) {
+	for _, script := range opts.Transforms {
+		prog, args, err := transformCommand(script)
+		if err != nil {
+			return nil, err
+		}
+		var in, out, stderr bytes.Buffer
+		if err := xmind.WriteIR(&in, sheets); err != nil {
+			return nil, err
+		}
+		cmd := exec.CommandContext(ctx, prog, args...)
+		cmd.Stdin = &in
+		cmd.Stdout = &out
+		cmd.Stderr = &stderr
+		cmd.Env = append(os.Environ(), "XMIND2MD_IR_VERSION="+strconv.Itoa(xmind.IRVersion))
+		if err := cmd.Run(); err != nil {
+			if cerr := ctx.Err(); cerr != nil {
+				return nil, cerr
+			}
+			return nil, i18n.Errorf("转换脚本 %s 执行失败: %v %s", script, err, strings.TrimSpace(stderr.String()))
+		}
+		ir, err := xmind.ReadIR(&out)
+		if err != nil {
+			return nil, i18n.Errorf("转换脚本 %s 的输出无效: %v", script, err)
+		}
+		sheets = ir.ToSheets()
+		if err := xmind.CheckLimits(sheets); err != nil {
+			return nil, err
+		}
+	}
+	return selectRoot(sheets, opts)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// colorModes 为 -color 的可选值
+var colorModes = []string{"auto", "always", "never"}
+
+var treeCommand = &command{
+	Name:  "tree",
+	Args:  "[参数] <文件>",
+	Short: "在终端中以树形显示思维导图的节点层级，不生成任何文件",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var depth int
+		var ascii bool
+		var color string
+		fs.StringVar(&opts.From, "from", "auto", "输入格式: auto, "+strings.Join(xmind.InputFormats(), ", "))
+		fs.IntVar(&depth, "depth", 0, "最多显示的层数，根节点为第 1 层，0 表示不限制")
+		fs.BoolVar(&ascii, "ascii", false, "只使用 ASCII 字符画树形的连线，用于不支持 Unicode 制表符的终端")
+		fs.StringVar(&color, "color", "auto", "是否使用颜色: "+strings.Join(colorModes, ", ")+"（auto 在输出为终端且没有设置 NO_COLOR 时使用）")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return withCode(exitUsage, i18n.Errorf("tree 需要指定一个文件"))
+			}
+			if !oneOf(color, colorModes) {
+				return withCode(exitUsage, i18n.Errorf("不支持的颜色设置: %s，可选: %s", color, strings.Join(colorModes, ", ")))
+			}
+			if depth < 0 {
+				return withCode(exitUsage, i18n.Errorf("-depth 不能小于 0"))
+			}
+			sheets, _, err := readInputSheets(ctx, args[0], opts)
+			if err != nil {
+				return &fileError{path: args[0], err: err}
+			}
+			p := treePrinter{w: os.Stdout, depth: depth, branches: unicodeBranches}
+			if ascii {
+				p.branches = asciiBranches
+			}
+			switch color {
+			case "always":
+				p.style.color = true
+			case "auto":
+				p.style.color = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && enableANSI(os.Stdout)
+			}
+			for i := range sheets {
+				if i > 0 {
+					fmt.Fprintln(p.w)
+				}
+				// 多个画布时在每棵树之前输出画布的标题
+				if len(sheets) > 1 {
+					fmt.Fprintln(p.w, p.style.wrap(ansiDim, i18n.Sprintf("画布 %d: %s", i+1, trailTitle(sheets[i].Title))))
+				}
+				p.printTopic(&sheets[i].RootTopic, "", 1)
+			}
+			return nil
+		}
+	},
+}
+
+// treeBranches 为树形连线使用的字符：中间的子节点、最后一个子节点，以及其下各层的缩进
+type treeBranches struct {
+	middle, last, pipe, space string
+}
+
+var (
+	unicodeBranches = treeBranches{middle: "├── ", last: "└── ", pipe: "│   ", space: "    "}
+	asciiBranches   = treeBranches{middle: "|-- ", last: "`-- ", pipe: "|   ", space: "    "}
+)
+
+// treePrinter 按树形输出节点，depth 大于 0 时只输出前 depth 层，被省略的节点在上级节点后注明个数
+type treePrinter struct {
+	w        io.Writer
+	depth    int
+	branches treeBranches
+	style    previewStyle
+}
+
+// printTopic 输出节点 t 及其子节点，prefix 为子节点连线之前的缩进，分离的节点排在其他子节点之后
+func (p treePrinter) printTopic(t *xmind.Topic, prefix string, layer int) {
+	children := topicChildren(t)
+	title := trailTitle(t.Title)
+	switch layer {
+	case 1:
+		title = p.style.wrap(ansiBold, title)
+	case 2:
+		title = p.style.wrap(ansiH2, title)
+	}
+	if p.depth > 0 && layer >= p.depth && len(children) > 0 {
+		hidden := xmind.Sheet{RootTopic: *t}.TopicCount() - 1
+		title += p.style.wrap(ansiDim, i18n.Sprintf("（还有 %d 个节点）", hidden))
+	}
+	fmt.Fprintln(p.w, title)
+	if p.depth > 0 && layer >= p.depth {
+		return
+	}
+	for i, c := range children {
+		branch, indent := p.branches.middle, p.branches.pipe
+		if i == len(children)-1 {
+			branch, indent = p.branches.last, p.branches.space
+		}
+		fmt.Fprint(p.w, prefix+p.style.wrap(ansiDim, branch))
+		p.printTopic(c, prefix+p.style.wrap(ansiDim, indent), layer+1)
+	}
+}
+
+// topicChildren 返回 t 的子节点，分离的节点排在最后
+func topicChildren(t *xmind.Topic) []*xmind.Topic {
+	var children []*xmind.Topic
+	if t.Children != nil {
+		for i := range t.Children.Attached {
+			children = append(children, &t.Children.Attached[i])
+		}
+	}
+	for i := range t.Detached {
+		children = append(children, &t.Detached[i])
+	}
+	return children
+}
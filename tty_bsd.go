@@ -0,0 +1,26 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal 判断文件是否连接到终端
+func isTerminal(f *os.File) bool {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TIOCGETA, uintptr(unsafe.Pointer(&t)))
+	return errno == 0
+}
+
+// enableANSI 判断是否可以向终端输出 ANSI 转义序列
+func enableANSI(f *os.File) bool {
+	return isTerminal(f)
+}
+
+// ownsConsole 判断终端是否为本程序单独打开，只有 Windows 上能够判断
+func ownsConsole() bool {
+	return false
+}
@@ -0,0 +1,24 @@
+//go:build !linux && !windows && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package main
+
+import "os"
+
+// isTerminal 判断文件是否为字符设备，无法精确判断时的近似做法
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// enableANSI 判断是否可以向终端输出 ANSI 转义序列
+func enableANSI(f *os.File) bool {
+	return isTerminal(f)
+}
+
+// ownsConsole 判断终端是否为本程序单独打开，只有 Windows 上能够判断
+func ownsConsole() bool {
+	return false
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal 判断文件是否连接到控制台
+func isTerminal(f *os.File) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode) == nil
+}
+
+// enableVirtualTerminalProcessing 为 ENABLE_VIRTUAL_TERMINAL_PROCESSING，开启后控制台解释 ANSI 转义序列
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableANSI 为控制台开启 ANSI 转义序列的支持，旧版本的 Windows 不支持时返回 false
+func enableANSI(f *os.File) bool {
+	var mode uint32
+	h := syscall.Handle(f.Fd())
+	if syscall.GetConsoleMode(h, &mode) != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
+
+var procGetConsoleProcessList = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleProcessList")
+
+// ownsConsole 判断控制台是否为本程序单独创建，即在资源管理器中双击或将文件拖放到程序上启动
+// 此时控制台中只有本进程，从 cmd.exe 或 PowerShell 中运行时还有 shell 进程
+func ownsConsole() bool {
+	var pids [2]uint32
+	n, _, _ := procGetConsoleProcessList.Call(uintptr(unsafe.Pointer(&pids[0])), uintptr(len(pids)))
+	return n == 1
+}
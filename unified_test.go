@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// lines 将以换行分隔的 s 拆分为行，s 为空时没有行
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		// edits 为最短编辑序列中新增与删除的行数
+		edits int
+	}{
+		{"两边都为空", "", "", 0},
+		{"相同", "a\nb\nc", "a\nb\nc", 0},
+		{"旧内容为空", "", "a\nb", 2},
+		{"新内容为空", "a\nb", "", 2},
+		{"插入", "a\nc", "a\nb\nc", 1},
+		{"删除", "a\nb\nc", "a\nc", 1},
+		{"修改", "a\nb\nc", "a\nx\nc", 2},
+		{"移动", "a\nb\nc\nd", "b\nc\nd\na", 2},
+		{"交错", "a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc", 5},
+	}
+	for _, tt := range tests {
+		a, b := lines(tt.a), lines(tt.b)
+		ops := diffLines(a, b)
+		// 按编辑序列还原两边的内容
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			if op.kind != '+' {
+				gotA = append(gotA, op.text)
+			}
+			if op.kind != '-' {
+				gotB = append(gotB, op.text)
+			}
+			if op.kind != ' ' {
+				edits++
+			}
+		}
+		if strings.Join(gotA, "\n") != tt.a || strings.Join(gotB, "\n") != tt.b {
+			t.Errorf("%s: 编辑序列 %v 不能由 %q 得到 %q", tt.name, ops, tt.a, tt.b)
+		}
+		if edits != tt.edits {
+			t.Errorf("%s: 编辑序列 %v 有 %d 处修改，最短应为 %d", tt.name, ops, edits, tt.edits)
+		}
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{"相同", "a\nb", "a\nb", 3, ""},
+		{"两边都为空", "", "", 3, ""},
+		{"旧内容为空", "", "a\nb", 3, "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"新内容为空", "a", "", 3, "@@ -1 +0,0 @@\n-a\n"},
+		{"插入", "a\nb\nc\nd\ne\nf", "a\nb\nc\nX\nd\ne\nf", 1,
+			"@@ -3,2 +3,3 @@\n c\n+X\n d\n"},
+		{"删除", "a\nb\nc\nd\ne\nf", "a\nb\nd\ne\nf", 1,
+			"@@ -2,3 +2,2 @@\n b\n-c\n d\n"},
+		{"移动", "a\nb\nc\nd", "b\nc\nd\na", 1,
+			"@@ -1,2 +1 @@\n-a\n b\n@@ -4 +3,2 @@\n d\n+a\n"},
+		{"相距不远的修改合并为一段", "a\nb\nc\nd\ne", "A\nb\nc\nd\nE", 2,
+			"@@ -1,5 +1,5 @@\n-a\n+A\n b\n c\n d\n-e\n+E\n"},
+		// 与 diff -u 相同，两处修改之间相同的行恰好为 2*context 时合并，多一行时分开
+		{"间隔恰好为两倍上下文", "a\nb\nc\nd", "A\nb\nc\nD", 1,
+			"@@ -1,4 +1,4 @@\n-a\n+A\n b\n c\n-d\n+D\n"},
+		{"间隔多一行", "a\nb\nc\nd\ne", "A\nb\nc\nd\nE", 1,
+			"@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -4,2 +4,2 @@\n d\n-e\n+E\n"},
+		{"相距较远的修改分为两段", "a\nb\nc\nd\ne\nf\ng", "A\nb\nc\nd\ne\nf\nG", 1,
+			"@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -6,2 +6,2 @@\n f\n-g\n+G\n"},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		changed, err := writeUnified(&b, "old", "new", lines(tt.a), lines(tt.b), tt.context)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := tt.want
+		if want != "" {
+			want = "--- old\n+++ new\n" + want
+		}
+		if changed != (want != "") || b.String() != want {
+			t.Errorf("%s: writeUnified() = %v，输出\n%s应为\n%s", tt.name, changed, b.String(), want)
+		}
+	}
+}
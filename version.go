@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/render"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// 版本信息在构建时通过 -ldflags 注入，例如:
+//
+//	go build -ldflags "-X main.version=1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+// buildInfo 返回版本、提交与构建时间，未注入时尽量从 Go 记录的构建信息中读取
+func buildInfo() (ver, rev, built string) {
+	ver, rev, built = version, commit, date
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ver, rev, built
+	}
+	if ver == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		ver = strings.TrimPrefix(info.Main.Version, "v")
+	}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision" && rev == "":
+			rev = s.Value
+			if len(rev) > 12 {
+				rev = rev[:12]
+			}
+		case s.Key == "vcs.time" && built == "":
+			built = s.Value
+		}
+	}
+	return ver, rev, built
+}
+
+// printVersion 输出版本信息与支持的输入输出格式
+func printVersion(w io.Writer) {
+	ver, rev, built := buildInfo()
+	if rev == "" {
+		rev = "unknown"
+	}
+	if built == "" {
+		built = "unknown"
+	}
+	fmt.Fprintf(w, "xmindtomarkdown %s\n", ver)
+	fmt.Fprintf(w, i18n.T("提交: %s\n"), rev)
+	fmt.Fprintf(w, i18n.T("构建时间: %s\n"), built)
+	fmt.Fprintf(w, i18n.T("Go 版本: %s %s/%s\n"), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(w, i18n.T("输入格式: %s\n"), strings.Join(xmind.InputFormats(), ", "))
+	fmt.Fprintf(w, i18n.T("输出格式: %s\n"), strings.Join(render.OutputFormats(), ", "))
+}
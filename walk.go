@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+	"github.com/Will-Liang/xmindtomarkdown/pkg/xmind"
+)
+
+// fileFilter 控制遍历目录时包含与排除哪些文件
+// 模式与相对于输入目录的路径匹配，支持 **；不含 / 的模式也会与文件名匹配
+type fileFilter struct {
+	Include stringList
+	Exclude stringList
+}
+
+// excluded 判断相对路径是否被排除，排除的目录不会再进入
+func (f fileFilter) excluded(rel string) bool {
+	return matchAny(f.Exclude, rel)
+}
+
+// included 判断文件是否需要转换，未指定包含模式时转换所有支持格式的文件
+func (f fileFilter) included(rel string) bool {
+	if len(f.Include) == 0 {
+		return xmind.IsInputFile(rel)
+	}
+	return matchAny(f.Include, rel)
+}
+
+// matchAny 判断相对路径是否与任意一个模式匹配
+func matchAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	name := path.Base(rel)
+	for _, p := range patterns {
+		p = filepath.ToSlash(p)
+		if matchGlob(strings.Split(p, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+		if !strings.Contains(p, "/") {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// walkDir 递归遍历目录，返回所有需要转换的文件，相对路径以 dir 为起点
+func walkDir(dir string, filter fileFilter) ([]input, error) {
+	var inputs []input
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		if filter.excluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			// 目录形式的 MindNode bundle 作为单个文件处理，不再进入其中
+			if xmind.FormatOf(p) == "mindnode" {
+				if filter.included(rel) {
+					inputs = append(inputs, input{Path: p, Rel: rel})
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filter.included(rel) {
+			inputs = append(inputs, input{Path: p, Rel: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, i18n.Errorf("读取目录失败: %v", err)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/Will-Liang/xmindtomarkdown/internal/i18n"
+)
+
+var watchCommand = &command{
+	Name:  "watch",
+	Args:  "[参数] <目录...>",
+	Short: "监视目录，自动转换新增或修改的思维导图",
+	Setup: func(fs *flag.FlagSet) func(ctx context.Context, args []string) error {
+		var opts convertOptions
+		var filter fileFilter
+		var w watcher
+		defineOutputFlags(fs, &opts, &filter)
+		fs.DurationVar(&w.Interval, "interval", time.Second, "检查文件变化的间隔")
+		fs.DurationVar(&w.Debounce, "debounce", 500*time.Millisecond, "文件停止变化多久后才开始转换，避免转换保存到一半的文件")
+
+		return func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				return withCode(exitUsage, i18n.Errorf("必须指定要监视的目录"))
+			}
+			for _, dir := range args {
+				info, err := os.Stat(dir)
+				if err != nil {
+					return &fileError{path: dir, err: i18n.Errorf("打开目录失败: %w", err)}
+				}
+				if !info.IsDir() {
+					return withCode(exitUsage, i18n.Errorf("不是目录: %s", dir))
+				}
+			}
+			if err := prepareOptions(&opts); err != nil {
+				return err
+			}
+			w.Dirs, w.Filter, w.Opts = args, filter, opts
+			ctx, stop := notifyInterrupt(ctx)
+			defer stop()
+			return w.run(ctx)
+		}
+	},
+}
+
+// watcher 定期扫描目录，转换新增或修改后停止变化超过 Debounce 的文件
+// 只使用标准库，因此通过比较修改时间与大小而不是文件系统通知发现变化
+type watcher struct {
+	Dirs     []string
+	Filter   fileFilter
+	Opts     convertOptions
+	Interval time.Duration
+	Debounce time.Duration
+
+	// files 记录每个文件上次看到的状态
+	files map[string]*watchedFile
+	// outputs 记录转换生成的文件，如 -to txt 时生成的 .txt 不会再作为输入
+	outputs map[string]bool
+}
+
+// watchedFile 为一个被监视的文件
+type watchedFile struct {
+	in      input
+	modTime time.Time
+	size    int64
+	// changed 为最后一次发现变化的时间，pending 表示变化后还没有转换
+	changed time.Time
+	pending bool
+}
+
+// run 持续监视直到 ctx 取消（如收到中断信号），启动时先转换所有文件，没有变化的文件按缓存跳过
+func (w *watcher) run(ctx context.Context) error {
+	w.files = map[string]*watchedFile{}
+	w.outputs = map[string]bool{}
+	w.Opts.Cache = loadBuildCache()
+	defer w.Opts.Cache.save()
+
+	logf(levelNormal, "正在监视 %d 个目录，按 Ctrl+C 退出", len(w.Dirs))
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		w.scan()
+		w.convertPending(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan 遍历所有目录，记录新增或修改的文件，已删除的文件不再监视
+func (w *watcher) scan() {
+	seen := map[string]bool{}
+	now := time.Now()
+	for _, dir := range w.Dirs {
+		inputs, err := walkDir(dir, w.Filter)
+		if err != nil {
+			warnf(levelNormal, "%v", err)
+			continue
+		}
+		for _, in := range inputs {
+			if w.outputs[cacheKey(in.Path)] {
+				continue
+			}
+			info, err := os.Stat(in.Path)
+			if err != nil {
+				continue
+			}
+			seen[in.Path] = true
+			f := w.files[in.Path]
+			if f == nil {
+				f = &watchedFile{in: in}
+				w.files[in.Path] = f
+			} else if f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+				continue
+			}
+			f.modTime, f.size = info.ModTime(), info.Size()
+			f.changed, f.pending = now, true
+		}
+	}
+	for p := range w.files {
+		if !seen[p] {
+			delete(w.files, p)
+		}
+	}
+}
+
+// convertPending 按 -jobs 并行转换停止变化超过 Debounce 的文件，失败时输出错误后继续监视
+func (w *watcher) convertPending(ctx context.Context) {
+	var ready []string
+	for p, f := range w.files {
+		if f.pending && time.Since(f.changed) >= w.Debounce {
+			ready = append(ready, p)
+		}
+	}
+	if len(ready) == 0 {
+		return
+	}
+	sort.Strings(ready)
+	w.Opts.Cache.newRun()
+	inputs := make([]input, len(ready))
+	for i, p := range ready {
+		f := w.files[p]
+		f.pending = false
+		inputs[i] = f.in
+	}
+	convertBatch(ctx, inputs, w.Opts, nil, func(in input, fr fileReport, err error) {
+		if ctx.Err() != nil {
+			// 中断时没有完成的文件留到下次启动时转换
+			return
+		}
+		if err != nil {
+			printError(os.Stderr, errorFormat, &fileError{path: in.Path, err: err})
+			return
+		}
+		w.outputs[cacheKey(fr.Output)] = true
+		if !fr.Skipped {
+			logf(levelNormal, "已转换 %s -> %s", in.Path, fr.Output, slog.String("input", in.Path), slog.String("output", fr.Output))
+		}
+	})
+	w.Opts.Cache.save()
+}